@@ -1,101 +1,162 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	// Часовые пояса для параметра tz статистики не зависят от zoneinfo в образе
+	_ "time/tzdata"
 
 	"SystemContorlBackend/internal/config"
 	"SystemContorlBackend/internal/database"
 	"SystemContorlBackend/internal/handlers"
-	"SystemContorlBackend/internal/middleware"
-	"SystemContorlBackend/internal/models"
-
-	"github.com/gin-gonic/gin"
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/mailer"
+	"SystemContorlBackend/internal/realtime"
+	"SystemContorlBackend/internal/router"
+	"SystemContorlBackend/internal/services"
 )
 
 func main() {
-	// Загружаем конфигурацию
-	config.LoadConfig()
-
-	// Инициализируем базу данных
-	database.InitDB()
-
-	// Создаем Gin роутер
-	router := gin.Default()
+	// Загружаем конфигурацию: неверные настройки останавливают запуск
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Добавляем CORS middleware для работы с мобильным приложением
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	// Структурированный журнал; уровень меняется на ходу через PUT /api/v1/log-level
+	if err := logging.Setup(os.Stdout, cfg.Log.Level, cfg.Log.Format); err != nil {
+		log.Fatal(err)
+	}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		// Подкоманда управления схемой БД: migrate up|down|status
+		case "migrate":
+			runMigrate(cfg, os.Args[2:])
+			return
+		// Итоговая конфигурация без секретов для диагностики
+		case "config":
+			fmt.Print(cfg.Redacted())
 			return
 		}
+	}
 
-		c.Next()
+	// SIGINT/SIGTERM запускают плавную остановку; повторный сигнал завершает процесс сразу
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Инициализируем базу данных
+	db := database.InitDB(cfg.Database)
+
+	// Собираем сервисы на общем подключении
+	svc := services.New(db, services.Config{
+		JWT:             cfg.JWT,
+		UploadDir:       cfg.UploadDir,
+		AppURL:          cfg.AppURL,
+		CheckMigrations: !cfg.Database.AutoMigrate,
+		IdempotencyTTL:  time.Duration(cfg.Idempotency.TTLHours) * time.Hour,
 	})
 
-	// API группа
-	api := router.Group("/api/v1")
-	{
-		// Публичные эндпоинты (без аутентификации)
-		auth := api.Group("/auth")
-		{
-			auth.POST("/register", handlers.Register)
-			auth.POST("/login", handlers.Login)
+	// Шина событий реального времени: в памяти для одного экземпляра,
+	// PostgreSQL LISTEN/NOTIFY при запуске нескольких экземпляров API
+	var broker realtime.Broker
+	if cfg.Realtime.Broker == "postgres" {
+		broker, err = realtime.NewPostgresBroker(db, cfg.Database.DSN(), 24*time.Hour)
+		if err != nil {
+			logging.Fatal("Ошибка запуска шины событий", "error", err)
 		}
+	} else {
+		broker = realtime.NewMemoryBroker(1000)
+	}
 
-		// Защищенные эндпоинты (требуют аутентификации)
-		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware())
-		{
-			// Профиль пользователя
-			protected.GET("/profile", handlers.GetProfile)
-
-			// Работа с файлами
-			protected.POST("/files/upload", handlers.UploadFiles) // Загрузка файлов
-			protected.GET("/files/:id", handlers.GetFile)               // Скачать файл
-			protected.GET("/files", handlers.GetEntityFiles)    // Список файлов сущности
-			protected.DELETE("/files/:id", handlers.DeleteFile) // Удалить файл
-
-			protected.GET("/projects/:id/files", handlers.GetProjectFiles)
-			protected.GET("/projects/:id/image", handlers.GetProjectMainImage)
-
-			// Проекты (доступ для всех авторизованных пользователей)
-			protected.GET("/projects", handlers.GetProjects)    // Список проектов
-			protected.GET("/projects/:id", handlers.GetProject) // Один проект
-
-			// Эндпоинты только для менеджеров
-			manager := protected.Group("/")
-			manager.Use(middleware.RoleMiddleware(models.RoleManager))
-			{
-				// Управление проектами
-				manager.POST("/projects", handlers.CreateProject)       // Создание проекта
-				manager.PUT("/projects/:id", handlers.UpdateProject)    // Обновление проекта
-				manager.DELETE("/projects/:id", handlers.DeleteProject) // Удаление проекта
-			}
-
-			// Эндпоинты для менеджеров и инженеров
-			staff := protected.Group("/")
-			staff.Use(middleware.RoleMiddleware(models.RoleManager, models.RoleEngineer))
-			{
-				// Здесь будут эндпоинты для создания и редактирования дефектов
-				// staff.POST("/defects", handlers.CreateDefect)
-				// staff.PUT("/defects/:id", handlers.UpdateDefect)
-			}
-		}
+	// Подписчики доменных событий: уведомления в приложении, очередь webhook,
+	// письма о созданных уведомлениях и поток реального времени
+	svc.Events.Subscribe(svc.Notifications.HandleEvent)
+	svc.Events.Subscribe(svc.Webhooks.HandleEvent)
+	svc.Events.Subscribe(svc.Emails.SendNotification)
+	svc.Events.Subscribe(services.RealtimePublisher(broker))
+
+	jobs := newBackgroundJobs()
+
+	// Запускаем фоновую проверку сроков устранения дефектов
+	jobs.Go(func(ctx context.Context) {
+		svc.SLA.StartScheduler(ctx, time.Duration(cfg.Jobs.SLACheckIntervalMinutes)*time.Minute)
+	})
+
+	// Запускаем отправку исходящих webhook из очереди доставок
+	jobs.Go(func(ctx context.Context) {
+		svc.Webhooks.StartWorker(ctx, time.Duration(cfg.Jobs.WebhookWorkerIntervalSeconds)*time.Second)
+	})
+
+	// Email-уведомления: SMTP, если задан SMTP_HOST, иначе письма только пишутся в журнал
+	var sender mailer.Sender = mailer.LogSender{}
+	if cfg.SMTP.Host != "" {
+		sender = mailer.NewSMTPSender(
+			cfg.SMTP.Host,
+			strconv.Itoa(cfg.SMTP.Port),
+			cfg.SMTP.Username,
+			cfg.SMTP.Password,
+			cfg.SMTP.From,
+		)
 	}
+	jobs.Go(func(ctx context.Context) {
+		svc.Emails.StartWorker(ctx, sender)
+	})
+
+	// Ежедневная сводка руководителям проектов
+	jobs.Go(func(ctx context.Context) {
+		svc.Emails.StartDigestScheduler(ctx, cfg.Jobs.DigestHour)
+	})
+
+	// Ответы на запросы с Idempotency-Key хранятся для повторов клиентов
+	jobs.Go(func(ctx context.Context) {
+		svc.Idempotency.StartCleanup(ctx, time.Hour)
+	})
+
+	h := handlers.New(svc, broker)
 
 	// Запускаем сервер
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
+	server := newHTTPServer(cfg.Server, router.New(h, svc.Tokens, svc.Idempotency))
+	server.RegisterOnShutdown(h.CloseStreams)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Сервер запущен", "port", cfg.Server.Port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		logging.Fatal("Ошибка запуска сервера", "error", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Плавная остановка: новые запросы не принимаются, начатые (в том числе загрузки файлов)
+	// завершаются, затем фоновые задачи заканчивают текущую работу
+	slog.Info("Получен сигнал остановки, завершаем запросы и фоновые задачи")
+	svc.Health.StartDraining()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Duration(cfg.Server.ShutdownTimeoutSeconds))
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Не все запросы завершились до таймаута", "error", err)
+	}
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		slog.Warn("Не все фоновые задачи завершились до таймаута", "error", err)
 	}
 
-	log.Printf("Сервер запущен на порту %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Ошибка запуска сервера: ", err)
+	broker.Close()
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
 	}
+	slog.Info("Сервер остановлен")
 }
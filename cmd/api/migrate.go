@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"SystemContorlBackend/internal/config"
+	"SystemContorlBackend/internal/database"
+	"SystemContorlBackend/internal/logging"
+)
+
+// runMigrate выполняет подкоманду управления схемой БД:
+//
+//	api migrate up          применить все новые миграции
+//	api migrate down [N]    откатить последние N миграций (по умолчанию 1)
+//	api migrate status      показать примененные и ожидающие миграции
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		logging.Fatal("Использование: migrate up|down [N]|status")
+	}
+
+	db := database.Connect(cfg.Database)
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			logging.Fatal("Ошибка применения миграций", "error", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Схема БД актуальна, новых миграций нет")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				logging.Fatal("Количество откатываемых миграций должно быть положительным числом")
+			}
+			steps = n
+		}
+		reverted, err := database.MigrateDown(db, steps)
+		if err != nil {
+			logging.Fatal("Ошибка отката миграций", "error", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Нет примененных миграций")
+		}
+
+	case "status":
+		statuses, err := database.MigrationStatuses(db)
+		if err != nil {
+			logging.Fatal("Ошибка чтения состояния миграций", "error", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		logging.Fatal("Неизвестная команда migrate, ожидается up, down или status", "command", args[0])
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"SystemContorlBackend/internal/config"
+)
+
+// newHTTPServer создает HTTP-сервер с таймаутами из конфигурации
+func newHTTPServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: config.Duration(cfg.ReadHeaderTimeoutSeconds),
+		ReadTimeout:       config.Duration(cfg.ReadTimeoutSeconds),
+		WriteTimeout:      config.Duration(cfg.WriteTimeoutSeconds),
+		IdleTimeout:       config.Duration(cfg.IdleTimeoutSeconds),
+	}
+}
+
+// backgroundJobs - фоновые задачи процесса, которые при остановке
+// завершают текущую итерацию и дожидаются друг друга
+type backgroundJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackgroundJobs() *backgroundJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backgroundJobs{ctx: ctx, cancel: cancel}
+}
+
+// Go запускает задачу; задача должна вернуться после отмены переданного контекста
+func (j *backgroundJobs) Go(job func(ctx context.Context)) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		job(j.ctx)
+	}()
+}
+
+// Stop отменяет задачи и ждет их завершения, но не дольше, чем живет ctx
+func (j *backgroundJobs) Stop(ctx context.Context) error {
+	j.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
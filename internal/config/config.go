@@ -1,24 +1,371 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
-// LoadConfig загружает переменные окружения из .env файла
-func LoadConfig() {
-	err := godotenv.Load()
+// MinJWTSecretLength - минимальная длина секрета подписи JWT
+const MinJWTSecretLength = 32
+
+// redacted заменяет секреты при выводе конфигурации
+const redacted = "***"
+
+// Config - настройки приложения
+type Config struct {
+	Env         string            `yaml:"env"`
+	AppURL      string            `yaml:"app_url"`
+	UploadDir   string            `yaml:"upload_dir"`
+	Server      ServerConfig      `yaml:"server"`
+	Log         LogConfig         `yaml:"log"`
+	Database    DatabaseConfig    `yaml:"database"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	SMTP        SMTPConfig        `yaml:"smtp"`
+	Jobs        JobsConfig        `yaml:"jobs"`
+	Realtime    RealtimeConfig    `yaml:"realtime"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+// ServerConfig - HTTP-сервер. Таймауты в секундах, 0 - без ограничения
+type ServerConfig struct {
+	Port                     int `yaml:"port"`
+	ReadHeaderTimeoutSeconds int `yaml:"read_header_timeout_seconds"`
+	// ReadTimeoutSeconds ограничивает чтение всего запроса, включая загружаемые файлы
+	ReadTimeoutSeconds  int `yaml:"read_timeout_seconds"`
+	WriteTimeoutSeconds int `yaml:"write_timeout_seconds"`
+	IdleTimeoutSeconds  int `yaml:"idle_timeout_seconds"`
+	// ShutdownTimeoutSeconds - сколько ждать завершения запросов и фоновых задач при остановке
+	ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds"`
+}
+
+// LogConfig - журнал приложения. Уровень можно менять на работающем сервере
+// через PUT /api/v1/log-level
+type LogConfig struct {
+	// Level - debug, info, warn или error
+	Level string `yaml:"level"`
+	// Format - json для сборщиков логов или text для локальной разработки
+	Format string `yaml:"format"`
+}
+
+// DatabaseConfig - подключение к PostgreSQL и управление схемой
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+	// MigrateOnStart применяет SQL-миграции при запуске; false - только проверка, что они применены
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+	// AutoMigrate управляет схемой через GORM AutoMigrate вместо миграций (только разработка)
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+// JWTConfig - подпись токенов доступа
+type JWTConfig struct {
+	Secret      string `yaml:"secret"`
+	ExpireHours int    `yaml:"expire_hours"`
+}
+
+// SMTPConfig - отправка писем; пустой Host - письма только пишутся в журнал
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// JobsConfig - расписание фоновых задач
+type JobsConfig struct {
+	SLACheckIntervalMinutes      int `yaml:"sla_check_interval_minutes"`
+	WebhookWorkerIntervalSeconds int `yaml:"webhook_worker_interval_seconds"`
+	DigestHour                   int `yaml:"digest_hour"`
+}
+
+// RealtimeConfig - шина событий: memory (один экземпляр) или postgres (LISTEN/NOTIFY)
+type RealtimeConfig struct {
+	Broker string `yaml:"broker"`
+}
+
+// IdempotencyConfig - хранение ответов на запросы с Idempotency-Key
+type IdempotencyConfig struct {
+	TTLHours int `yaml:"ttl_hours"`
+}
+
+// Default возвращает конфигурацию со значениями по умолчанию
+func Default() *Config {
+	return &Config{
+		Env:       "development",
+		UploadDir: "uploads",
+		Server: ServerConfig{
+			Port:                     8080,
+			ReadHeaderTimeoutSeconds: 10,
+			ReadTimeoutSeconds:       120,
+			WriteTimeoutSeconds:      120,
+			IdleTimeoutSeconds:       120,
+			ShutdownTimeoutSeconds:   30,
+		},
+		Log: LogConfig{Level: "info", Format: "json"},
+		Database: DatabaseConfig{
+			Host:           "localhost",
+			Port:           5432,
+			SSLMode:        "disable",
+			MigrateOnStart: true,
+		},
+		JWT:         JWTConfig{ExpireHours: 24},
+		SMTP:        SMTPConfig{Port: 1025, From: "noreply@systemcontrol.local"},
+		Jobs:        JobsConfig{SLACheckIntervalMinutes: 15, WebhookWorkerIntervalSeconds: 10, DigestHour: 8},
+		Realtime:    RealtimeConfig{Broker: "memory"},
+		Idempotency: IdempotencyConfig{TTLHours: 24},
+	}
+}
+
+// Load собирает конфигурацию: значения по умолчанию, затем YAML-файл из CONFIG_FILE (если задан),
+// затем переменные окружения. Сначала читается .env, поэтому CONFIG_FILE можно задать и в нем.
+// Переменные окружения важнее файла. Возвращает ошибку, если значения не разбираются
+// или не проходят проверку.
+func Load() (*Config, error) {
+	// .env не перезаписывает уже заданные переменные окружения
+	if err := godotenv.Load(); err != nil {
+		slog.Info("No .env file found")
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать файл конфигурации: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("неверный файл конфигурации %s: %w", path, err)
+		}
+	}
+
+	// Ошибки разбора и проверки возвращаются вместе, чтобы исправить их за один раз
+	if err := joinErrors(append(cfg.applyEnv(), cfg.problems()...)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv переносит в конфигурацию заданные переменные окружения и возвращает ошибки разбора
+func (c *Config) applyEnv() []string {
+	texts := map[string]*string{
+		"ENV":             &c.Env,
+		"APP_URL":         &c.AppURL,
+		"UPLOAD_DIR":      &c.UploadDir,
+		"DB_HOST":         &c.Database.Host,
+		"DB_USER":         &c.Database.User,
+		"DB_PASSWORD":     &c.Database.Password,
+		"DB_NAME":         &c.Database.Name,
+		"DB_SSLMODE":      &c.Database.SSLMode,
+		"JWT_SECRET":      &c.JWT.Secret,
+		"SMTP_HOST":       &c.SMTP.Host,
+		"SMTP_USERNAME":   &c.SMTP.Username,
+		"SMTP_PASSWORD":   &c.SMTP.Password,
+		"SMTP_FROM":       &c.SMTP.From,
+		"REALTIME_BROKER": &c.Realtime.Broker,
+		"LOG_LEVEL":       &c.Log.Level,
+		"LOG_FORMAT":      &c.Log.Format,
+	}
+	ints := map[string]*int{
+		"SERVER_PORT":                        &c.Server.Port,
+		"SERVER_READ_HEADER_TIMEOUT_SECONDS": &c.Server.ReadHeaderTimeoutSeconds,
+		"SERVER_READ_TIMEOUT_SECONDS":        &c.Server.ReadTimeoutSeconds,
+		"SERVER_WRITE_TIMEOUT_SECONDS":       &c.Server.WriteTimeoutSeconds,
+		"SERVER_IDLE_TIMEOUT_SECONDS":        &c.Server.IdleTimeoutSeconds,
+		"SERVER_SHUTDOWN_TIMEOUT_SECONDS":    &c.Server.ShutdownTimeoutSeconds,
+		"DB_PORT":                            &c.Database.Port,
+		"JWT_EXPIRE_HOURS":                   &c.JWT.ExpireHours,
+		"SMTP_PORT":                          &c.SMTP.Port,
+		"SLA_CHECK_INTERVAL_MINUTES":         &c.Jobs.SLACheckIntervalMinutes,
+		"WEBHOOK_WORKER_INTERVAL_SECONDS":    &c.Jobs.WebhookWorkerIntervalSeconds,
+		"DIGEST_HOUR":                        &c.Jobs.DigestHour,
+		"IDEMPOTENCY_TTL_HOURS":              &c.Idempotency.TTLHours,
+	}
+	bools := map[string]*bool{
+		"DB_MIGRATE_ON_START": &c.Database.MigrateOnStart,
+		"DB_AUTO_MIGRATE":     &c.Database.AutoMigrate,
+	}
+
+	var errs []string
+	for key, field := range texts {
+		if value, ok := lookupNonEmpty(key); ok {
+			*field = value
+		}
+	}
+	for key, field := range ints {
+		if value, ok := lookupNonEmpty(key); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, key+": ожидается целое число")
+				continue
+			}
+			*field = n
+		}
+	}
+	for key, field := range bools {
+		if value, ok := lookupNonEmpty(key); ok {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, key+": ожидается true или false")
+				continue
+			}
+			*field = b
+		}
+	}
+
+	sort.Strings(errs)
+	return errs
+}
+
+// lookupNonEmpty возвращает значение переменной окружения, если она задана и не пуста.
+// Пустая переменная (например, "SMTP_HOST=" в .env) не перекрывает значение из файла.
+func lookupNonEmpty(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	return value, ok && value != ""
+}
+
+// Validate проверяет конфигурацию и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	return joinErrors(c.problems())
+}
+
+// problems возвращает список нарушений в конфигурации
+func (c *Config) problems() []string {
+	var errs []string
+	checkPort := func(name string, port int) {
+		if port < 1 || port > 65535 {
+			errs = append(errs, fmt.Sprintf("%s: порт должен быть от 1 до 65535", name))
+		}
+	}
+
+	checkPort("SERVER_PORT", c.Server.Port)
+	checkTimeout := func(name string, seconds int) {
+		if seconds < 0 {
+			errs = append(errs, name+": не может быть отрицательным")
+		}
+	}
+	checkTimeout("SERVER_READ_HEADER_TIMEOUT_SECONDS", c.Server.ReadHeaderTimeoutSeconds)
+	checkTimeout("SERVER_READ_TIMEOUT_SECONDS", c.Server.ReadTimeoutSeconds)
+	checkTimeout("SERVER_WRITE_TIMEOUT_SECONDS", c.Server.WriteTimeoutSeconds)
+	checkTimeout("SERVER_IDLE_TIMEOUT_SECONDS", c.Server.IdleTimeoutSeconds)
+	if c.Server.ShutdownTimeoutSeconds < 1 {
+		errs = append(errs, "SERVER_SHUTDOWN_TIMEOUT_SECONDS: должно быть положительным числом")
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, "LOG_LEVEL: допустимы debug, info, warn или error")
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		errs = append(errs, "LOG_FORMAT: допустимы json или text")
+	}
+
+	if c.Database.Host == "" {
+		errs = append(errs, "DB_HOST: обязательный параметр")
+	}
+	if c.Database.User == "" {
+		errs = append(errs, "DB_USER: обязательный параметр")
+	}
+	if c.Database.Name == "" {
+		errs = append(errs, "DB_NAME: обязательный параметр")
+	}
+	checkPort("DB_PORT", c.Database.Port)
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, "DB_SSLMODE: неизвестный режим "+c.Database.SSLMode)
+	}
+
+	if len(c.JWT.Secret) < MinJWTSecretLength {
+		errs = append(errs, fmt.Sprintf("JWT_SECRET: секрет должен быть не короче %d символов", MinJWTSecretLength))
+	}
+	if c.JWT.ExpireHours < 1 {
+		errs = append(errs, "JWT_EXPIRE_HOURS: должно быть положительным числом")
+	}
+
+	if c.SMTP.Host != "" {
+		checkPort("SMTP_PORT", c.SMTP.Port)
+		if c.SMTP.From == "" {
+			errs = append(errs, "SMTP_FROM: обязательный параметр при заданном SMTP_HOST")
+		}
+	}
+
+	if c.UploadDir == "" {
+		errs = append(errs, "UPLOAD_DIR: обязательный параметр")
+	}
+	if c.Jobs.SLACheckIntervalMinutes < 1 {
+		errs = append(errs, "SLA_CHECK_INTERVAL_MINUTES: должно быть положительным числом")
+	}
+	if c.Jobs.WebhookWorkerIntervalSeconds < 1 {
+		errs = append(errs, "WEBHOOK_WORKER_INTERVAL_SECONDS: должно быть положительным числом")
+	}
+	if c.Jobs.DigestHour < 0 || c.Jobs.DigestHour > 23 {
+		errs = append(errs, "DIGEST_HOUR: час должен быть от 0 до 23")
+	}
+	if c.Idempotency.TTLHours < 1 {
+		errs = append(errs, "IDEMPOTENCY_TTL_HOURS: должно быть положительным числом")
+	}
+	if c.Realtime.Broker != "memory" && c.Realtime.Broker != "postgres" {
+		errs = append(errs, "REALTIME_BROKER: допустимы memory или postgres")
+	}
+
+	return errs
+}
+
+// joinErrors объединяет ошибки конфигурации в одну
+func joinErrors(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New("неверная конфигурация: " + strings.Join(errs, "; "))
+}
+
+// Duration переводит таймаут в секундах в time.Duration
+func Duration(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// Redacted возвращает конфигурацию в YAML со скрытыми паролями и секретами для диагностики
+func (c *Config) Redacted() string {
+	safe := *c
+	safe.Database.Password = redact(safe.Database.Password)
+	safe.JWT.Secret = redact(safe.JWT.Secret)
+	safe.SMTP.Password = redact(safe.SMTP.Password)
+
+	data, err := yaml.Marshal(&safe)
 	if err != nil {
-		log.Println("No .env file found")
+		return err.Error()
+	}
+	return string(data)
+}
+
+// redact скрывает непустое значение
+func redact(value string) string {
+	if value == "" {
+		return ""
 	}
+	return redacted
 }
 
-// GetEnv получает переменную окружения или возвращает значение по умолчанию
-func GetEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// DSN собирает строку подключения к PostgreSQL
+func (c DatabaseConfig) DSN() string {
+	if c.Password == "" {
+		return fmt.Sprintf("host=%s user=%s dbname=%s port=%d sslmode=%s",
+			c.Host, c.User, c.Name, c.Port, c.SSLMode)
 	}
-	return defaultValue
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		c.Host, c.User, c.Password, c.Name, c.Port, c.SSLMode)
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// setRequiredEnv задает минимально необходимые переменные окружения
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DB_USER", "postgres")
+	t.Setenv("DB_NAME", "defects_test")
+	t.Setenv("JWT_SECRET", testSecret)
+}
+
+func TestLoadDefaultsAndEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("SERVER_PORT", "9090")
+	t.Setenv("DB_MIGRATE_ON_START", "false")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9090 || cfg.Database.MigrateOnStart {
+		t.Fatalf("переменные окружения не применены: %+v", cfg)
+	}
+	if cfg.Database.Host != "localhost" || cfg.JWT.ExpireHours != 24 || cfg.Realtime.Broker != "memory" {
+		t.Fatalf("не подставлены значения по умолчанию: %+v", cfg)
+	}
+}
+
+func TestLoadYAMLWithEnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "server:\n  port: 7070\ndatabase:\n  user: app\n  name: defects\n  port: 6432\njwt:\n  secret: " + testSecret + "\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SERVER_PORT", "8081")
+	// Пустая переменная не стирает значение из файла
+	t.Setenv("DB_USER", "")
+
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8081 {
+		t.Fatalf("переменная окружения должна перекрывать файл, порт %d", cfg.Server.Port)
+	}
+	if cfg.Database.User != "app" || cfg.Database.Port != 6432 {
+		t.Fatalf("значения из файла не применены: %+v", cfg.Database)
+	}
+}
+
+func TestLoadConfigFileFromDotEnv(t *testing.T) {
+	setRequiredEnv(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 7171\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("CONFIG_FILE="+path+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	// .env не перекрывает заданные переменные: CONFIG_FILE должен отсутствовать в окружении;
+	// t.Setenv восстановит исходное значение после теста
+	os.Unsetenv("CONFIG_FILE")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 7171 {
+		t.Fatalf("файл из CONFIG_FILE в .env не прочитан, порт %d", cfg.Server.Port)
+	}
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DB_USER", "postgres")
+	t.Setenv("DB_NAME", "")
+	t.Setenv("JWT_SECRET", "short")
+	t.Setenv("SERVER_PORT", "70000")
+	t.Setenv("DIGEST_HOUR", "eight")
+	t.Setenv("LOG_LEVEL", "verbose")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("ожидалась ошибка конфигурации")
+	}
+	for _, key := range []string{"JWT_SECRET", "SERVER_PORT", "DIGEST_HOUR", "DB_NAME", "LOG_LEVEL"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("в ошибке нет %s: %v", key, err)
+		}
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := Load(); err == nil {
+		t.Fatal("ожидалась ошибка для отсутствующего файла")
+	}
+}
+
+func TestRedactedHidesSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "db-password"
+	cfg.JWT.Secret = testSecret
+	cfg.SMTP.Password = "smtp-password"
+
+	dump := cfg.Redacted()
+	for _, secret := range []string{"db-password", testSecret, "smtp-password"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("секрет %q попал в вывод:\n%s", secret, dump)
+		}
+	}
+	if !strings.Contains(dump, "port: 8080") {
+		t.Errorf("в выводе нет обычных настроек:\n%s", dump)
+	}
+	if cfg.JWT.Secret != testSecret {
+		t.Error("Redacted изменил исходную конфигурацию")
+	}
+}
@@ -2,62 +2,116 @@ package database
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 
+	"SystemContorlBackend/internal/config"
+	"SystemContorlBackend/internal/logging"
 	"SystemContorlBackend/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-// InitDB инициализирует подключение к базе данных
-func InitDB() {
-	var dsn string
-	password := os.Getenv("DB_PASSWORD")
-	if password == "" {
-		dsn = fmt.Sprintf("host=%s user=%s dbname=%s port=%s sslmode=%s",
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_USER"),
-			os.Getenv("DB_NAME"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_SSLMODE"),
-		)
-	} else {
-		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_USER"),
-			password,
-			os.Getenv("DB_NAME"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_SSLMODE"),
-		)
-	}
-
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Connect открывает подключение к базе данных без изменения схемы
+func Connect(cfg config.DatabaseConfig) *gorm.DB {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
 	if err != nil {
-		log.Fatal("Failed to connect to database: ", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
+	return db
+}
+
+// InitDB подключается к базе данных, приводит схему к актуальной версии и заполняет справочники.
+// По умолчанию применяются встроенные SQL-миграции (MigrateOnStart=false - только проверка,
+// что миграции применены командой migrate up). AutoMigrate=true включает AutoMigrate
+// моделей вместо миграций - только для локальной разработки.
+func InitDB(cfg config.DatabaseConfig) *gorm.DB {
+	db := Connect(cfg)
+
+	if cfg.AutoMigrate {
+		slog.Warn("DB_AUTO_MIGRATE is enabled: schema is managed by AutoMigrate (development only)")
+		autoMigrate(db)
+	} else if !cfg.MigrateOnStart {
+		pending, err := PendingMigrations(db)
+		if err != nil {
+			logging.Fatal("Failed to check migrations", "error", err)
+		}
+		if len(pending) > 0 {
+			logging.Fatal("Database schema is outdated, run \"migrate up\"", "pending", len(pending))
+		}
+	} else if _, err := MigrateUp(db); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+
+	if err := Seed(db); err != nil {
+		logging.Fatal("Failed to seed database", "error", err)
+	}
+	return db
+}
+
+// Seed создает в db роли по умолчанию и заполняет справочники дефектов
+func Seed(db *gorm.DB) error {
+	if err := seedRoles(db); err != nil {
+		return err
+	}
+	return seedCatalogs(db)
+}
 
-	// Автомиграция моделей
-	err = DB.AutoMigrate(
+// Models возвращает модели всех таблиц приложения в порядке создания
+func Models() []interface{} {
+	return []interface{}{
 		&models.Role{},
+		&models.Organization{},
 		&models.User{},
 		&models.Project{},
 		&models.Attachment{},
-	)
-	if err != nil {
-		log.Fatal("Failed to migrate database: ", err)
+		&models.DefectCategory{},
+		&models.SeverityLevel{},
+		&models.NormReference{},
+		&models.Defect{},
+		&models.Holiday{},
+		&models.DefectAssignment{},
+		&models.Comment{},
+		&models.Notification{},
+		&models.ProjectMember{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.NotificationPreference{},
+		&models.EmailDigest{},
+		&models.SavedView{},
+		&models.SavedViewPin{},
+		&models.IdempotencyKey{},
+	}
+}
+
+// autoMigrate создает и дополняет таблицы по моделям (режим разработки)
+func autoMigrate(db *gorm.DB) {
+	if err := AutoMigrate(db); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
 	}
 
-	// Создаем роли по умолчанию
-	seedRoles()
+	// Колонки и индексы полнотекстового поиска
+	ensureSearchIndexes(db)
+}
+
+// AutoMigrate создает и дополняет таблицы всех моделей в db без SQL-миграций.
+// Не зависит от диалекта PostgreSQL, поэтому подходит и для тестовой SQLite.
+func AutoMigrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(Models()...); err != nil {
+		return err
+	}
+
+	// Файлы полиморфно ссылаются на проекты и дефекты, поэтому внешний ключ
+	// attachments.entity_id -> projects.id, созданный ранними версиями, мешает загрузке файлов дефектов
+	if db.Migrator().HasConstraint(&models.Attachment{}, "fk_projects_attachments") {
+		if err := db.Migrator().DropConstraint(&models.Attachment{}, "fk_projects_attachments"); err != nil {
+			slog.Warn("Failed to drop constraint fk_projects_attachments", "error", err)
+		}
+	}
+	return nil
 }
 
 // seedRoles создает роли согласно ТЗ
-func seedRoles() {
+func seedRoles(db *gorm.DB) error {
 	roles := []models.Role{
 		{Name: "Менеджер", Code: models.RoleManager},
 		{Name: "Инженер", Code: models.RoleEngineer},
@@ -65,12 +119,83 @@ func seedRoles() {
 	}
 
 	for _, role := range roles {
-		var existingRole models.Role
-		if err := DB.Where("code = ?", role.Code).First(&existingRole).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				DB.Create(&role)
-				log.Printf("Role '%s' created", role.Name)
-			}
+		created, err := seedRecord(db, &role, "code = ? OR name = ?", role.Code, role.Name)
+		if err != nil {
+			return fmt.Errorf("seed role %s: %w", role.Code, err)
+		}
+		if created {
+			slog.Info("Role created", "name", role.Name)
 		}
 	}
-}
\ No newline at end of file
+	return nil
+}
+
+// seedCatalogs заполняет справочники категорий, уровней критичности и нормативов
+func seedCatalogs(db *gorm.DB) error {
+	categories := []models.DefectCategory{
+		{Name: "Бетонные работы", Code: "concrete"},
+		{Name: "Электромонтажные работы", Code: "electrical"},
+		{Name: "Отделочные работы", Code: "finishing"},
+		{Name: "Кровельные работы", Code: "roofing"},
+		{Name: "Инженерные системы", Code: "utilities"},
+	}
+	for _, category := range categories {
+		created, err := seedRecord(db, &category, "code = ? OR name = ?", category.Code, category.Name)
+		if err != nil {
+			return fmt.Errorf("seed defect category %s: %w", category.Code, err)
+		}
+		if created {
+			slog.Info("Defect category created", "name", category.Name)
+		}
+	}
+
+	severities := []models.SeverityLevel{
+		{Name: "Незначительный", Code: models.SeverityLow, Rank: 1, FixDays: 30},
+		{Name: "Значительный", Code: models.SeverityMedium, Rank: 2, FixDays: 14},
+		{Name: "Существенный", Code: models.SeverityHigh, Rank: 3, FixDays: 5},
+		{Name: "Критический", Code: models.SeverityCritical, Rank: 4, FixDays: 1},
+	}
+	for _, severity := range severities {
+		created, err := seedRecord(db, &severity, "code = ? OR name = ?", severity.Code, severity.Name)
+		if err != nil {
+			return fmt.Errorf("seed severity level %s: %w", severity.Code, err)
+		}
+		if created {
+			slog.Info("Severity level created", "name", severity.Name)
+		}
+	}
+
+	norms := []models.NormReference{
+		{DocumentType: models.NormDocumentSP, Document: "СП 70.13330.2012", Clause: "5.18", Title: "Несущие и ограждающие конструкции. Монолитные конструкции"},
+		{DocumentType: models.NormDocumentSP, Document: "СП 71.13330.2017", Clause: "7.2", Title: "Изоляционные и отделочные покрытия. Штукатурные работы"},
+		{DocumentType: models.NormDocumentSP, Document: "СП 76.13330.2016", Clause: "3.1", Title: "Электротехнические устройства. Общие требования"},
+		{DocumentType: models.NormDocumentGOST, Document: "ГОСТ 30971-2012", Clause: "5.1", Title: "Швы монтажные узлов примыкания оконных блоков"},
+	}
+	for _, norm := range norms {
+		created, err := seedRecord(db, &norm, "document = ? AND clause = ?", norm.Document, norm.Clause)
+		if err != nil {
+			return fmt.Errorf("seed norm reference %s %s: %w", norm.Document, norm.Clause, err)
+		}
+		if created {
+			slog.Info("Norm reference created", "document", norm.Document, "clause", norm.Clause)
+		}
+	}
+	return nil
+}
+
+// seedRecord создает запись, если подходящей под условие еще нет. Удаленные записи
+// тоже учитываются: справочник, удаленный менеджером, не восстанавливается при запуске,
+// а уникальные индексы не мешают запуску.
+func seedRecord(db *gorm.DB, record interface{}, query string, args ...interface{}) (bool, error) {
+	var count int64
+	if err := db.Unscoped().Model(record).Where(query, args...).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	if err := db.Create(record).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,317 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SQL-миграции встраиваются в бинарный файл: migrations/0001_name.up.sql и 0001_name.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Ключ advisory lock: пока один экземпляр применяет миграции, остальные ждут
+const migrationLockKey = 7_215_004_501
+
+// Migration - версия схемы с SQL для применения и отката
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus - состояние миграции в базе данных
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // nil - миграция не применена
+}
+
+// schemaMigration - запись о примененной миграции
+type schemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations возвращает встроенные миграции в порядке версий
+func LoadMigrations() ([]Migration, error) {
+	files, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, file := range files {
+		match := migrationFileName.FindStringSubmatch(file.Name())
+		if match == nil {
+			return nil, fmt.Errorf("неверное имя файла миграции: %s", file.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := migrationFiles.ReadFile("migrations/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("у миграции %d разные имена: %s и %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("у миграции %d нет файла up", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MigrateUp применяет к db все непримененные миграции и возвращает их список.
+// Каждая миграция выполняется в своей транзакции вместе с записью в schema_migrations.
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		pending, err := pendingMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				// CREATE TABLE IF NOT EXISTS не добавляет колонки в таблицы, созданные старой
+				// версией API: такую схему нужно доводить вручную, а не отмечать примененной
+				if missing := missingSchema(tx, migration); len(missing) > 0 {
+					return fmt.Errorf("в существующей схеме не хватает: %s", strings.Join(missing, ", "))
+				}
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("миграция %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("Migration applied", "version", migration.Version, "name", migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown откатывает в db последние steps примененных миграций
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		migrations, err := LoadMigrations()
+		if err != nil {
+			return err
+		}
+		known := make(map[int64]Migration, len(migrations))
+		for _, migration := range migrations {
+			known[migration.Version] = migration
+		}
+
+		var records []schemaMigration
+		if err := conn.Order("version DESC").Limit(steps).Find(&records).Error; err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			migration, ok := known[record.Version]
+			if !ok || migration.Down == "" {
+				return fmt.Errorf("миграцию %d_%s нельзя откатить: нет файла down", record.Version, record.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, record.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("откат миграции %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("Migration reverted", "version", migration.Version, "name", migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses возвращает все известные миграции и время их применения к db
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	records, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := records[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// PendingMigrations возвращает миграции, которые еще не применены к db
+func PendingMigrations(db *gorm.DB) ([]Migration, error) {
+	return pendingMigrations(db)
+}
+
+func pendingMigrations(db *gorm.DB) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	records, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if _, ok := records[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// appliedMigrations читает schema_migrations; таблицы еще нет - миграции не применялись
+func appliedMigrations(db *gorm.DB) (map[int64]schemaMigration, error) {
+	records := map[int64]schemaMigration{}
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return records, nil
+	}
+
+	var list []schemaMigration
+	if err := db.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range list {
+		records[record.Version] = record
+	}
+	return records, nil
+}
+
+// withMigrationLock выполняет fn на одном соединении под advisory lock PostgreSQL,
+// чтобы несколько экземпляров API не применяли миграции одновременно
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		if err := ensureMigrationsTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// ensureMigrationsTable создает schema_migrations. База, созданная через AutoMigrate
+// до перехода на миграции, может уже содержать всю начальную схему: тогда первая миграция
+// отмечается примененной без выполнения. Базы первой версии API содержат только часть
+// таблиц - для них первая миграция выполняется и создает недостающее.
+func ensureMigrationsTable(conn *gorm.DB) error {
+	if conn.Migrator().HasTable(&schemaMigration{}) {
+		return nil
+	}
+
+	existingSchema := conn.Migrator().HasTable("users")
+	if err := conn.Migrator().CreateTable(&schemaMigration{}); err != nil {
+		return err
+	}
+	if !existingSchema {
+		return nil
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return errors.New("нет встроенных миграций")
+	}
+	baseline := migrations[0]
+	if missing := missingSchema(conn, baseline); len(missing) > 0 {
+		slog.Info("Existing schema is incomplete, baseline migration will add missing objects",
+			"version", baseline.Version, "name", baseline.Name, "missing", len(missing))
+		return nil
+	}
+
+	slog.Info("Existing schema found, migration marked as applied", "version", baseline.Version, "name", baseline.Name)
+	return conn.Create(&schemaMigration{
+		Version:   baseline.Version,
+		Name:      baseline.Name,
+		AppliedAt: time.Now(),
+	}).Error
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?s)CREATE TABLE (?:IF NOT EXISTS )?"(\w+)" \((.*?)\n\);`)
+	columnPattern      = regexp.MustCompile(`(?m)^\s+"(\w+)" `)
+)
+
+// missingSchema возвращает таблицы и колонки из CREATE TABLE миграции, которых нет в базе
+// (в виде "таблица" или "таблица.колонка")
+func missingSchema(db *gorm.DB, migration Migration) []string {
+	var missing []string
+	for _, table := range createTablePattern.FindAllStringSubmatch(migration.Up, -1) {
+		if !db.Migrator().HasTable(table[1]) {
+			missing = append(missing, table[1])
+			continue
+		}
+		for _, column := range columnPattern.FindAllStringSubmatch(table[2], -1) {
+			if !db.Migrator().HasColumn(table[1], column[1]) {
+				missing = append(missing, table[1]+"."+column[1])
+			}
+		}
+	}
+	return missing
+}
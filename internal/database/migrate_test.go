@@ -0,0 +1,76 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMissingSchemaDetectsLegacyDatabase(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseline := migrations[0]
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "legacy.db")),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Схема первой версии API: users без organization_id, остальных таблиц нет
+	if err := db.Exec(`CREATE TABLE users (id integer PRIMARY KEY, email text, password text,
+		first_name text, last_name text, phone text, is_active boolean, role_id integer,
+		created_at datetime, updated_at datetime, deleted_at datetime)`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	missing := map[string]bool{}
+	for _, name := range missingSchema(db, baseline) {
+		missing[name] = true
+	}
+	for _, name := range []string{"users.organization_id", "defects", "comments", "roles"} {
+		if !missing[name] {
+			t.Errorf("не найдено отсутствие %s: %v", name, missing)
+		}
+	}
+	if missing["users"] || missing["users.email"] {
+		t.Errorf("существующие таблица и колонки отмечены отсутствующими: %v", missing)
+	}
+
+	// Полная схема не считается неполной
+	full, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "full.db")),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AutoMigrate(full); err != nil {
+		t.Fatal(err)
+	}
+	if missing := missingSchema(full, baseline); len(missing) > 0 {
+		t.Errorf("схема AutoMigrate расходится с начальной миграцией: %v", missing)
+	}
+}
+
+func TestSearchMigrationCoversSearchTables(t *testing.T) {
+	migration, err := searchMigration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Поиск (services/search_service.go) читает search_vector этих таблиц
+	for _, table := range []string{"projects", "defects", "comments", "attachments"} {
+		for _, statement := range []string{
+			"ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS search_vector",
+			"CREATE INDEX IF NOT EXISTS idx_" + table + "_search",
+		} {
+			if !strings.Contains(migration.Up, statement) {
+				t.Errorf("в миграции %s нет %q", searchMigrationName, statement)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS "comment_mentions";
+DROP TABLE IF EXISTS "defect_norms";
+DROP TABLE IF EXISTS "idempotency_keys";
+DROP TABLE IF EXISTS "saved_view_pins";
+DROP TABLE IF EXISTS "saved_views";
+DROP TABLE IF EXISTS "email_digests";
+DROP TABLE IF EXISTS "notification_preferences";
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhooks";
+DROP TABLE IF EXISTS "project_members";
+DROP TABLE IF EXISTS "notifications";
+DROP TABLE IF EXISTS "comments";
+DROP TABLE IF EXISTS "defect_assignments";
+DROP TABLE IF EXISTS "holidays";
+DROP TABLE IF EXISTS "defects";
+DROP TABLE IF EXISTS "norm_references";
+DROP TABLE IF EXISTS "severity_levels";
+DROP TABLE IF EXISTS "defect_categories";
+DROP TABLE IF EXISTS "attachments";
+DROP TABLE IF EXISTS "projects";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "organizations";
+DROP TABLE IF EXISTS "roles";
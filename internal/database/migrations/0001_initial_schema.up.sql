@@ -0,0 +1,366 @@
+-- Начальная схема: соответствует AutoMigrate моделей на момент перехода на SQL-миграции.
+-- Базы первой версии API (AutoMigrate только roles, users, projects и attachments) доводятся
+-- до этой схемы: таблицы создаются IF NOT EXISTS, недостающие колонки добавляются ALTER TABLE.
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_roles_name" UNIQUE ("name"),
+    CONSTRAINT "uni_roles_code" UNIQUE ("code")
+);
+CREATE INDEX IF NOT EXISTS "idx_roles_deleted_at" ON "roles" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "organizations" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "inn" varchar(12) NOT NULL,
+    "type" varchar(50) NOT NULL,
+    "contact_name" varchar(255),
+    "contact_phone" varchar(20),
+    "contact_email" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_organizations_deleted_at" ON "organizations" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_organizations_inn" ON "organizations" ("inn");
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "email" varchar(255) NOT NULL,
+    "password" varchar(255) NOT NULL,
+    "first_name" varchar(100) NOT NULL,
+    "last_name" varchar(100) NOT NULL,
+    "phone" varchar(20),
+    "is_active" boolean DEFAULT true,
+    "role_id" bigint,
+    "organization_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_organizations_users" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id"),
+    CONSTRAINT "fk_users_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id"),
+    CONSTRAINT "uni_users_email" UNIQUE ("email")
+);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "organization_id" bigint
+    CONSTRAINT "fk_organizations_users" REFERENCES "organizations"("id");
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_users_organization_id" ON "users" ("organization_id");
+
+CREATE TABLE IF NOT EXISTS "projects" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "address" varchar(500),
+    "status" varchar(50) DEFAULT 'active',
+    "start_date" timestamptz,
+    "end_date" timestamptz,
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_by" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_projects_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id")
+);
+ALTER TABLE "projects" ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS "idx_projects_deleted_at" ON "projects" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "attachments" (
+    "id" bigserial,
+    "file_name" varchar(255) NOT NULL,
+    "original_name" varchar(255) NOT NULL,
+    "file_path" varchar(500) NOT NULL,
+    "file_size" bigint,
+    "content_type" varchar(100),
+    "file_type" varchar(50),
+    "entity_type" varchar(50) NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "uploaded_by" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_attachments_uploader" FOREIGN KEY ("uploaded_by") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_attachments_deleted_at" ON "attachments" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "defect_categories" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "description" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_defect_categories_name" UNIQUE ("name"),
+    CONSTRAINT "uni_defect_categories_code" UNIQUE ("code")
+);
+CREATE INDEX IF NOT EXISTS "idx_defect_categories_deleted_at" ON "defect_categories" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "severity_levels" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "rank" bigint NOT NULL DEFAULT 0,
+    "fix_days" bigint NOT NULL DEFAULT 0,
+    "description" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_severity_levels_code" UNIQUE ("code"),
+    CONSTRAINT "uni_severity_levels_name" UNIQUE ("name")
+);
+CREATE INDEX IF NOT EXISTS "idx_severity_levels_deleted_at" ON "severity_levels" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "norm_references" (
+    "id" bigserial,
+    "document_type" varchar(20) NOT NULL,
+    "document" varchar(100) NOT NULL,
+    "clause" varchar(50),
+    "title" varchar(500) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_norm_references_deleted_at" ON "norm_references" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "defects" (
+    "id" bigserial,
+    "project_id" bigint NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "description" text,
+    "location" varchar(255),
+    "status" varchar(50) DEFAULT 'new',
+    "priority" varchar(20) DEFAULT 'normal',
+    "category_id" bigint,
+    "severity_id" bigint,
+    "assignee_user_id" bigint,
+    "assignee_org_id" bigint,
+    "due_date" timestamptz,
+    "closed_at" timestamptz,
+    "deadline_warned_at" timestamptz,
+    "escalated_at" timestamptz,
+    "escalated_to" bigint,
+    "client_id" varchar(36),
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_by" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_defects_assignee_user" FOREIGN KEY ("assignee_user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_defects_assignee_org" FOREIGN KEY ("assignee_org_id") REFERENCES "organizations"("id"),
+    CONSTRAINT "fk_defects_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id"),
+    CONSTRAINT "fk_defects_project" FOREIGN KEY ("project_id") REFERENCES "projects"("id"),
+    CONSTRAINT "fk_defects_category" FOREIGN KEY ("category_id") REFERENCES "defect_categories"("id"),
+    CONSTRAINT "fk_defects_severity" FOREIGN KEY ("severity_id") REFERENCES "severity_levels"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_defects_deleted_at" ON "defects" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_defects_client_id" ON "defects" ("client_id");
+CREATE INDEX IF NOT EXISTS "idx_defects_due_date" ON "defects" ("due_date");
+CREATE INDEX IF NOT EXISTS "idx_defects_assignee_org_id" ON "defects" ("assignee_org_id");
+CREATE INDEX IF NOT EXISTS "idx_defects_assignee_user_id" ON "defects" ("assignee_user_id");
+CREATE INDEX IF NOT EXISTS "idx_defects_severity_id" ON "defects" ("severity_id");
+CREATE INDEX IF NOT EXISTS "idx_defects_category_id" ON "defects" ("category_id");
+CREATE INDEX IF NOT EXISTS "idx_defects_status" ON "defects" ("status");
+CREATE INDEX IF NOT EXISTS "idx_defects_project_id" ON "defects" ("project_id");
+
+CREATE TABLE IF NOT EXISTS "holidays" (
+    "id" bigserial,
+    "date" date NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_holidays_date" ON "holidays" ("date");
+
+CREATE TABLE IF NOT EXISTS "defect_assignments" (
+    "id" bigserial,
+    "defect_id" bigint NOT NULL,
+    "assignee_user_id" bigint,
+    "assignee_org_id" bigint,
+    "previous_user_id" bigint,
+    "previous_org_id" bigint,
+    "comment" text,
+    "assigned_by" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_defect_assignments_assignee_user" FOREIGN KEY ("assignee_user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_defect_assignments_assignee_org" FOREIGN KEY ("assignee_org_id") REFERENCES "organizations"("id"),
+    CONSTRAINT "fk_defect_assignments_assigner" FOREIGN KEY ("assigned_by") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_defect_assignments_defect_id" ON "defect_assignments" ("defect_id");
+
+CREATE TABLE IF NOT EXISTS "comments" (
+    "id" bigserial,
+    "defect_id" bigint NOT NULL,
+    "author_id" bigint NOT NULL,
+    "text" text NOT NULL,
+    "client_id" varchar(36),
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_comments_author" FOREIGN KEY ("author_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_comments_deleted_at" ON "comments" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_comments_client_id" ON "comments" ("client_id");
+CREATE INDEX IF NOT EXISTS "idx_comments_defect_id" ON "comments" ("defect_id");
+
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "type" varchar(50) NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "message" text,
+    "project_id" bigint,
+    "entity_type" varchar(50),
+    "entity_id" bigint,
+    "actor_id" bigint,
+    "read_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_read" ON "notifications" ("user_id","read_at");
+
+CREATE TABLE IF NOT EXISTS "project_members" (
+    "id" bigserial,
+    "project_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "added_by" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_project_members_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_project_members_user_id" ON "project_members" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_project_member" ON "project_members" ("project_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "webhooks" (
+    "id" bigserial,
+    "project_id" bigint,
+    "url" varchar(1000) NOT NULL,
+    "secret" varchar(255) NOT NULL,
+    "event_types" text,
+    "description" varchar(500),
+    "is_active" boolean DEFAULT true,
+    "created_by" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhooks_deleted_at" ON "webhooks" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_webhooks_project_id" ON "webhooks" ("project_id");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" bigserial,
+    "webhook_id" bigint NOT NULL,
+    "event_type" varchar(50) NOT NULL,
+    "payload" text NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz,
+    "last_attempt_at" timestamptz,
+    "response_code" bigint,
+    "response_body" text,
+    "error" text,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_queue" ON "webhook_deliveries" ("status","next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_webhook_id" ON "webhook_deliveries" ("webhook_id");
+
+CREATE TABLE IF NOT EXISTS "notification_preferences" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "event_type" varchar(50) NOT NULL,
+    "email" boolean NOT NULL,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notification_pref" ON "notification_preferences" ("user_id","event_type");
+
+CREATE TABLE IF NOT EXISTS "email_digests" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "date" date NOT NULL,
+    "sent_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_email_digest_user_date" ON "email_digests" ("user_id","date");
+
+CREATE TABLE IF NOT EXISTS "saved_views" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "entity_type" varchar(50) NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "query" text,
+    "project_id" bigint,
+    "shared" boolean DEFAULT false,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_saved_views_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_saved_views_deleted_at" ON "saved_views" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_saved_views_project_id" ON "saved_views" ("project_id");
+CREATE INDEX IF NOT EXISTS "idx_saved_views_entity_type" ON "saved_views" ("entity_type");
+CREATE INDEX IF NOT EXISTS "idx_saved_views_user_id" ON "saved_views" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "saved_view_pins" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "saved_view_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_saved_view_pins_saved_view_id" ON "saved_view_pins" ("saved_view_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_saved_view_pin" ON "saved_view_pins" ("user_id","saved_view_id");
+
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "key" varchar(255) NOT NULL,
+    "method" varchar(10) NOT NULL,
+    "path" varchar(500) NOT NULL,
+    "request_hash" varchar(64) NOT NULL,
+    "status_code" bigint NOT NULL DEFAULT 0,
+    "content_type" varchar(255),
+    "response_body" bytea,
+    "created_at" timestamptz,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_key" ON "idempotency_keys" ("user_id","key");
+
+CREATE TABLE IF NOT EXISTS "defect_norms" (
+    "defect_id" bigint,
+    "norm_reference_id" bigint,
+    PRIMARY KEY ("defect_id","norm_reference_id"),
+    CONSTRAINT "fk_defect_norms_defect" FOREIGN KEY ("defect_id") REFERENCES "defects"("id"),
+    CONSTRAINT "fk_defect_norms_norm_reference" FOREIGN KEY ("norm_reference_id") REFERENCES "norm_references"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "comment_mentions" (
+    "comment_id" bigint,
+    "user_id" bigint,
+    PRIMARY KEY ("comment_id","user_id"),
+    CONSTRAINT "fk_comment_mentions_comment" FOREIGN KEY ("comment_id") REFERENCES "comments"("id"),
+    CONSTRAINT "fk_comment_mentions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
@@ -0,0 +1,2 @@
+-- Внешний ключ не восстанавливается: он несовместим с файлами дефектов
+SELECT 1;
@@ -0,0 +1,3 @@
+-- Файлы полиморфно ссылаются на проекты и дефекты, поэтому внешний ключ
+-- attachments.entity_id -> projects.id, созданный ранними версиями, мешает загрузке файлов дефектов
+ALTER TABLE attachments DROP CONSTRAINT IF EXISTS fk_projects_attachments;
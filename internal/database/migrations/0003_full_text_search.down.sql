@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_attachments_search;
+ALTER TABLE attachments DROP COLUMN IF EXISTS search_vector;
+DROP INDEX IF EXISTS idx_comments_search;
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+DROP INDEX IF EXISTS idx_defects_search;
+ALTER TABLE defects DROP COLUMN IF EXISTS search_vector;
+DROP INDEX IF EXISTS idx_projects_search;
+ALTER TABLE projects DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,35 @@
+-- Полнотекстовый поиск: вычисляемые колонки search_vector (русская и английская
+-- конфигурации) и GIN-индексы. Колонки вычисляет PostgreSQL, сервисам не нужно их обновлять.
+-- IF NOT EXISTS: этот же SQL выполняется при запуске в режиме AutoMigrate (database/search.go).
+
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(regexp_replace(original_name, '[._-]+', ' ', 'g'), '')), 'A')
+    || setweight(to_tsvector('english', coalesce(regexp_replace(original_name, '[._-]+', ' ', 'g'), '')), 'A')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_attachments_search ON attachments USING GIN (search_vector);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(text, '')), 'B')
+    || setweight(to_tsvector('english', coalesce(text, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_comments_search ON comments USING GIN (search_vector);
+
+ALTER TABLE defects ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(title, '')), 'A')
+    || setweight(to_tsvector('english', coalesce(title, '')), 'A')
+    || setweight(to_tsvector('russian', coalesce(location, '')), 'B')
+    || setweight(to_tsvector('english', coalesce(location, '')), 'B')
+    || setweight(to_tsvector('russian', coalesce(description, '')), 'C')
+    || setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_defects_search ON defects USING GIN (search_vector);
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(name, '')), 'A')
+    || setweight(to_tsvector('english', coalesce(name, '')), 'A')
+    || setweight(to_tsvector('russian', coalesce(address, '')), 'B')
+    || setweight(to_tsvector('english', coalesce(address, '')), 'B')
+    || setweight(to_tsvector('russian', coalesce(description, '')), 'C')
+    || setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_projects_search ON projects USING GIN (search_vector);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_defects_open_due_date;
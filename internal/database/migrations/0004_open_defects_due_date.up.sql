@@ -0,0 +1,3 @@
+-- Частичный индекс для проверки сроков: планировщик SLA ищет только открытые дефекты
+CREATE INDEX IF NOT EXISTS idx_defects_open_due_date ON defects (due_date)
+    WHERE deleted_at IS NULL AND status NOT IN ('closed', 'cancelled');
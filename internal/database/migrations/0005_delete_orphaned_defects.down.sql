@@ -0,0 +1,2 @@
+-- Удаленные дефекты не восстанавливаются: их нельзя отличить от удаленных вручную
+SELECT 1;
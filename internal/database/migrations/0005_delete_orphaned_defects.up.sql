@@ -0,0 +1,12 @@
+-- Дефекты и их файлы удаляются вместе с проектом. Дефекты проектов, удаленных раньше,
+-- оставались видимыми: удаляем их с тем же временем, что и проект.
+
+UPDATE attachments SET deleted_at = projects.deleted_at
+FROM defects JOIN projects ON projects.id = defects.project_id
+WHERE attachments.entity_type = 'defect' AND attachments.entity_id = defects.id
+    AND attachments.deleted_at IS NULL AND projects.deleted_at IS NOT NULL;
+
+UPDATE defects SET deleted_at = projects.deleted_at
+FROM projects
+WHERE projects.id = defects.project_id
+    AND defects.deleted_at IS NULL AND projects.deleted_at IS NOT NULL;
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// searchMigrationName - миграция с вычисляемыми колонками search_vector и GIN-индексами.
+// Схема поиска описана только в ней: AutoMigrate выполняет тот же SQL.
+const searchMigrationName = "full_text_search"
+
+// searchMigration возвращает встроенную миграцию полнотекстового поиска
+func searchMigration() (Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return Migration{}, err
+	}
+	for _, migration := range migrations {
+		if migration.Name == searchMigrationName {
+			return migration, nil
+		}
+	}
+	return Migration{}, fmt.Errorf("нет миграции %s", searchMigrationName)
+}
+
+// ensureSearchIndexes добавляет вычисляемые колонки search_vector и GIN-индексы,
+// выполняя SQL миграции полнотекстового поиска (он идемпотентен: IF NOT EXISTS).
+// Колонки вычисляются самой PostgreSQL, поэтому сервисам не нужно их обновлять.
+func ensureSearchIndexes(db *gorm.DB) {
+	migration, err := searchMigration()
+	if err == nil {
+		err = db.Exec(migration.Up).Error
+	}
+	if err != nil {
+		slog.Warn("Failed to prepare full-text search", "error", err)
+	}
+}
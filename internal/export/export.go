@@ -0,0 +1,258 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Форматы выгрузки
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Типы значений колонки; определяют форматирование дат
+const (
+	KindText = iota
+	KindNumber
+	KindDate     // Только дата: 02.01.2006
+	KindDateTime // Дата и время: 02.01.2006 15:04
+)
+
+// Column - колонка выгрузки
+type Column struct {
+	Title string
+	Width float64 // Ширина в XLSX (в символах); 0 - по умолчанию
+	Kind  int
+}
+
+// Writer построчно пишет таблицу в выходной поток
+type Writer interface {
+	// WriteRow записывает строку; значения: string, целые числа, time.Time, *time.Time или nil
+	WriteRow(values []interface{}) error
+	// Close дописывает файл; до вызова Close XLSX не выдается в поток
+	Close() error
+}
+
+// ContentType возвращает MIME-тип формата
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// NewWriter создает запись таблицы в формате format и сразу пишет строку заголовков
+func NewWriter(format string, w io.Writer, sheet string, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet, columns)
+	}
+	return nil, errors.New("неподдерживаемый формат выгрузки, допустимо: csv, xlsx")
+}
+
+// csvWriter - CSV для Excel с русской локалью: BOM, разделитель ";"
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	rows    int
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	// BOM нужен, чтобы Excel распознал UTF-8
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return nil, err
+	}
+
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+	cw.w.Comma = ';'
+	cw.w.UseCRLF = true
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Title
+	}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		kind := KindText
+		if i < len(cw.columns) {
+			kind = cw.columns[i].Kind
+		}
+		record[i] = formatValue(value, kind)
+	}
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+
+	// Периодически выталкиваем буфер, чтобы клиент получал данные по мере выборки
+	cw.rows++
+	if cw.rows%500 == 0 {
+		cw.w.Flush()
+	}
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxWriter пишет лист через потоковый режим excelize: строки сбрасываются
+// во временный файл, а не держатся в памяти
+type xlsxWriter struct {
+	out       io.Writer
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	columns   []Column
+	row       int
+	dateStyle int
+	timeStyle int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, columns []Column) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	xw := &xlsxWriter{out: w, file: file, columns: columns, row: 1}
+
+	dateFormat, timeFormat := "dd.mm.yyyy", "dd.mm.yyyy hh:mm"
+	var err error
+	if xw.dateStyle, err = file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if xw.timeStyle, err = file.NewStyle(&excelize.Style{CustomNumFmt: &timeFormat}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#E6E6E6"}},
+		Alignment: &excelize.Alignment{WrapText: true, Vertical: "center"},
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if xw.stream, err = file.NewStreamWriter(sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Ширина колонок задается до первой строки
+	for i, column := range columns {
+		if column.Width > 0 {
+			if err := xw.stream.SetColWidth(i+1, i+1, column.Width); err != nil {
+				file.Close()
+				return nil, err
+			}
+		}
+	}
+	if err := xw.stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: column.Title}
+	}
+	if err := xw.writeCells(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxWriter) WriteRow(values []interface{}) error {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		kind := KindText
+		if i < len(xw.columns) {
+			kind = xw.columns[i].Kind
+		}
+
+		t, isTime := timeValue(value)
+		switch {
+		case isTime && t == nil:
+			cells[i] = nil
+		case isTime && kind == KindDate:
+			cells[i] = excelize.Cell{StyleID: xw.dateStyle, Value: t.In(time.Local)}
+		case isTime:
+			cells[i] = excelize.Cell{StyleID: xw.timeStyle, Value: t.In(time.Local)}
+		default:
+			cells[i] = value
+		}
+	}
+	return xw.writeCells(cells)
+}
+
+func (xw *xlsxWriter) writeCells(cells []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	xw.row++
+	return xw.stream.SetRow(cell, cells)
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	return xw.file.Write(xw.out)
+}
+
+// formatValue форматирует значение для текстовой выгрузки
+func formatValue(value interface{}, kind int) string {
+	if t, isTime := timeValue(value); isTime {
+		if t == nil {
+			return ""
+		}
+		if kind == KindDate {
+			return t.In(time.Local).Format("02.01.2006")
+		}
+		return t.In(time.Local).Format("02.01.2006 15:04")
+	}
+	if value == nil {
+		return ""
+	}
+
+	text := fmt.Sprint(value)
+	// Текст, похожий на формулу, Excel выполнит при открытии CSV - экранируем его
+	if kind == KindText && text != "" && strings.ContainsRune("=+-@", rune(text[0])) {
+		text = "'" + text
+	}
+	return text
+}
+
+// timeValue распознает time.Time и *time.Time; для nil-указателя возвращает (nil, true)
+func timeValue(value interface{}) (*time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return &v, true
+	case *time.Time:
+		return v, true
+	}
+	return nil, false
+}
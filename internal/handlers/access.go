@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Доступ к проектам: менеджеры и наблюдатели видят все проекты, инженеры - только те,
+// в которых участвуют. Запись недоступного проекта для пользователя не существует: 404.
+
+// accessibleScope возвращает проекты, доступные текущему пользователю (nil - все проекты)
+func (h *Handler) accessibleScope(c *gin.Context) ([]uint, error) {
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+	return h.members.AccessibleProjectScope(userID.(uint), roleCode.(string))
+}
+
+// requireProjectAccess отвечает 404, если проект недоступен текущему пользователю
+func (h *Handler) requireProjectAccess(c *gin.Context, projectID uint) bool {
+	if !h.canAccessProject(c, projectID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrProjectNotFound.Error()})
+		return false
+	}
+	return true
+}
+
+// requireDefectAccess отвечает 404, если дефекта нет или его проект недоступен пользователю
+func (h *Handler) requireDefectAccess(c *gin.Context, defectID uint) bool {
+	projectID, err := h.defects.ProjectIDOf(defectID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return false
+	}
+	if !h.canAccessProject(c, projectID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrDefectNotFound.Error()})
+		return false
+	}
+	return true
+}
+
+// requireCommentAccess отвечает 404, если комментария нет или проект его дефекта недоступен
+func (h *Handler) requireCommentAccess(c *gin.Context, commentID uint) bool {
+	projectID, err := h.comments.ProjectIDOf(commentID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrCommentNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return false
+	}
+	if !h.canAccessProject(c, projectID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrCommentNotFound.Error()})
+		return false
+	}
+	return true
+}
+
+// requireEntityAccess отвечает 404, если проект сущности с файлами (проекта или дефекта)
+// не найден или недоступен пользователю
+func (h *Handler) requireEntityAccess(c *gin.Context, entityType string, entityID uint) bool {
+	projectID := h.files.ProjectIDOf(entityType, entityID)
+	if projectID == 0 || !h.canAccessProject(c, projectID) {
+		err := services.ErrProjectNotFound
+		if entityType == models.EntityTypeDefect {
+			err = services.ErrDefectNotFound
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// requireFileAccess загружает запись о файле и отвечает 404, если файла нет
+// или проект его сущности недоступен пользователю
+func (h *Handler) requireFileAccess(c *gin.Context, id uint) (*models.Attachment, bool) {
+	attachment, err := h.files.GetByID(id)
+	if err == nil && !h.canAccessProject(c, h.files.ProjectIDOf(attachment.EntityType, attachment.EntityID)) {
+		err = errors.New("файл не найден")
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return attachment, true
+}
+
+// canAccessProject проверяет, доступен ли проект текущему пользователю
+func (h *Handler) canAccessProject(c *gin.Context, projectID uint) bool {
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+	return h.members.CanAccessProject(userID.(uint), roleCode.(string), projectID)
+}
@@ -4,13 +4,12 @@ import (
 	"net/http"
 
 	"SystemContorlBackend/internal/models"
-	"SystemContorlBackend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Register обрабатывает регистрацию пользователя
-func Register(c *gin.Context) {
+func (h *Handler) Register(c *gin.Context) {
 	var userData models.UserRegister
 
 	// Валидация входных данных
@@ -20,19 +19,12 @@ func Register(c *gin.Context) {
 	}
 
 	// Регистрация пользователя
-	user, err := services.RegisterUser(userData)
+	user, token, err := h.users.Register(userData)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Генерируем токен для нового пользователя
-	token, err := services.GenerateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
-		return
-	}
-
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Пользователь успешно зарегистрирован",
 		"user": gin.H{
@@ -52,7 +44,7 @@ func Register(c *gin.Context) {
 }
 
 // Login обрабатывает вход пользователя
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var loginData models.UserLogin
 
 	// Валидация входных данных
@@ -62,7 +54,7 @@ func Login(c *gin.Context) {
 	}
 
 	// Выполняем вход
-	user, token, err := services.LoginUser(loginData)
+	user, token, err := h.users.Login(loginData)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -87,10 +79,10 @@ func Login(c *gin.Context) {
 }
 
 // GetProfile получает профиль текущего пользователя
-func GetProfile(c *gin.Context) {
+func (h *Handler) GetProfile(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
-	user, err := services.GetUserByID(userID.(uint))
+	user, err := h.users.GetByID(userID.(uint))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
 		return
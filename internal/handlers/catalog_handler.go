@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDefectCategories возвращает справочник категорий дефектов
+func (h *Handler) GetDefectCategories(c *gin.Context) {
+	categories, err := h.catalog.GetDefectCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// CreateDefectCategory создает категорию дефектов (только для менеджеров)
+func (h *Handler) CreateDefectCategory(c *gin.Context) {
+	var input models.DefectCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.catalog.CreateDefectCategory(input)
+	if err != nil {
+		c.JSON(catalogErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Категория успешно создана",
+		"category": category,
+	})
+}
+
+// UpdateDefectCategory обновляет категорию дефектов (только для менеджеров)
+func (h *Handler) UpdateDefectCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID категории"})
+		return
+	}
+
+	var input models.DefectCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.catalog.UpdateDefectCategory(uint(id), input)
+	if err != nil {
+		c.JSON(catalogErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Категория успешно обновлена",
+		"category": category,
+	})
+}
+
+// DeleteDefectCategory удаляет категорию дефектов (только для менеджеров)
+func (h *Handler) DeleteDefectCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID категории"})
+		return
+	}
+
+	if err := h.catalog.DeleteDefectCategory(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Категория успешно удалена"})
+}
+
+// GetSeverityLevels возвращает справочник уровней критичности
+func (h *Handler) GetSeverityLevels(c *gin.Context) {
+	levels, err := h.catalog.GetSeverityLevels()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"severities": levels})
+}
+
+// CreateSeverityLevel создает уровень критичности (только для менеджеров)
+func (h *Handler) CreateSeverityLevel(c *gin.Context) {
+	var input models.SeverityLevelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	level, err := h.catalog.CreateSeverityLevel(input)
+	if err != nil {
+		c.JSON(catalogErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Уровень критичности успешно создан",
+		"severity": level,
+	})
+}
+
+// UpdateSeverityLevel обновляет уровень критичности (только для менеджеров)
+func (h *Handler) UpdateSeverityLevel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID уровня критичности"})
+		return
+	}
+
+	var input models.SeverityLevelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	level, err := h.catalog.UpdateSeverityLevel(uint(id), input)
+	if err != nil {
+		c.JSON(catalogErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Уровень критичности успешно обновлен",
+		"severity": level,
+	})
+}
+
+// DeleteSeverityLevel удаляет уровень критичности (только для менеджеров)
+func (h *Handler) DeleteSeverityLevel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID уровня критичности"})
+		return
+	}
+
+	if err := h.catalog.DeleteSeverityLevel(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Уровень критичности успешно удален"})
+}
+
+// GetNormReferences возвращает справочник нормативных ссылок
+func (h *Handler) GetNormReferences(c *gin.Context) {
+	norms, err := h.catalog.GetNormReferences(c.Query("document_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"norms": norms})
+}
+
+// CreateNormReference создает нормативную ссылку (только для менеджеров)
+func (h *Handler) CreateNormReference(c *gin.Context) {
+	var input models.NormReferenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	norm, err := h.catalog.CreateNormReference(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Нормативная ссылка успешно создана",
+		"norm":    norm,
+	})
+}
+
+// UpdateNormReference обновляет нормативную ссылку (только для менеджеров)
+func (h *Handler) UpdateNormReference(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID нормативной ссылки"})
+		return
+	}
+
+	var input models.NormReferenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	norm, err := h.catalog.UpdateNormReference(uint(id), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Нормативная ссылка успешно обновлена",
+		"norm":    norm,
+	})
+}
+
+// DeleteNormReference удаляет нормативную ссылку (только для менеджеров)
+func (h *Handler) DeleteNormReference(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID нормативной ссылки"})
+		return
+	}
+
+	if err := h.catalog.DeleteNormReference(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Нормативная ссылка успешно удалена"})
+}
+
+// GetHolidays возвращает праздничные дни производственного календаря
+func (h *Handler) GetHolidays(c *gin.Context) {
+	year, _ := strconv.Atoi(c.Query("year"))
+
+	holidays, err := h.sla.GetHolidays(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holidays": holidays})
+}
+
+// CreateHoliday добавляет праздничный день (только для менеджеров)
+func (h *Handler) CreateHoliday(c *gin.Context) {
+	var input models.HolidayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holiday, err := h.sla.CreateHoliday(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Праздничный день добавлен",
+		"holiday": holiday,
+	})
+}
+
+// DeleteHoliday удаляет праздничный день (только для менеджеров)
+func (h *Handler) DeleteHoliday(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID праздничного дня"})
+		return
+	}
+
+	if err := h.sla.DeleteHoliday(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Праздничный день удален"})
+}
+
+// catalogErrorStatus возвращает 409, если код или название записи справочника заняты,
+// иначе fallback
+func catalogErrorStatus(err error, fallback int) int {
+	if errors.Is(err, services.ErrCategoryExists) || errors.Is(err, services.ErrSeverityExists) {
+		return http.StatusConflict
+	}
+	return fallback
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDefectComments получает комментарии дефекта (доступно всем ролям)
+func (h *Handler) GetDefectComments(c *gin.Context) {
+	defectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+	if !h.requireDefectAccess(c, uint(defectID)) {
+		return
+	}
+
+	comments, err := h.comments.ListByDefect(uint(defectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+// CreateDefectComment добавляет комментарий к дефекту (доступно всем ролям)
+func (h *Handler) CreateDefectComment(c *gin.Context) {
+	defectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+	if !h.requireDefectAccess(c, uint(defectID)) {
+		return
+	}
+
+	var input models.CommentCreate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	comment, err := h.comments.Create(c.Request.Context(), uint(defectID), input, userID.(uint))
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Комментарий добавлен",
+		"comment": comment,
+	})
+}
+
+// DeleteComment удаляет комментарий (только автор)
+func (h *Handler) DeleteComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID комментария"})
+		return
+	}
+	if !h.requireCommentAccess(c, uint(id)) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.comments.Delete(uint(id), userID.(uint)); err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Комментарий удален"})
+}
+
+// GetComment получает комментарий по ID (доступно всем ролям)
+func (h *Handler) GetComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID комментария"})
+		return
+	}
+	if !h.requireCommentAccess(c, uint(id)) {
+		return
+	}
+
+	comment, err := h.comments.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, comment.Version)
+	c.JSON(http.StatusOK, gin.H{"comment": comment})
+}
+
+// UpdateComment изменяет текст комментария (только автор).
+// С заголовком If-Match комментарий обновляется, только если его версия не изменилась.
+func (h *Handler) UpdateComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID комментария"})
+		return
+	}
+	if !h.requireCommentAccess(c, uint(id)) {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input models.CommentUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	comment, err := h.comments.Update(uint(id), input, userID.(uint), version)
+	if err != nil {
+		if status, ok := versionErrorStatus(err); ok {
+			current, _ := h.comments.GetByID(uint(id))
+			if current != nil {
+				setETag(c, current.Version)
+			}
+			c.JSON(status, gin.H{"error": err.Error(), "comment": current})
+			return
+		}
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, comment.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Комментарий изменен",
+		"comment": comment,
+	})
+}
+
+// commentErrorStatus возвращает HTTP-статус ошибки работы с комментарием
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCommentNotFound), errors.Is(err, services.ErrDefectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrNotCommentAuthor):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
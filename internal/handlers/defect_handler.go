@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateDefect регистрирует дефект (менеджеры и инженеры)
+func (h *Handler) CreateDefect(c *gin.Context) {
+	var defectData models.DefectCreate
+
+	// Валидация входных данных
+	if err := c.ShouldBindJSON(&defectData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.requireProjectAccess(c, defectData.ProjectID) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	defect, err := h.defects.Create(c.Request.Context(), defectData, userID.(uint))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Дефект успешно зарегистрирован",
+		"defect":  defect,
+	})
+}
+
+// GetDefects получает список дефектов (доступно всем ролям)
+func (h *Handler) GetDefects(c *gin.Context) {
+	// Параметр view подставляет фильтры сохраненного представления
+	values, err := h.listValues(c, models.SavedViewEntityDefect)
+	if err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	filter, err := services.ParseDefectFilter(values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Сортировка, фильтры с операторами, выборка полей и пагинация (page/limit или cursor)
+	options, err := listquery.Parse(services.DefectListSchema, values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Инженер видит только дефекты доступных ему проектов
+	if filter.ProjectIDs, err = h.accessibleScope(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	defects, page, err := h.defects.List(filter, options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := options.Pick(defects)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"defects":    items,
+		"pagination": page.Response(),
+	})
+}
+
+// GetDefect получает дефект по ID (доступно всем ролям)
+func (h *Handler) GetDefect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+	if !h.requireDefectAccess(c, uint(id)) {
+		return
+	}
+
+	defect, err := h.defects.GetByID(uint(id))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, defect.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"defect": defect,
+	})
+}
+
+// UpdateDefect обновляет дефект (менеджеры и инженеры).
+// С заголовком If-Match дефект обновляется, только если его версия не изменилась.
+func (h *Handler) UpdateDefect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+	if !h.requireDefectAccess(c, uint(id)) {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var updateData models.DefectUpdate
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	defect, err := h.defects.Update(c.Request.Context(), uint(id), updateData, userID.(uint), version)
+	if err != nil {
+		// При конфликте версий клиент получает текущее состояние дефекта
+		if status, ok := versionErrorStatus(err); ok {
+			current, _ := h.defects.GetByID(uint(id))
+			if current != nil {
+				setETag(c, current.Version)
+			}
+			c.JSON(status, gin.H{"error": err.Error(), "defect": current})
+			return
+		}
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, defect.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Дефект успешно обновлен",
+		"defect":  defect,
+	})
+}
+
+// PatchDefect частично изменяет дефект по JSON Merge Patch (менеджеры и инженеры).
+// Поле со значением null очищается, отсутствующее поле не меняется.
+func (h *Handler) PatchDefect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+	if !h.requireDefectAccess(c, uint(id)) {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	defect, err := h.defects.Patch(c.Request.Context(), uint(id), patch, userID.(uint), version)
+	if err != nil {
+		if status, ok := versionErrorStatus(err); ok {
+			current, _ := h.defects.GetByID(uint(id))
+			if current != nil {
+				setETag(c, current.Version)
+			}
+			c.JSON(status, gin.H{"error": err.Error(), "defect": current})
+			return
+		}
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, defect.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Дефект успешно обновлен",
+		"defect":  defect,
+	})
+}
+
+// DeleteDefect удаляет дефект (только для менеджеров)
+func (h *Handler) DeleteDefect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.defects.Delete(c.Request.Context(), uint(id), userID.(uint)); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Дефект успешно удален",
+	})
+}
+
+// AssignDefect назначает исполнителя дефекта (только для менеджеров)
+func (h *Handler) AssignDefect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+
+	var input models.DefectAssign
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	defect, err := h.defects.Assign(c.Request.Context(), uint(id), input, userID.(uint))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Исполнитель назначен",
+		"defect":  defect,
+	})
+}
+
+// GetDefectAssignments возвращает историю назначений дефекта
+func (h *Handler) GetDefectAssignments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID дефекта"})
+		return
+	}
+	if !h.requireDefectAccess(c, uint(id)) {
+		return
+	}
+
+	assignments, err := h.defects.ListAssignments(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assignments": assignments})
+}
+
+// GetMyAssignedDefects возвращает дефекты, которые должен устранить текущий пользователь
+func (h *Handler) GetMyAssignedDefects(c *gin.Context) {
+	options, err := listquery.Parse(services.AssignedDefectListSchema, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	defects, page, err := h.defects.ListAssigned(userID.(uint), c.Query("status"), options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := options.Pick(defects)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"defects":    items,
+		"pagination": page.Response(),
+	})
+}
+
+// defectFilterFromQuery собирает фильтр дефектов из параметров запроса
+func defectFilterFromQuery(c *gin.Context) (models.DefectFilter, error) {
+	return services.ParseDefectFilter(c.Request.URL.Query())
+}
+
+// queryDateRange читает период из параметров from и to (обе границы включительно)
+func queryDateRange(c *gin.Context) (from, to *time.Time, err error) {
+	return services.ParseDateRange(c.Request.URL.Query())
+}
+
+// queryUint читает необязательный числовой параметр запроса (0 - не задан)
+func queryUint(c *gin.Context, key string) uint {
+	value, err := strconv.ParseUint(c.Query(key), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(value)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"SystemContorlBackend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag передает версию записи в заголовке ETag: "3"
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatchVersion возвращает версию из заголовка If-Match.
+// 0 - заголовок не передан или равен "*": запись обновляется без проверки версии.
+func ifMatchVersion(c *gin.Context) (uint, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	version, err := strconv.ParseUint(strings.Trim(value, `"`), 10, 32)
+	if err != nil || version == 0 {
+		return 0, errors.New("Неверный заголовок If-Match")
+	}
+	return uint(version), nil
+}
+
+// versionErrorStatus возвращает HTTP-статус для ошибок версии:
+// 412 - If-Match не совпал с текущей версией, 409 - запись изменили одновременно с запросом
+func versionErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, true
+	case errors.Is(err, repository.ErrVersionConflict):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"SystemContorlBackend/internal/export"
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportProjects выгружает проекты в XLSX или CSV (format=xlsx|csv) с фильтрами, сортировкой
+// и сохраненным представлением списка проектов
+func (h *Handler) ExportProjects(c *gin.Context) {
+	values, err := h.listValues(c, models.SavedViewEntityProject)
+	if err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	options, err := listquery.Parse(services.ProjectListSchema, values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scope, err := h.accessibleScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	streamExport(c, "projects", "Проекты", services.ProjectExportColumns, func(w export.Writer) error {
+		return h.exports.Projects(w, values.Get("status"), scope, options)
+	})
+}
+
+// ExportDefects выгружает дефекты в XLSX или CSV с фильтрами, сортировкой
+// и сохраненным представлением списка дефектов
+func (h *Handler) ExportDefects(c *gin.Context) {
+	values, err := h.listValues(c, models.SavedViewEntityDefect)
+	if err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	filter, err := services.ParseDefectFilter(values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	options, err := listquery.Parse(services.DefectListSchema, values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if filter.ProjectIDs, err = h.accessibleScope(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	streamExport(c, "defects", "Дефекты", services.DefectExportColumns, func(w export.Writer) error {
+		return h.exports.Defects(w, filter, options)
+	})
+}
+
+// streamExport отдает выгрузку потоком: строки пишутся в ответ по мере чтения из БД
+func streamExport(c *gin.Context, name, sheet string, columns []export.Column, fill func(w export.Writer) error) {
+	format := c.DefaultQuery("format", export.FormatXLSX)
+	if format != export.FormatXLSX && format != export.FormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат выгрузки, допустимо: xlsx, csv"})
+		return
+	}
+
+	// Большая выгрузка пишется дольше таймаута записи сервера
+	disableWriteTimeout(c)
+
+	fileName := fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102_1504"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, sheet, columns)
+	if err == nil {
+		err = fill(writer)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	// Заголовки уже отправлены, поэтому ошибку можно только записать в журнал
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("Ошибка выгрузки", "export", name, "error", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"SystemContorlBackend/internal/listquery"
 	"SystemContorlBackend/internal/models"
 	"SystemContorlBackend/internal/services"
 
@@ -11,32 +12,50 @@ import (
 )
 
 // GetProjectFiles получает все файлы проекта
-func GetProjectFiles(c *gin.Context) {
+func (h *Handler) GetProjectFiles(c *gin.Context) {
     projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
         return
     }
+    if !h.requireProjectAccess(c, uint(projectID)) {
+        return
+    }
 
-    files, err := services.GetAttachments(models.EntityTypeProject, uint(projectID))
+    options, err := listquery.Parse(services.AttachmentListSchema, c.Request.URL.Query())
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    files, page, err := h.files.List(models.EntityTypeProject, uint(projectID), options)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"files": files})
+    items, err := options.Pick(files)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"files": items, "pagination": page.Response()})
 }
 
 // GetProjectMainImage возвращает первое изображение проекта
-func GetProjectMainImage(c *gin.Context) {
+func (h *Handler) GetProjectMainImage(c *gin.Context) {
 	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
 		return
 	}
+	if !h.requireProjectAccess(c, uint(projectID)) {
+		return
+	}
 
 	// Получаем файлы проекта
-	files, err := services.GetAttachments(models.EntityTypeProject, uint(projectID))
+	files, _, err := h.files.List(models.EntityTypeProject, uint(projectID), nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -47,7 +66,7 @@ func GetProjectMainImage(c *gin.Context) {
 	for _, file := range files {
 		if file.FileType == models.FileTypeImage {
 			// Получаем полную информацию о файле
-			att, err := services.GetAttachmentByID(file.ID)
+			att, err := h.files.GetByID(file.ID)
 			if err == nil {
 				imageAttachment = att
 				break
@@ -66,11 +85,12 @@ func GetProjectMainImage(c *gin.Context) {
 	c.Header("Cache-Control", "public, max-age=3600")
 
 	// Отдаем файл
+	disableWriteTimeout(c)
 	c.File(imageAttachment.FilePath)
 }
 
 // UploadFiles загружает файлы для сущности (проект, дефект)
-func UploadFiles(c *gin.Context) {
+func (h *Handler) UploadFiles(c *gin.Context) {
 	entityType := c.PostForm("entity_type")
 	entityIDStr := c.PostForm("entity_id")
 
@@ -91,6 +111,9 @@ func UploadFiles(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неподдерживаемый тип сущности"})
 		return
 	}
+	if !h.requireEntityAccess(c, entityType, uint(entityID)) {
+		return
+	}
 
 	// Получаем ID пользователя
 	userID, _ := c.Get("user_id")
@@ -113,7 +136,7 @@ func UploadFiles(c *gin.Context) {
 
 	// Загружаем каждый файл
 	for _, file := range files {
-		attachment, err := services.UploadFile(file, entityType, uint(entityID), userID.(uint))
+		attachment, err := h.files.Upload(c.Request.Context(), file, entityType, uint(entityID), userID.(uint))
 		if err != nil {
 			errors = append(errors, file.Filename+": "+err.Error())
 			continue
@@ -144,16 +167,15 @@ func UploadFiles(c *gin.Context) {
 }
 
 // GetFile отдает файл для скачивания
-func GetFile(c *gin.Context) {
+func (h *Handler) GetFile(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID файла"})
 		return
 	}
 
-	attachment, err := services.GetAttachmentByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	attachment, ok := h.requireFileAccess(c, uint(id))
+	if !ok {
 		return
 	}
 
@@ -166,12 +188,13 @@ func GetFile(c *gin.Context) {
 		c.Header("Cache-Control", "public, max-age=3600")
 	}
 
-	// Отдаем файл
+	// Отдаем файл; на медленном соединении передача может занять дольше таймаута записи
+	disableWriteTimeout(c)
 	c.File(attachment.FilePath)
 }
 
 // GetEntityFiles получает список файлов для сущности
-func GetEntityFiles(c *gin.Context) {
+func (h *Handler) GetEntityFiles(c *gin.Context) {
 	entityType := c.Query("entity_type")
 	entityIDStr := c.Query("entity_id")
 
@@ -185,19 +208,35 @@ func GetEntityFiles(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный entity_id"})
 		return
 	}
+	if !h.requireEntityAccess(c, entityType, uint(entityID)) {
+		return
+	}
 
-	files, err := services.GetAttachments(entityType, uint(entityID))
+	options, err := listquery.Parse(services.AttachmentListSchema, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	files, page, err := h.files.List(entityType, uint(entityID), options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := options.Pick(files)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"files": files,
+		"files":      items,
+		"pagination": page.Response(),
 	})
 }
 
-func DeleteFile(c *gin.Context) {
+func (h *Handler) DeleteFile(c *gin.Context) {
 	// Получаем ID файла из URL параметра
 	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -212,8 +251,12 @@ func DeleteFile(c *gin.Context) {
 		return
 	}
 
+	if _, ok := h.requireFileAccess(c, uint(attachmentID)); !ok {
+		return
+	}
+
 	// Удаляем файл
-	err = services.DeleteAttachment(uint(attachmentID), userID.(uint))
+	err = h.files.Delete(c.Request.Context(), uint(attachmentID), userID.(uint))
 	if err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		return
@@ -225,13 +268,17 @@ func DeleteFile(c *gin.Context) {
 }
 
 // ReplaceFile заменяет существующий файл новым
-func ReplaceFile(c *gin.Context) {
+func (h *Handler) ReplaceFile(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID файла"})
 		return
 	}
 
+	if _, ok := h.requireFileAccess(c, uint(id)); !ok {
+		return
+	}
+
 	userID, _ := c.Get("user_id")
 
 	// Получаем загружаемый файл
@@ -242,7 +289,7 @@ func ReplaceFile(c *gin.Context) {
 	}
 
 	// Заменяем файл
-	newAttachment, err := services.ReplaceAttachment(uint(id), file, userID.(uint))
+	newAttachment, err := h.files.Replace(c.Request.Context(), uint(id), file, userID.(uint))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"SystemContorlBackend/internal/realtime"
+	"SystemContorlBackend/internal/services"
+)
+
+// Handler - HTTP-обработчики API. Сервисы передаются при создании,
+// поэтому обработчики можно запускать с тестовой БД или подделками хранилищ.
+type Handler struct {
+	users         *services.UserService
+	projects      *services.ProjectService
+	members       *services.ProjectMemberService
+	files         *services.FileService
+	defects       *services.DefectService
+	comments      *services.CommentService
+	sync          *services.SyncService
+	catalog       *services.CatalogService
+	sla           *services.SLAService
+	organizations *services.OrganizationService
+	notifications *services.NotificationService
+	emails        *services.EmailService
+	webhooks      *services.WebhookService
+	savedViews    *services.SavedViewService
+	search        *services.SearchService
+	stats         *services.StatsService
+	exports       *services.ExportService
+	reports       *services.ReportService
+	imports       *services.ImportService
+	health        *services.HealthService
+
+	// broker - шина событий реального времени для потоков SSE
+	broker realtime.Broker
+
+	// closing закрывается при остановке сервера и завершает долгие потоки событий
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// New создает обработчики поверх сервисов svc; потоки событий читают из broker
+func New(svc *services.Services, broker realtime.Broker) *Handler {
+	return &Handler{
+		users:         svc.Users,
+		projects:      svc.Projects,
+		members:       svc.Members,
+		files:         svc.Files,
+		defects:       svc.Defects,
+		comments:      svc.Comments,
+		sync:          svc.Sync,
+		catalog:       svc.Catalog,
+		sla:           svc.SLA,
+		organizations: svc.Organizations,
+		notifications: svc.Notifications,
+		emails:        svc.Emails,
+		webhooks:      svc.Webhooks,
+		savedViews:    svc.SavedViews,
+		search:        svc.Search,
+		stats:         svc.Stats,
+		exports:       svc.Exports,
+		reports:       svc.Reports,
+		imports:       svc.Imports,
+		health:        svc.Health,
+		broker:        broker,
+		closing:       make(chan struct{}),
+	}
+}
+
+// CloseStreams завершает открытые потоки событий (SSE), чтобы остановка сервера
+// не ждала их до таймаута; клиенты переподключатся к другому экземпляру
+func (h *Handler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// errorStatus возвращает HTTP-статус ошибки сервиса: 404 - запись не найдена,
+// 400 - данные запроса не прошли проверку, 500 - остальные ошибки
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrDefectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidInput):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
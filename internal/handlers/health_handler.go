@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz - проверка живости: процесс запущен и обрабатывает запросы
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz - проверка готовности: доступна БД, применены миграции, можно сохранять файлы.
+// Во время остановки сервера возвращает 503, чтобы на экземпляр перестали направлять запросы.
+func (h *Handler) Readyz(c *gin.Context) {
+	ready, checks := h.health.Ready(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
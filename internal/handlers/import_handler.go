@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"SystemContorlBackend/internal/importer"
+	"SystemContorlBackend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимальный размер файла импорта: 20MB
+const maxImportFileSize = 20 << 20
+
+// ImportProjects импортирует проекты из CSV/XLSX (только для менеджеров).
+// С параметром dry_run=true только проверяет файл и возвращает ошибки по строкам.
+func (h *Handler) ImportProjects(c *gin.Context) {
+	runImport(c, h.imports.Projects)
+}
+
+// ImportDefects импортирует дефекты из CSV/XLSX (только для менеджеров)
+func (h *Handler) ImportDefects(c *gin.Context) {
+	runImport(c, h.imports.Defects)
+}
+
+// runImport читает загруженный файл и передает таблицу в функцию импорта
+func runImport(c *gin.Context, importFunc func(ctx context.Context, table *importer.Table, importedBy uint, dryRun bool) (*models.ImportResult, error)) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл не передан (поле file)"})
+		return
+	}
+	if file.Size > maxImportFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл слишком большой. Максимальный размер: 20MB"})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка открытия файла"})
+		return
+	}
+	defer src.Close()
+
+	table, err := importer.Read(format, src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	dryRun := c.Query("dry_run") == "true"
+
+	result, err := importFunc(c.Request.Context(), table, userID.(uint), dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, gin.H{"result": result})
+	case len(result.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Файл содержит ошибки, ничего не импортировано",
+			"result": result,
+		})
+	default:
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Импорт выполнен",
+			"result":  result,
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"SystemContorlBackend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLogLevel возвращает текущий уровень журнала
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logging.Level()})
+}
+
+// SetLogLevel меняет уровень журнала без перезапуска сервера (до следующего запуска)
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	var body struct {
+		Level string `json:"level" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := logging.Level()
+	if err := logging.SetLevel(body.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logging.FromContext(c.Request.Context()).Warn("Уровень журнала изменен",
+		"from", previous, "to", logging.Level())
+	c.JSON(http.StatusOK, gin.H{"level": logging.Level()})
+}
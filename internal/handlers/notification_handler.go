@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications получает уведомления текущего пользователя
+func (h *Handler) GetNotifications(c *gin.Context) {
+	options, err := listquery.Parse(services.NotificationListSchema, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	notifications, page, unread, err := h.notifications.List(userID.(uint), c.Query("unread") == "true", options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := options.Pick(notifications)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": items,
+		"unread_count":  unread,
+		"pagination":    page.Response(),
+	})
+}
+
+// GetUnreadNotificationsCount возвращает количество непрочитанных уведомлений
+func (h *Handler) GetUnreadNotificationsCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	unread, err := h.notifications.CountUnread(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID уведомления"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.notifications.MarkRead(uint(id), userID.(uint)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Уведомление прочитано"})
+}
+
+// MarkAllNotificationsRead отмечает все уведомления прочитанными
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	updated, err := h.notifications.MarkAllRead(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Все уведомления прочитаны",
+		"updated": updated,
+	})
+}
+
+// GetNotificationPreferences получает настройки email-уведомлений текущего пользователя
+func (h *Handler) GetNotificationPreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	preferences, err := h.emails.GetPreferences(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// UpdateNotificationPreferences обновляет настройки email-уведомлений текущего пользователя
+func (h *Handler) UpdateNotificationPreferences(c *gin.Context) {
+	var input models.NotificationPreferencesUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	preferences, err := h.emails.UpdatePreferences(userID.(uint), input.Preferences)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Настройки уведомлений сохранены",
+		"preferences": preferences,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOrganizations получает список организаций (доступно всем ролям)
+func (h *Handler) GetOrganizations(c *gin.Context) {
+	organizations, err := h.organizations.List(c.Query("type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": organizations})
+}
+
+// GetOrganization получает организацию с сотрудниками (доступно всем ролям)
+func (h *Handler) GetOrganization(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID организации"})
+		return
+	}
+
+	organization, err := h.organizations.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organization": organization})
+}
+
+// CreateOrganization создает организацию (только для менеджеров)
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	var input models.OrganizationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizations.Create(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Организация успешно создана",
+		"organization": organization,
+	})
+}
+
+// UpdateOrganization обновляет организацию (только для менеджеров)
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID организации"})
+		return
+	}
+
+	var input models.OrganizationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizations.Update(uint(id), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Организация успешно обновлена",
+		"organization": organization,
+	})
+}
+
+// DeleteOrganization удаляет организацию (только для менеджеров)
+func (h *Handler) DeleteOrganization(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID организации"})
+		return
+	}
+
+	if err := h.organizations.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Организация успешно удалена"})
+}
+
+// AddOrganizationMember добавляет пользователя в организацию (только для менеджеров)
+func (h *Handler) AddOrganizationMember(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID организации"})
+		return
+	}
+
+	var input models.OrganizationMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.organizations.AddMember(uint(id), input.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь добавлен в организацию"})
+}
+
+// RemoveOrganizationMember исключает пользователя из организации (только для менеджеров)
+func (h *Handler) RemoveOrganizationMember(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID организации"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID пользователя"})
+		return
+	}
+
+	if err := h.organizations.RemoveMember(uint(id), uint(userID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь исключен из организации"})
+}
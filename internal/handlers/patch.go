@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"SystemContorlBackend/internal/mergepatch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимальный размер тела PATCH
+const maxPatchSize = 1 << 20
+
+// readMergePatch читает тело PATCH-запроса. Принимается application/merge-patch+json
+// и, для клиентов, которые не умеют его передавать, application/json.
+func readMergePatch(c *gin.Context) ([]byte, bool) {
+	switch c.ContentType() {
+	case mergepatch.ContentType, "application/json":
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Ожидается Content-Type: " + mergepatch.ContentType})
+		return nil, false
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPatchSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать тело запроса"})
+		return nil, false
+	}
+	if len(patch) > maxPatchSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Слишком большое тело запроса"})
+		return nil, false
+	}
+	return patch, true
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"SystemContorlBackend/internal/listquery"
 	"SystemContorlBackend/internal/models"
 	"SystemContorlBackend/internal/services"
 
@@ -11,7 +12,7 @@ import (
 )
 
 // CreateProject создает новый проект (только для менеджеров)
-func CreateProject(c *gin.Context) {
+func (h *Handler) CreateProject(c *gin.Context) {
 	var projectData models.ProjectCreate
 
 	// Валидация входных данных
@@ -24,7 +25,7 @@ func CreateProject(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
 	// Создаем проект
-	project, err := services.CreateProject(projectData, userID.(uint))
+	project, err := h.projects.Create(c.Request.Context(), projectData, userID.(uint))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,80 +38,152 @@ func CreateProject(c *gin.Context) {
 }
 
 // GetProjects получает список проектов (доступно всем ролям)
-func GetProjects(c *gin.Context) {
-	// Параметры пагинации
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	status := c.Query("status")
-
-	if page < 1 {
-		page = 1
+func (h *Handler) GetProjects(c *gin.Context) {
+	// Параметр view подставляет фильтры сохраненного представления
+	values, err := h.listValues(c, models.SavedViewEntityProject)
+	if err != nil {
+		savedViewError(c, err)
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+	status := values.Get("status")
+
+	// Сортировка, фильтры с операторами, выборка полей и пагинация (page/limit или cursor)
+	options, err := listquery.Parse(services.ProjectListSchema, values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	offset := (page - 1) * limit
+	// Инженер видит только проекты, в которых участвует
+	scope, err := h.accessibleScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	projects, total, err := services.GetProjects(status, limit, offset)
+	projects, page, err := h.projects.List(status, scope, options)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Подсчет общего количества страниц
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	items, err := options.Pick(projects)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"projects": projects,
-		"pagination": gin.H{
-			"current_page": page,
-			"total_pages":  totalPages,
-			"total_items":  total,
-			"limit":        limit,
-		},
+		"projects":   items,
+		"pagination": page.Response(),
 	})
 }
 
 // GetProject получает проект по ID (доступно всем ролям)
-func GetProject(c *gin.Context) {
+func (h *Handler) GetProject(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
 		return
 	}
+	if !h.requireProjectAccess(c, uint(id)) {
+		return
+	}
 
-	project, err := services.GetProjectByID(uint(id))
+	project, err := h.projects.GetByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	setETag(c, project.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"project": project,
 	})
 }
 
-// UpdateProject обновляет проект (только для менеджеров)
-func UpdateProject(c *gin.Context) {
+// UpdateProject обновляет проект (только для менеджеров).
+// С заголовком If-Match проект обновляется, только если его версия не изменилась.
+func (h *Handler) UpdateProject(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
 		return
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var updateData models.ProjectUpdate
 	if err := c.ShouldBindJSON(&updateData); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	project, err := services.UpdateProject(uint(id), updateData)
+	userID, _ := c.Get("user_id")
+
+	project, err := h.projects.Update(c.Request.Context(), uint(id), updateData, userID.(uint), version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// При конфликте версий клиент получает текущее состояние проекта
+		if status, ok := versionErrorStatus(err); ok {
+			current, _ := h.projects.GetByID(uint(id))
+			if current != nil {
+				setETag(c, current.Version)
+			}
+			c.JSON(status, gin.H{"error": err.Error(), "project": current})
+			return
+		}
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	setETag(c, project.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Проект успешно обновлен",
+		"project": project,
+	})
+}
+
+// PatchProject частично изменяет проект по JSON Merge Patch (только для менеджеров).
+// Поле со значением null очищается, отсутствующее поле не меняется.
+func (h *Handler) PatchProject(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	project, err := h.projects.Patch(c.Request.Context(), uint(id), patch, userID.(uint), version)
+	if err != nil {
+		if status, ok := versionErrorStatus(err); ok {
+			current, _ := h.projects.GetByID(uint(id))
+			if current != nil {
+				setETag(c, current.Version)
+			}
+			c.JSON(status, gin.H{"error": err.Error(), "project": current})
+			return
+		}
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, project.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Проект успешно обновлен",
 		"project": project,
@@ -118,16 +191,18 @@ func UpdateProject(c *gin.Context) {
 }
 
 // DeleteProject удаляет проект (только для менеджеров)
-func DeleteProject(c *gin.Context) {
+func (h *Handler) DeleteProject(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
 		return
 	}
 
-	err = services.DeleteProject(uint(id))
+	userID, _ := c.Get("user_id")
+
+	err = h.projects.Delete(c.Request.Context(), uint(id), userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -135,4 +210,4 @@ func DeleteProject(c *gin.Context) {
 		"message": "Проект успешно удален",
 	})
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectMembers получает участников проекта (доступно всем ролям)
+func (h *Handler) GetProjectMembers(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+		return
+	}
+	if !h.requireProjectAccess(c, uint(projectID)) {
+		return
+	}
+
+	members, err := h.members.List(uint(projectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// AddProjectMember добавляет участника проекта (только для менеджеров)
+func (h *Handler) AddProjectMember(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+		return
+	}
+
+	var input models.ProjectMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	member, err := h.members.Add(uint(projectID), input.UserID, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Участник добавлен в проект",
+		"member":  member,
+	})
+}
+
+// RemoveProjectMember исключает участника проекта (только для менеджеров)
+func (h *Handler) RemoveProjectMember(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID пользователя"})
+		return
+	}
+
+	if err := h.members.Remove(uint(projectID), uint(memberID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Участник исключен из проекта"})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDefectReportPDF формирует акт осмотра проекта в PDF (только для менеджеров).
+// Принимает те же фильтры, что и список дефектов: status, from, to, location и др.
+func (h *Handler) GetDefectReportPDF(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+		return
+	}
+
+	filter, err := defectFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+	if !h.members.CanAccessProject(userID.(uint), roleCode.(string), uint(projectID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "проект не найден"})
+		return
+	}
+
+	// Акт по большому проекту собирается и передается дольше таймаута записи сервера
+	disableWriteTimeout(c)
+
+	// Документ собирается в памяти, чтобы при ошибке вернуть JSON, а не оборванный файл
+	var buf bytes.Buffer
+	if err := h.reports.WriteDefectReport(c.Request.Context(), &buf, uint(projectID), filter, userID.(uint)); err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileName := fmt.Sprintf("defects_%d_%s.pdf", projectID, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
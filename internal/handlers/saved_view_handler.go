@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"SystemContorlBackend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSavedViews получает свои и общие представления (фильтр entity_type)
+func (h *Handler) GetSavedViews(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	views, err := h.savedViews.List(userID.(uint), roleCode.(string), c.Query("entity_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"views": views})
+}
+
+// GetSavedViewCounts возвращает количество записей в каждом представлении
+func (h *Handler) GetSavedViewCounts(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	counts, err := h.savedViews.Count(userID.(uint), roleCode.(string), c.Query("entity_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"counts": counts})
+}
+
+// GetSavedView получает представление по ID
+func (h *Handler) GetSavedView(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID представления"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	view, err := h.savedViews.GetByID(uint(id), userID.(uint), roleCode.(string))
+	if err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"view": view})
+}
+
+// CreateSavedView сохраняет представление
+func (h *Handler) CreateSavedView(c *gin.Context) {
+	var input models.SavedViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	view, err := h.savedViews.Create(input, userID.(uint), roleCode.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Представление сохранено",
+		"view":    view,
+	})
+}
+
+// UpdateSavedView изменяет представление (только владелец)
+func (h *Handler) UpdateSavedView(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID представления"})
+		return
+	}
+
+	var input models.SavedViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	view, err := h.savedViews.Update(uint(id), input, userID.(uint), roleCode.(string))
+	if err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Представление обновлено",
+		"view":    view,
+	})
+}
+
+// DeleteSavedView удаляет представление (только владелец)
+func (h *Handler) DeleteSavedView(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID представления"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	if err := h.savedViews.Delete(uint(id), userID.(uint), roleCode.(string)); err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Представление удалено"})
+}
+
+// PinSavedView закрепляет представление у текущего пользователя
+func (h *Handler) PinSavedView(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID представления"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	if err := h.savedViews.Pin(uint(id), userID.(uint), roleCode.(string)); err != nil {
+		savedViewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Представление закреплено"})
+}
+
+// UnpinSavedView снимает закрепление представления
+func (h *Handler) UnpinSavedView(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID представления"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.savedViews.Unpin(uint(id), userID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Закрепление снято"})
+}
+
+// listValues возвращает параметры списочного запроса; если указан view,
+// к ним добавляются параметры сохраненного представления
+func (h *Handler) listValues(c *gin.Context, entityType string) (url.Values, error) {
+	values := c.Request.URL.Query()
+	if values.Get("view") == "" {
+		return values, nil
+	}
+
+	id, err := strconv.ParseUint(values.Get("view"), 10, 32)
+	if err != nil {
+		return nil, errors.New("неверный ID представления")
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	return h.savedViews.Apply(uint(id), userID.(uint), roleCode.(string), entityType, values)
+}
+
+// savedViewError отвечает статусом, соответствующим ошибке сервиса представлений
+func savedViewError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "недостаточно прав"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
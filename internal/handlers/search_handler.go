@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search выполняет полнотекстовый поиск по доступным пользователю данным (доступно всем ролям).
+// Параметры: q - запрос, types - типы через запятую (project, defect, comment, file), limit.
+func (h *Handler) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if utf8.RuneCountInString(q) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Поисковый запрос должен содержать не менее 2 символов"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var types []string
+	for _, searchType := range strings.Split(c.Query("types"), ",") {
+		searchType = strings.TrimSpace(searchType)
+		if searchType == "" {
+			continue
+		}
+		if !slices.Contains(services.SearchTypes, searchType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный тип поиска: " + searchType})
+			return
+		}
+		types = append(types, searchType)
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	results, err := h.search.Search(q, types, userID.(uint), roleCode.(string), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   q,
+		"results": results,
+		"total":   len(results),
+	})
+}
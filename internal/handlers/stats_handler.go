@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPortfolioStats возвращает аналитику по дефектам всех проектов (менеджеры и наблюдатели)
+func (h *Handler) GetPortfolioStats(c *gin.Context) {
+	filter, err := statsFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.stats.GetDefectStats(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// GetProjectStats возвращает аналитику по дефектам проекта (менеджеры и наблюдатели)
+func (h *Handler) GetProjectStats(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+		return
+	}
+
+	filter, err := statsFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.ProjectID = uint(projectID)
+
+	stats, err := h.stats.GetDefectStats(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// statsFilterFromQuery читает период (from, to), глубину недельного тренда weeks
+// и часовой пояс tz (имя IANA, по умолчанию UTC), в котором считаются даты и недели
+func statsFilterFromQuery(c *gin.Context) (models.StatsFilter, error) {
+	var filter models.StatsFilter
+	filter.Weeks, _ = strconv.Atoi(c.Query("weeks"))
+
+	// "Local" зависит от настроек сервера и неизвестен PostgreSQL
+	location, err := time.LoadLocation(c.Query("tz"))
+	if err != nil || location == time.Local {
+		return filter, errors.New("неизвестный часовой пояс tz")
+	}
+	filter.Location = location
+
+	from, to, err := services.ParseDateRangeIn(c.Request.URL.Query(), location)
+	if err != nil {
+		return filter, err
+	}
+	filter.CreatedFrom, filter.CreatedTo = from, to
+
+	return filter, nil
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"SystemContorlBackend/internal/realtime"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Интервал служебных сообщений, не дающих прокси закрыть простаивающее соединение
+const streamHeartbeat = 25 * time.Second
+
+// Сколько пропущенных событий отдается при переподключении
+const streamReplayLimit = 500
+
+// StreamEvents отдает поток событий (Server-Sent Events) по доступным пользователю проектам.
+// Параметр project_id (можно несколько или через запятую) ограничивает подписку проектами,
+// заголовок Last-Event-ID позволяет получить события, пропущенные при обрыве связи.
+func (h *Handler) StreamEvents(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+	uid := userID.(uint)
+	role := roleCode.(string)
+
+	// Проекты, на которые подписывается клиент (пусто - все доступные)
+	subscribed := map[uint]bool{}
+	for _, value := range c.QueryArray("project_id") {
+		for _, part := range strings.Split(value, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+				return
+			}
+			subscribed[uint(id)] = true
+		}
+	}
+
+	accessible, all, err := h.loadAccessibleProjects(uid, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for projectID := range subscribed {
+		if !all && !accessible[projectID] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Нет доступа к проекту"})
+			return
+		}
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	lastID, _ := strconv.ParseUint(lastEventID, 10, 64)
+
+	// Подписываемся до чтения истории, чтобы не потерять события между ними
+	events, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+
+	var missed []realtime.Message
+	if lastID > 0 {
+		missed, err = h.broker.Since(lastID, streamReplayLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Поток живет дольше таймаута записи сервера
+	disableWriteTimeout(c)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	visible := func(msg realtime.Message) bool {
+		if msg.UserID != 0 {
+			return msg.UserID == uid
+		}
+		if msg.ProjectID == 0 {
+			return false
+		}
+		if len(subscribed) > 0 && !subscribed[msg.ProjectID] {
+			return false
+		}
+		return all || accessible[msg.ProjectID]
+	}
+
+	sentID := lastID
+	send := func(msg realtime.Message) {
+		if msg.ID <= sentID {
+			return
+		}
+		sentID = msg.ID
+		if !visible(msg) {
+			return
+		}
+		c.Render(-1, sse.Event{
+			Id:    strconv.FormatUint(msg.ID, 10),
+			Event: msg.Type,
+			Data:  msg,
+		})
+		c.Writer.Flush()
+	}
+
+	for _, msg := range missed {
+		send(msg)
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.closing:
+			return
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+			send(msg)
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+			// Доступ инженера меняется при назначениях - обновляем его периодически
+			if !all {
+				if refreshed, _, err := h.loadAccessibleProjects(uid, role); err == nil {
+					accessible = refreshed
+				}
+			}
+		}
+	}
+}
+
+// loadAccessibleProjects возвращает множество доступных пользователю проектов
+func (h *Handler) loadAccessibleProjects(userID uint, roleCode string) (map[uint]bool, bool, error) {
+	ids, all, err := h.members.AccessibleProjectIDs(userID, roleCode)
+	if err != nil {
+		return nil, false, err
+	}
+
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, all, nil
+}
+
+// disableWriteTimeout снимает таймаут записи сервера для ответа, который передается дольше него:
+// поток событий, выгрузки и файлы, отдаваемые клиенту на медленном соединении
+func disableWriteTimeout(c *gin.Context) {
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSyncDelta возвращает изменения с момента прошлой синхронизации (доступно всем ролям).
+// Параметры: token - sync_token из прошлого ответа (пусто при первой синхронизации),
+// project_id - ограничить одним проектом, limit - записей каждого типа.
+// Пока has_more = true, клиент повторяет запрос с новым токеном.
+func (h *Handler) GetSyncDelta(c *gin.Context) {
+	var projectID uint64
+	if value := c.Query("project_id"); value != "" {
+		var err error
+		projectID, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID проекта"})
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	delta, err := h.sync.Delta(userID.(uint), roleCode.(string), c.Query("token"), uint(projectID), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSyncToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, delta)
+}
+
+// PushSyncChanges применяет изменения, сделанные офлайн (только для менеджеров и инженеров).
+// Результат возвращается по каждому изменению; файлы загружаются отдельно через /files/upload.
+func (h *Handler) PushSyncChanges(c *gin.Context) {
+	var push models.SyncPush
+	if err := c.ShouldBindJSON(&push); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	roleCode, _ := c.Get("role_code")
+
+	results := h.sync.Apply(c.Request.Context(), push.Changes, userID.(uint), roleCode.(string))
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+	})
+}
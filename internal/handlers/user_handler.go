@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsers получает список пользователей (только для менеджеров)
+func (h *Handler) GetUsers(c *gin.Context) {
+	options, err := listquery.Parse(services.UserListSchema, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, page, err := h.users.List(options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := options.Pick(users)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":      items,
+		"pagination": page.Response(),
+	})
+}
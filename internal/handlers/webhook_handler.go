@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhooks получает список webhook (фильтр project_id)
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhooks.List(queryUint(c, "project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// GetWebhook получает webhook по ID
+func (h *Handler) GetWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID webhook"})
+		return
+	}
+
+	webhook, err := h.webhooks.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook})
+}
+
+// CreateWebhook регистрирует webhook (только для менеджеров)
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var input models.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	webhook, secret, err := h.webhooks.Create(input, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook зарегистрирован",
+		"webhook": webhook,
+		"secret":  secret,
+	})
+}
+
+// UpdateWebhook обновляет webhook (только для менеджеров)
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID webhook"})
+		return
+	}
+
+	var input models.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhooks.Update(uint(id), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook обновлен",
+		"webhook": webhook,
+	})
+}
+
+// DeleteWebhook удаляет webhook (только для менеджеров)
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID webhook"})
+		return
+	}
+
+	if err := h.webhooks.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook удален"})
+}
+
+// GetWebhookDeliveries получает журнал доставок webhook
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID webhook"})
+		return
+	}
+
+	options, err := listquery.Parse(services.WebhookDeliveryListSchema, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, page, err := h.webhooks.Deliveries(uint(id), c.Query("status"), options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := options.Pick(deliveries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": items,
+		"pagination": page.Response(),
+	})
+}
+
+// TestWebhook отправляет тестовое событие на webhook
+func (h *Handler) TestWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID webhook"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	delivery, err := h.webhooks.Test(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  delivery.Status == models.WebhookDeliveryDelivered,
+		"delivery": delivery,
+	})
+}
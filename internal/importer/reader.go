@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Максимальное число строк данных в одном файле импорта
+const MaxRows = 10000
+
+// Table - таблица импорта: заголовки и строки данных
+type Table struct {
+	columns map[string]int
+	Rows    []Row
+}
+
+// Row - строка данных с номером строки в файле (для сообщений об ошибках)
+type Row struct {
+	Number int
+	Values []string
+}
+
+// Read читает таблицу из CSV или XLSX; первая строка - заголовки.
+// Для XLSX читается первый лист, даты приходят числами Excel (см. ParseDate).
+func Read(format string, r io.Reader) (*Table, error) {
+	var records [][]string
+	var err error
+
+	switch format {
+	case "csv":
+		records, err = readCSV(r)
+	case "xlsx":
+		records, err = readXLSX(r)
+	default:
+		return nil, errors.New("неподдерживаемый формат файла, допустимо: csv, xlsx")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("файл пуст")
+	}
+	if len(records)-1 > MaxRows {
+		return nil, errors.New("слишком много строк, максимум " + strconv.Itoa(MaxRows))
+	}
+
+	table := &Table{columns: make(map[string]int)}
+	for i, title := range records[0] {
+		table.columns[normalize(title)] = i
+	}
+	for i, record := range records[1:] {
+		if !isBlank(record) {
+			table.Rows = append(table.Rows, Row{Number: i + 2, Values: record})
+		}
+	}
+	return table, nil
+}
+
+// Has проверяет, есть ли в таблице колонка с одним из названий
+func (t *Table) Has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := t.columns[normalize(name)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Value возвращает значение первой найденной колонки из names (например, "name" и "Название")
+func (t *Table) Value(row Row, names ...string) string {
+	for _, name := range names {
+		if i, ok := t.columns[normalize(name)]; ok {
+			if i < len(row.Values) {
+				return strings.TrimSpace(row.Values[i])
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// ParseDate разбирает дату в форматах 2006-01-02, 02.01.2006, RFC3339 или числом даты Excel.
+// Пустое значение - nil без ошибки.
+func ParseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "02.01.2006 15:04", "2006-01-02 15:04:05", time.RFC3339} {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &date, nil
+		}
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		if date, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			// Дата Excel не содержит часового пояса - считаем ее местной
+			local := time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), 0, time.Local)
+			return &local, nil
+		}
+	}
+
+	return nil, errors.New("неверный формат даты: " + value)
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := bufio.NewReader(r)
+
+	// Пропускаем BOM, который добавляет Excel
+	if bom, err := reader.Peek(3); err == nil && bytes.Equal(bom, []byte("\xEF\xBB\xBF")) {
+		reader.Discard(3)
+	}
+
+	// Разделитель определяем по первой строке: Excel с русской локалью сохраняет через ";"
+	comma := ','
+	line, _ := reader.Peek(4096)
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		comma = ';'
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = comma
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.New("ошибка чтения CSV: " + err.Error())
+	}
+	return records, nil
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, errors.New("ошибка чтения XLSX: " + err.Error())
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("в файле нет листов")
+	}
+
+	rows, err := file.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, errors.New("ошибка чтения XLSX: " + err.Error())
+	}
+	return rows, nil
+}
+
+func normalize(title string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(title, "\ufeff")))
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,465 @@
+// Package listquery разбирает параметры списочных запросов - сортировку, фильтры
+// с операторами и выборку полей - и превращает их в условия GORM.
+//
+// Поддерживаемый синтаксис:
+//
+//	sort=-created_at,name           сортировка, "-" - по убыванию
+//	start_date[gte]=2024-01-01      фильтр с оператором
+//	creator_id[in]=1,2,3            список значений
+//	name[ilike]=корпус              поиск подстроки без учета регистра
+//	end_date[null]=true             IS NULL / IS NOT NULL
+//	fields=id,name,status           выборка полей ответа
+//	page=2&limit=20                 постраничная навигация
+//	cursor=&limit=20                навигация по курсору (keyset), см. Find
+//
+// Сортировать и фильтровать можно только по полям из белого списка Schema,
+// поэтому в SQL попадают лишь заранее известные колонки.
+package listquery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FieldType - тип значения поля, определяет разбор значения фильтра и допустимые операторы
+type FieldType int
+
+const (
+	String FieldType = iota
+	Number
+	Time
+	Bool
+)
+
+// Операторы фильтров
+const (
+	OpEq    = "eq"
+	OpNe    = "ne"
+	OpGt    = "gt"
+	OpGte   = "gte"
+	OpLt    = "lt"
+	OpLte   = "lte"
+	OpIn    = "in"
+	OpNin   = "nin"
+	OpLike  = "like"
+	OpIlike = "ilike"
+	OpNull  = "null"
+)
+
+// Максимальное количество значений в фильтрах in/nin и полей сортировки
+const (
+	maxInValues  = 100
+	maxSortParts = 5
+)
+
+// Размер страницы по умолчанию и максимальный
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Field - поле, по которому разрешены сортировка и фильтрация
+type Field struct {
+	Column string // Колонка в SQL, например "projects.created_at"
+	Type   FieldType
+}
+
+// Schema - белый список полей списочного эндпоинта
+type Schema struct {
+	Fields      map[string]Field // Поля для sort и фильтров (ключ - имя в запросе)
+	Selectable  []string         // Поля ответа (ключи JSON), доступные в fields
+	DefaultSort string           // Сортировка, если sort не указан
+	IDColumn    string           // Первичный ключ; добавляется в конец сортировки для стабильного порядка
+	// Вычисляемые поля ответа и колонки модели, из которых они считаются
+	Computed map[string][]string
+	// Размер страницы, если limit не указан; 0 - без ограничения (в режиме курсора - DefaultLimit)
+	DefaultLimit int
+}
+
+// WithDefaultSort возвращает копию схемы с другой сортировкой по умолчанию
+func (s Schema) WithDefaultSort(sort string) Schema {
+	s.DefaultSort = sort
+	return s
+}
+
+// SortField - одно поле сортировки
+type SortField struct {
+	Name   string
+	Column string
+	Type   FieldType
+	Desc   bool
+}
+
+// Condition - одно условие фильтра
+type Condition struct {
+	Name   string
+	Column string
+	Op     string
+	Values []interface{}
+}
+
+// Options - разобранные параметры списочного запроса
+type Options struct {
+	Sort       []SortField
+	Conditions []Condition
+	Fields     []string // Пусто - все поля
+	Page       int      // Номер страницы (с 1) для постраничной навигации
+	Limit      int      // Размер страницы; 0 - без ограничения
+	Cursor     *Cursor  // nil - постраничная навигация, иначе навигация по курсору
+	WithTotal  bool     // Считать общее количество в режиме курсора
+	idColumn   string
+	computed   map[string][]string
+}
+
+var filterKey = regexp.MustCompile(`^([a-z_]+)\[([a-z]+)\]$`)
+
+// Parse разбирает параметры запроса по схеме. Обычные параметры без оператора
+// (status=, project_id= и т.п.) не трогает - их обрабатывают сами эндпоинты.
+func Parse(schema Schema, values url.Values) (*Options, error) {
+	options := &Options{idColumn: schema.IDColumn, computed: schema.Computed}
+
+	sortValue := values.Get("sort")
+	if sortValue == "" {
+		sortValue = schema.DefaultSort
+	}
+	sort, err := parseSort(schema, sortValue)
+	if err != nil {
+		return nil, err
+	}
+	options.Sort = sort
+
+	if err := options.parsePagination(schema, values); err != nil {
+		return nil, err
+	}
+
+	// Ключи перебираем в отсортированном порядке, чтобы SQL был детерминированным
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		name, op := match[1], match[2]
+		field, ok := schema.Fields[name]
+		if !ok {
+			return nil, fmt.Errorf("фильтрация по полю %s не поддерживается", name)
+		}
+		for _, raw := range values[key] {
+			condition, err := parseCondition(name, field, op, raw)
+			if err != nil {
+				return nil, err
+			}
+			options.Conditions = append(options.Conditions, condition)
+		}
+	}
+
+	if value := values.Get("fields"); value != "" {
+		// id возвращается всегда, чтобы клиент мог сопоставить записи
+		options.Fields = []string{"id"}
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || slices.Contains(options.Fields, name) {
+				continue
+			}
+			if !slices.Contains(schema.Selectable, name) {
+				return nil, fmt.Errorf("неизвестное поле %s в параметре fields", name)
+			}
+			options.Fields = append(options.Fields, name)
+		}
+	}
+
+	return options, nil
+}
+
+// parseSort разбирает значение sort=-created_at,name
+func parseSort(schema Schema, value string) ([]SortField, error) {
+	var sort []SortField
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+		field, ok := schema.Fields[name]
+		if !ok {
+			return nil, fmt.Errorf("сортировка по полю %s не поддерживается", name)
+		}
+		if slices.ContainsFunc(sort, func(s SortField) bool { return s.Name == name }) {
+			continue
+		}
+		sort = append(sort, SortField{Name: name, Column: field.Column, Type: field.Type, Desc: desc})
+	}
+	if len(sort) > maxSortParts {
+		return nil, fmt.Errorf("сортировка возможна не более чем по %d полям", maxSortParts)
+	}
+	return sort, nil
+}
+
+// parseCondition разбирает одно условие name[op]=raw
+func parseCondition(name string, field Field, op, raw string) (Condition, error) {
+	condition := Condition{Name: name, Column: field.Column, Op: op}
+
+	switch op {
+	case OpEq, OpNe:
+	case OpGt, OpGte, OpLt, OpLte:
+		if field.Type == Bool {
+			return condition, fmt.Errorf("оператор %s не применим к полю %s", op, name)
+		}
+	case OpLike, OpIlike:
+		if field.Type != String {
+			return condition, fmt.Errorf("оператор %s применим только к текстовым полям", op)
+		}
+		condition.Values = []interface{}{"%" + escapeLike(raw) + "%"}
+		return condition, nil
+	case OpNull:
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return condition, fmt.Errorf("значение %s[null] должно быть true или false", name)
+		}
+		condition.Values = []interface{}{isNull}
+		return condition, nil
+	case OpIn, OpNin:
+		parts := strings.Split(raw, ",")
+		if len(parts) > maxInValues {
+			return condition, fmt.Errorf("в фильтре %s[%s] не более %d значений", name, op, maxInValues)
+		}
+		for _, part := range parts {
+			value, err := parseValue(field.Type, strings.TrimSpace(part))
+			if err != nil {
+				return condition, fmt.Errorf("неверное значение фильтра %s: %v", name, err)
+			}
+			condition.Values = append(condition.Values, value)
+		}
+		return condition, nil
+	default:
+		return condition, fmt.Errorf("неизвестный оператор фильтра: %s", op)
+	}
+
+	value, err := parseValue(field.Type, raw)
+	if err != nil {
+		return condition, fmt.Errorf("неверное значение фильтра %s: %v", name, err)
+	}
+	condition.Values = []interface{}{value}
+	return condition, nil
+}
+
+// parseValue приводит строковое значение фильтра к типу поля
+func parseValue(fieldType FieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case Number:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("ожидается целое число")
+		}
+		return value, nil
+	case Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("ожидается true или false")
+		}
+		return value, nil
+	case Time:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		if t, err := time.ParseInLocation("2006-01-02", raw, time.Local); err == nil {
+			return t, nil
+		}
+		return nil, errors.New("ожидается дата ГГГГ-ММ-ДД или RFC 3339")
+	default:
+		return raw, nil
+	}
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE, чтобы искалась буквальная подстрока
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// Where добавляет к запросу условия фильтров
+func (o *Options) Where(db *gorm.DB) *gorm.DB {
+	if o == nil {
+		return db
+	}
+	for _, condition := range o.Conditions {
+		column := condition.Column
+		switch condition.Op {
+		case OpEq:
+			db = db.Where(column+" = ?", condition.Values[0])
+		case OpNe:
+			db = db.Where(column+" <> ?", condition.Values[0])
+		case OpGt:
+			db = db.Where(column+" > ?", condition.Values[0])
+		case OpGte:
+			db = db.Where(column+" >= ?", condition.Values[0])
+		case OpLt:
+			db = db.Where(column+" < ?", condition.Values[0])
+		case OpLte:
+			db = db.Where(column+" <= ?", condition.Values[0])
+		case OpIn:
+			db = db.Where(column+" IN ?", condition.Values)
+		case OpNin:
+			db = db.Where(column+" NOT IN ?", condition.Values)
+		case OpLike:
+			db = db.Where(column+" LIKE ?", condition.Values[0])
+		case OpIlike:
+			db = db.Where(column+" ILIKE ?", condition.Values[0])
+		case OpNull:
+			if condition.Values[0].(bool) {
+				db = db.Where(column + " IS NULL")
+			} else {
+				db = db.Where(column + " IS NOT NULL")
+			}
+		}
+	}
+	return db
+}
+
+// Order добавляет к запросу сортировку; последним ключом всегда идет первичный ключ
+func (o *Options) Order(db *gorm.DB) *gorm.DB {
+	if o == nil {
+		return db
+	}
+	return o.order(db, false)
+}
+
+// order добавляет сортировку; reverse - в обратном порядке (для перехода к предыдущей странице)
+func (o *Options) order(db *gorm.DB, reverse bool) *gorm.DB {
+	keys := o.sortKeys()
+	if len(keys) == 0 {
+		return db
+	}
+	columns := make([]clause.OrderByColumn, 0, len(keys))
+	for _, key := range keys {
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Name: key.Column, Raw: true},
+			Desc:   key.Desc != reverse,
+		})
+	}
+	return db.Clauses(clause.OrderBy{Columns: columns})
+}
+
+// sortKeys возвращает поля сортировки, дополненные первичным ключом
+func (o *Options) sortKeys() []SortField {
+	keys := make([]SortField, 0, len(o.Sort)+1)
+	hasID := false
+	for _, sort := range o.Sort {
+		keys = append(keys, sort)
+		hasID = hasID || sort.Column == o.idColumn
+	}
+	if o.idColumn != "" && !hasID {
+		keys = append(keys, SortField{Name: "id", Column: o.idColumn, Type: Number})
+	}
+	return keys
+}
+
+// selectColumns ограничивает запрос колонками полей из fields. Кроме самих полей
+// загружаются первичный ключ, ключи сортировки (по ним строится курсор), внешние ключи
+// запрошенных связей и колонки, из которых считаются запрошенные вычисляемые поля.
+func (o *Options) selectColumns(db *gorm.DB, dest interface{}) (*gorm.DB, error) {
+	if len(o.Fields) == 0 {
+		return db, nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return nil, err
+	}
+	model := stmt.Schema
+
+	var columns []string
+	addField := func(field *schema.Field) {
+		if field == nil || field.DBName == "" || field.Schema != model {
+			return
+		}
+		column := model.Table + "." + field.DBName
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	for _, field := range model.PrimaryFields {
+		addField(field)
+	}
+	for _, key := range o.sortKeys() {
+		addField(model.LookUpField(key.Column[strings.LastIndex(key.Column, ".")+1:]))
+	}
+	for _, name := range o.Fields {
+		if field := model.LookUpField(name); field != nil {
+			addField(field)
+			continue
+		}
+		for _, column := range o.computed[name] {
+			addField(model.LookUpField(column))
+		}
+		for _, relation := range model.Relationships.Relations {
+			if jsonName(relation.Field) != name {
+				continue
+			}
+			for _, reference := range relation.References {
+				addField(reference.ForeignKey)
+				addField(reference.PrimaryKey)
+			}
+		}
+	}
+
+	return db.Select(columns), nil
+}
+
+// jsonName возвращает ключ поля модели в JSON-ответе
+func jsonName(field *schema.Field) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// Pick оставляет в каждом элементе списка только поля из fields: колонки выбираются
+// в Find, а Pick убирает из ответа связи, загруженные Preload без запроса клиента,
+// и незагруженные колонки с нулевыми значениями.
+// Если выборка не задана, список возвращается без изменений.
+func (o *Options) Pick(items interface{}) (interface{}, error) {
+	if o == nil || len(o.Fields) == 0 {
+		return items, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	picked := make([]map[string]json.RawMessage, len(rows))
+	for i, row := range rows {
+		item := make(map[string]json.RawMessage, len(o.Fields))
+		for _, name := range o.Fields {
+			if value, ok := row[name]; ok {
+				item[name] = value
+			}
+		}
+		picked[i] = item
+	}
+	return picked, nil
+}
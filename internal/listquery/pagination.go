@@ -0,0 +1,384 @@
+package listquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Направления перехода по курсору
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// Cursor - позиция в списке: значения ключей сортировки записи, от которой идет переход.
+// Клиенту передается в непрозрачном виде (base64 от JSON).
+type Cursor struct {
+	Sort      string        `json:"s"` // Сортировка, для которой выдан курсор
+	Direction string        `json:"d"`
+	Values    []interface{} `json:"v"` // Пусто - первая страница
+}
+
+// PageInfo - сведения о полученной странице
+type PageInfo struct {
+	Page       int
+	Limit      int
+	Total      *int64 // nil - не считался
+	NextCursor string
+	PrevCursor string
+	cursorMode bool
+}
+
+// parsePagination читает page, limit, cursor и with_total.
+// Наличие параметра cursor (в том числе пустого) включает навигацию по курсору.
+func (o *Options) parsePagination(schema Schema, values url.Values) error {
+	o.Page, _ = strconv.Atoi(values.Get("page"))
+	if o.Page < 1 {
+		o.Page = 1
+	}
+
+	o.Limit = schema.DefaultLimit
+	if value := values.Get("limit"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit >= 1 && limit <= MaxLimit {
+			o.Limit = limit
+		}
+	}
+
+	if _, ok := values["cursor"]; !ok {
+		return nil
+	}
+	if o.Limit == 0 {
+		o.Limit = DefaultLimit
+	}
+	o.WithTotal = values.Get("with_total") == "true"
+	o.Cursor = &Cursor{Sort: o.sortSignature(), Direction: cursorNext}
+
+	value := values.Get("cursor")
+	if value == "" {
+		return nil
+	}
+	cursor, err := o.decodeCursor(value)
+	if err != nil {
+		return err
+	}
+	o.Cursor = cursor
+	return nil
+}
+
+// sortSignature - строковое представление сортировки, например "-created_at,name"
+func (o *Options) sortSignature() string {
+	parts := make([]string, 0, len(o.Sort))
+	for _, sort := range o.Sort {
+		if sort.Desc {
+			parts = append(parts, "-"+sort.Name)
+		} else {
+			parts = append(parts, sort.Name)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeCursor разбирает курсор и приводит значения к типам полей сортировки
+func (o *Options) decodeCursor(value string) (*Cursor, error) {
+	invalid := errors.New("неверный курсор")
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor Cursor
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, invalid
+	}
+	if cursor.Sort != o.sortSignature() {
+		return nil, errors.New("курсор выдан для другой сортировки")
+	}
+	if cursor.Direction != cursorNext && cursor.Direction != cursorPrev {
+		return nil, invalid
+	}
+
+	keys := o.sortKeys()
+	if len(cursor.Values) != len(keys) {
+		return nil, invalid
+	}
+	for i, key := range keys {
+		if cursor.Values[i] == nil {
+			continue
+		}
+		raw, ok := cursor.Values[i].(string)
+		if number, isNumber := cursor.Values[i].(json.Number); isNumber {
+			raw, ok = number.String(), true
+		}
+		if flag, isBool := cursor.Values[i].(bool); isBool {
+			raw, ok = strconv.FormatBool(flag), true
+		}
+		if !ok {
+			return nil, invalid
+		}
+
+		var value interface{}
+		if key.Type == Time {
+			value, err = time.Parse(time.RFC3339Nano, raw)
+		} else {
+			value, err = parseValue(key.Type, raw)
+		}
+		if err != nil {
+			return nil, invalid
+		}
+		cursor.Values[i] = value
+	}
+	return &cursor, nil
+}
+
+// encodeCursor кодирует курсор для передачи клиенту
+func encodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Find применяет к запросу фильтры, сортировку и пагинацию и загружает записи в dest
+// (указатель на срез моделей).
+//
+// В постраничном режиме считается общее количество и используется OFFSET.
+// В режиме курсора выбираются записи строго после (или до) курсора по ключам
+// сортировки и первичному ключу, поэтому вставки и удаления между запросами
+// не приводят к пропускам и повторам; общее количество считается только по with_total.
+//
+// Если задан fields, из БД читаются только колонки выбранных полей (см. selectColumns).
+func (o *Options) Find(db *gorm.DB, dest interface{}) (*PageInfo, error) {
+	if o == nil {
+		return &PageInfo{Page: 1}, db.Find(dest).Error
+	}
+
+	query := o.Where(db)
+	info := &PageInfo{Page: o.Page, Limit: o.Limit, cursorMode: o.Cursor != nil}
+
+	if o.Cursor == nil || o.WithTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		info.Total = &total
+	}
+
+	// Колонки выбираются после подсчета: COUNT строится по всей записи
+	query, err := o.selectColumns(query, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Cursor == nil {
+		paged := o.order(query, false)
+		if o.Limit > 0 {
+			paged = paged.Offset((o.Page - 1) * o.Limit).Limit(o.Limit)
+		}
+		return info, paged.Find(dest).Error
+	}
+
+	backward := o.Cursor.Direction == cursorPrev
+	if len(o.Cursor.Values) > 0 {
+		condition, vars := o.keysetCondition(o.Cursor.Values, backward)
+		query = query.Where(condition, vars...)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	result := o.order(query, backward).Limit(o.Limit + 1).Find(dest)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	items := reflect.ValueOf(dest).Elem()
+	hasMore := items.Len() > o.Limit
+	if hasMore {
+		items.Set(items.Slice(0, o.Limit))
+	}
+	if backward {
+		swap := reflect.Swapper(items.Interface())
+		for i, j := 0, items.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	if items.Len() == 0 {
+		return info, nil
+	}
+
+	cursorAt := func(index int, direction string) (string, error) {
+		values, err := o.keyValues(result, items.Index(index))
+		if err != nil {
+			return "", err
+		}
+		return encodeCursor(Cursor{Sort: o.Cursor.Sort, Direction: direction, Values: values}), nil
+	}
+
+	if hasMore || backward {
+		if info.NextCursor, err = cursorAt(items.Len()-1, cursorNext); err != nil {
+			return nil, err
+		}
+	}
+	if (hasMore && backward) || (!backward && len(o.Cursor.Values) > 0) {
+		if info.PrevCursor, err = cursorAt(0, cursorPrev); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// Each загружает в dest все записи, подходящие под фильтры, пачками по size в порядке
+// сортировки списка и вызывает fn после каждой пачки. Пачки выбираются по курсору,
+// поэтому порядок совпадает со списком, а в памяти держится не больше size записей.
+func (o *Options) Each(db *gorm.DB, dest interface{}, size int, fn func() error) error {
+	if o == nil {
+		return errors.New("не заданы параметры списка")
+	}
+	batch := &Options{Sort: o.Sort, Conditions: o.Conditions, Limit: size, idColumn: o.idColumn}
+	batch.Cursor = &Cursor{Sort: batch.sortSignature(), Direction: cursorNext}
+
+	for {
+		info, err := batch.Find(db, dest)
+		if err != nil {
+			return err
+		}
+		if reflect.ValueOf(dest).Elem().Len() == 0 {
+			return nil
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+		if info.NextCursor == "" {
+			return nil
+		}
+		if batch.Cursor, err = batch.decodeCursor(info.NextCursor); err != nil {
+			return err
+		}
+	}
+}
+
+// keysetCondition строит условие "запись идет после values в порядке сортировки".
+// Для ключей (k1, k2, ...) это k1 > v1 OR (k1 = v1 AND k2 > v2) OR ...
+// с учетом направления каждого ключа и того, что PostgreSQL ставит NULL
+// в конец при ASC и в начало при DESC.
+func (o *Options) keysetCondition(values []interface{}, backward bool) (string, []interface{}) {
+	var disjuncts []string
+	var vars []interface{}
+
+	var equal []string
+	var equalVars []interface{}
+	for i, key := range o.sortKeys() {
+		desc := key.Desc != backward
+		value := values[i]
+
+		var after string
+		var afterVars []interface{}
+		switch {
+		case value == nil && desc:
+			after = key.Column + " IS NOT NULL"
+		case value == nil:
+			// При ASC после NULL ничего нет
+		case desc:
+			after, afterVars = key.Column+" < ?", []interface{}{value}
+		default:
+			after, afterVars = "("+key.Column+" > ? OR "+key.Column+" IS NULL)", []interface{}{value}
+		}
+
+		if after != "" {
+			parts := append(append([]string{}, equal...), after)
+			disjuncts = append(disjuncts, "("+strings.Join(parts, " AND ")+")")
+			vars = append(vars, equalVars...)
+			vars = append(vars, afterVars...)
+		}
+
+		if value == nil {
+			equal = append(equal, key.Column+" IS NULL")
+		} else {
+			equal = append(equal, key.Column+" = ?")
+			equalVars = append(equalVars, value)
+		}
+	}
+
+	if len(disjuncts) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", vars
+}
+
+// keyValues извлекает из загруженной записи значения ключей сортировки
+func (o *Options) keyValues(result *gorm.DB, item reflect.Value) ([]interface{}, error) {
+	schema := result.Statement.Schema
+	keys := o.sortKeys()
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		column := key.Column[strings.LastIndex(key.Column, ".")+1:]
+		field := schema.LookUpField(column)
+		if field == nil {
+			return nil, errors.New("поле сортировки " + key.Name + " отсутствует в модели")
+		}
+
+		value, _ := field.ValueOf(context.Background(), reflect.Indirect(item))
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				value = nil
+			} else {
+				value = v.Elem().Interface()
+			}
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339Nano)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Response возвращает блок pagination для ответа API.
+// В постраничном режиме формат прежний: current_page, total_pages, total_items, limit.
+func (p *PageInfo) Response() map[string]interface{} {
+	if !p.cursorMode {
+		total := int64(0)
+		if p.Total != nil {
+			total = *p.Total
+		}
+		totalPages := 0
+		if p.Limit > 0 {
+			totalPages = int((total + int64(p.Limit) - 1) / int64(p.Limit))
+		} else if total > 0 {
+			totalPages = 1
+		}
+		return map[string]interface{}{
+			"current_page": p.Page,
+			"total_pages":  totalPages,
+			"total_items":  total,
+			"limit":        p.Limit,
+		}
+	}
+
+	response := map[string]interface{}{
+		"limit":       p.Limit,
+		"next_cursor": nullable(p.NextCursor),
+		"prev_cursor": nullable(p.PrevCursor),
+		"has_more":    p.NextCursor != "",
+	}
+	if p.Total != nil {
+		response["total_items"] = *p.Total
+	}
+	return response
+}
+
+// nullable превращает пустую строку в null в JSON
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,189 @@
+package listquery
+
+import (
+	"encoding/base64"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type eachItem struct {
+	ID   uint
+	Name string
+}
+
+func TestEachReadsAllBatchesInListOrder(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "each.db")),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&eachItem{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"в", "а", "д", "б", "г", "е", "а"} {
+		db.Create(&eachItem{Name: name})
+	}
+
+	schema := Schema{
+		Fields: map[string]Field{
+			"name": {Column: "each_items.name", Type: String},
+		},
+		IDColumn:     "each_items.id",
+		DefaultLimit: 2,
+	}
+	options, err := Parse(schema, url.Values{"sort": {"-name"}, "name[ne]": {"е"}, "limit": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var items []eachItem
+	var names []string
+	batches := 0
+	err = options.Each(db.Model(&eachItem{}), &items, 2, func() error {
+		batches++
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Параметр limit списка на выгрузку не влияет
+	if got := strings.Join(names, ""); got != "дгвбаа" {
+		t.Fatalf("неверный порядок или состав записей: %s", got)
+	}
+	if batches != 3 {
+		t.Fatalf("ожидалось 3 пачки, получено %d", batches)
+	}
+}
+
+// cursorSchema - схема для проверок курсора: дата закрытия может быть NULL
+var cursorSchema = Schema{
+	Fields: map[string]Field{
+		"name":      {Column: "items.name", Type: String},
+		"closed_at": {Column: "items.closed_at", Type: Time},
+		"done":      {Column: "items.done", Type: Bool},
+	},
+	IDColumn: "items.id",
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	options, err := Parse(cursorSchema, url.Values{"sort": {"-closed_at,name,done"}, "cursor": {""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAt := time.Date(2026, 3, 1, 10, 30, 0, 123000000, time.UTC)
+
+	tests := []struct {
+		name   string
+		values []interface{}
+		want   []interface{}
+	}{
+		{"значения", []interface{}{closedAt.Format(time.RFC3339Nano), "а", true, 42}, []interface{}{closedAt, "а", true, int64(42)}},
+		{"NULL в ключе", []interface{}{nil, "б", false, 7}, []interface{}{nil, "б", false, int64(7)}},
+	}
+	for _, tt := range tests {
+		encoded := encodeCursor(Cursor{Sort: "-closed_at,name,done", Direction: cursorPrev, Values: tt.values})
+		cursor, err := options.decodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if cursor.Direction != cursorPrev || !reflect.DeepEqual(cursor.Values, tt.want) {
+			t.Errorf("%s: курсор разобран как %s %#v, ожидалось %#v", tt.name, cursor.Direction, cursor.Values, tt.want)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsInvalid(t *testing.T) {
+	options, err := Parse(cursorSchema, url.Values{"sort": {"name"}, "cursor": {""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"не base64", "!!!"},
+		{"не JSON", base64.RawURLEncoding.EncodeToString([]byte("курсор"))},
+		{"другая сортировка", encodeCursor(Cursor{Sort: "-name", Direction: cursorNext, Values: []interface{}{"а", 1}})},
+		{"неизвестное направление", encodeCursor(Cursor{Sort: "name", Direction: "up", Values: []interface{}{"а", 1}})},
+		{"не хватает ключей", encodeCursor(Cursor{Sort: "name", Direction: cursorNext, Values: []interface{}{"а"}})},
+		{"неверный тип ключа", encodeCursor(Cursor{Sort: "name", Direction: cursorNext, Values: []interface{}{"а", "один"}})},
+	}
+	for _, tt := range tests {
+		if _, err := options.decodeCursor(tt.value); err == nil {
+			t.Errorf("%s: курсор принят", tt.name)
+		}
+	}
+}
+
+func TestKeysetConditionWithNulls(t *testing.T) {
+	closedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		sort     string
+		values   []interface{}
+		backward bool
+		want     string
+		wantVars []interface{}
+	}{
+		{
+			name:     "ASC: после значения идут большие и NULL",
+			sort:     "closed_at",
+			values:   []interface{}{closedAt, int64(5)},
+			want:     "(((items.closed_at > ? OR items.closed_at IS NULL)) OR (items.closed_at = ? AND (items.id > ? OR items.id IS NULL)))",
+			wantVars: []interface{}{closedAt, closedAt, int64(5)},
+		},
+		{
+			name:     "ASC: после NULL только NULL с большим ID",
+			sort:     "closed_at",
+			values:   []interface{}{nil, int64(5)},
+			want:     "((items.closed_at IS NULL AND (items.id > ? OR items.id IS NULL)))",
+			wantVars: []interface{}{int64(5)},
+		},
+		{
+			name:     "DESC: после NULL идут все значения",
+			sort:     "-closed_at",
+			values:   []interface{}{nil, int64(5)},
+			want:     "((items.closed_at IS NOT NULL) OR (items.closed_at IS NULL AND (items.id > ? OR items.id IS NULL)))",
+			wantVars: []interface{}{int64(5)},
+		},
+		{
+			name:     "DESC: после значения только меньшие",
+			sort:     "-closed_at",
+			values:   []interface{}{closedAt, int64(5)},
+			want:     "((items.closed_at < ?) OR (items.closed_at = ? AND (items.id > ? OR items.id IS NULL)))",
+			wantVars: []interface{}{closedAt, closedAt, int64(5)},
+		},
+		{
+			name:     "назад по ASC: до NULL идут все значения",
+			sort:     "closed_at",
+			values:   []interface{}{nil, int64(5)},
+			backward: true,
+			want:     "((items.closed_at IS NOT NULL) OR (items.closed_at IS NULL AND items.id < ?))",
+			wantVars: []interface{}{int64(5)},
+		},
+	}
+	for _, tt := range tests {
+		options, err := Parse(cursorSchema, url.Values{"sort": {tt.sort}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		condition, vars := options.keysetCondition(tt.values, tt.backward)
+		if condition != tt.want || !reflect.DeepEqual(vars, tt.wantVars) {
+			t.Errorf("%s:\nусловие %s %v\nожидалось %s %v", tt.name, condition, vars, tt.want, tt.wantVars)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package logging настраивает структурированный журнал на log/slog.
+//
+// Уровень журнала хранится в общей переменной и меняется на работающем процессе
+// через SetLevel. Логгер запроса (с request_id и пользователем) передается
+// в сервисы через context: обработчики передают c.Request.Context(),
+// сервисы берут логгер из него функцией FromContext.
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level - текущий уровень журнала, общий для всех логгеров процесса
+var level = new(slog.LevelVar)
+
+// contextKey - ключ логгера в context
+type contextKey struct{}
+
+// Setup делает JSON- или текстовый логгер с уровнем levelName логгером по умолчанию
+func Setup(w io.Writer, levelName, format string) error {
+	if err := SetLevel(levelName); err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return errors.New("неизвестный формат журнала " + format)
+	}
+
+	// После этого и сообщения через log.Printf (например, от сторонних библиотек)
+	// попадают в журнал с уровнем info
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// ParseLevel разбирает название уровня: debug, info, warn или error
+func ParseLevel(name string) (slog.Level, error) {
+	var parsed slog.Level
+	switch strings.ToLower(name) {
+	case "debug":
+		parsed = slog.LevelDebug
+	case "info":
+		parsed = slog.LevelInfo
+	case "warn":
+		parsed = slog.LevelWarn
+	case "error":
+		parsed = slog.LevelError
+	default:
+		return 0, errors.New("неизвестный уровень журнала, допустимы debug, info, warn или error")
+	}
+	return parsed, nil
+}
+
+// SetLevel меняет уровень журнала для всего процесса
+func SetLevel(name string) error {
+	parsed, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// Level возвращает название текущего уровня журнала
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// WithLogger сохраняет логгер в context
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext возвращает логгер запроса, а вне запроса - логгер по умолчанию
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// Fatal пишет ошибку в журнал и завершает процесс
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
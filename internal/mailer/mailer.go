@@ -0,0 +1,128 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message - письмо с текстовой и HTML-версией
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender - способ отправки писем
+type Sender interface {
+	Send(msg Message) error
+}
+
+// SMTPSender отправляет письма через SMTP-сервер.
+// Для локальной проверки подходит любой фейковый SMTP (MailHog, Mailpit) без авторизации.
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewSMTPSender создает отправщика SMTP
+func NewSMTPSender(host, port, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+// Send отправляет письмо
+func (s *SMTPSender) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("не указан получатель письма")
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	body, err := buildMIME(s.From, msg)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, s.From, msg.To, body)
+}
+
+// LogSender пишет письма в журнал; используется, когда SMTP не настроен
+type LogSender struct{}
+
+// Send выводит письмо в журнал
+func (LogSender) Send(msg Message) error {
+	slog.Info("Письмо (SMTP не настроен)", "to", strings.Join(msg.To, ", "), "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
+
+// buildMIME собирает письмо multipart/alternative в кодировке UTF-8
+func buildMIME(from string, msg Message) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	writePart := func(contentType, content string) {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", contentType+"; charset=UTF-8")
+		writeHeader("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+
+		encoded := base64.StdEncoding.EncodeToString([]byte(content))
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		buf.WriteString(encoded + "\r\n")
+	}
+
+	writePart("text/plain", msg.Text)
+	if msg.HTML != "" {
+		writePart("text/html", msg.HTML)
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
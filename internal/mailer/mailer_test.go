@@ -0,0 +1,182 @@
+package mailer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// smtpSession - то, что фейковый SMTP-сервер получил от клиента
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP принимает одно письмо на случайном порту и возвращает адрес сервера
+// и канал с полученной сессией
+func fakeSMTP(t *testing.T) (host, port string, received <-chan smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		var session smtpSession
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				session.from = smtpPath(line)
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				session.to = append(session.to, smtpPath(line))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				session.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				sessions <- session
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(listener.Addr().String())
+	return host, port, sessions
+}
+
+// smtpPath извлекает адрес из команды MAIL FROM:<...> или RCPT TO:<...> с параметрами
+func smtpPath(command string) string {
+	start, end := strings.Index(command, "<"), strings.Index(command, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return command[start+1 : end]
+}
+
+func TestSMTPSenderDeliversRenderedMessage(t *testing.T) {
+	host, port, received := fakeSMTP(t)
+
+	text, html, err := Render("notification", map[string]interface{}{
+		"Name":        "Иван Петров",
+		"Title":       "Назначен дефект",
+		"Message":     "Трещина в несущей стене",
+		"ProjectName": "ЖК Северный",
+		"AppURL":      "https://control.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender := NewSMTPSender(host, port, "", "", "noreply@example.com")
+	subject := "Назначен дефект: трещина в стене"
+	if err := sender.Send(Message{To: []string{"ivan@example.com"}, Subject: subject, Text: text, HTML: html}); err != nil {
+		t.Fatalf("отправка письма: %v", err)
+	}
+	session := <-received
+
+	if session.from != "noreply@example.com" || len(session.to) != 1 || session.to[0] != "ivan@example.com" {
+		t.Fatalf("конверт письма: from=%q to=%v", session.from, session.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(session.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Тема на кириллице передается закодированной по RFC 2047
+	rawSubject := msg.Header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?UTF-8?b?") {
+		t.Errorf("тема не закодирована: %q", rawSubject)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil || decoded != subject {
+		t.Errorf("тема %q (%v), ожидалась %q", decoded, err, subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type %q: %v", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoding := part.Header.Get("Content-Transfer-Encoding"); encoding != "base64" {
+			t.Errorf("кодировка части %q", encoding)
+		}
+		content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = string(content)
+	}
+
+	if parts["text/plain"] != text {
+		t.Errorf("текстовая часть отличается от шаблона:\n%s", parts["text/plain"])
+	}
+	if parts["text/html"] != html {
+		t.Errorf("HTML-часть отличается от шаблона:\n%s", parts["text/html"])
+	}
+	if !strings.Contains(parts["text/plain"], "ЖК Северный") {
+		t.Errorf("в письме нет данных шаблона: %s", parts["text/plain"])
+	}
+}
+
+func TestSMTPSenderRequiresRecipient(t *testing.T) {
+	sender := NewSMTPSender("127.0.0.1", "25", "", "", "noreply@example.com")
+	if err := sender.Send(Message{Subject: "Тест"}); err == nil {
+		t.Error("письмо без получателя отправлено")
+	}
+}
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+)
+
+// Render формирует текстовую и HTML-версию письма по шаблону name
+// (templates/<name>.txt и templates/<name>.html)
+func Render(name string, data interface{}) (text string, html string, err error) {
+	var textBuf, htmlBuf bytes.Buffer
+
+	if err := textTemplates.ExecuteTemplate(&textBuf, name+".txt", data); err != nil {
+		return "", "", err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&htmlBuf, name+".html", data); err != nil {
+		return "", "", err
+	}
+
+	return textBuf.String(), htmlBuf.String(), nil
+}
@@ -0,0 +1,89 @@
+// Package mergepatch применяет JSON Merge Patch (RFC 7386) к структурам.
+//
+// В патче отсутствующее поле не меняется, null очищает поле, любое другое
+// значение заменяет текущее; вложенные объекты объединяются рекурсивно:
+//
+//	{"description": null, "end_date": "2025-12-31T00:00:00Z"}
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ContentType - тип содержимого запроса PATCH по RFC 7386
+const ContentType = "application/merge-patch+json"
+
+// Merge объединяет документ target с патчем patch по алгоритму RFC 7386
+func Merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = Merge(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// Apply применяет патч к структуре dst: dst сериализуется в JSON, объединяется с патчем
+// и разбирается обратно. Патч должен быть JSON-объектом, содержащим только поля dst.
+func Apply(dst interface{}, patch []byte) error {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return errors.New("неверный формат JSON Merge Patch")
+	}
+	if _, ok := patchDoc.(map[string]interface{}); !ok {
+		return errors.New("JSON Merge Patch должен быть объектом")
+	}
+
+	current, err := json.Marshal(dst)
+	if err != nil {
+		return err
+	}
+	var targetDoc interface{}
+	if err := json.Unmarshal(current, &targetDoc); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(Merge(targetDoc, patchDoc))
+	if err != nil {
+		return err
+	}
+
+	// Результат разбирается в пустое значение, чтобы удаленные поля стали нулевыми
+	value := reflect.ValueOf(dst).Elem()
+	result := reflect.New(value.Type())
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(result.Interface()); err != nil {
+		return decodeError(err)
+	}
+	value.Set(result.Elem())
+	return nil
+}
+
+// decodeError переводит ошибки разбора объединенного документа в понятные сообщения
+func decodeError(err error) error {
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return fmt.Errorf("%s: неверный тип значения", typeError.Field)
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Errorf("%s: поле нельзя изменить", strings.Trim(field, `"`))
+	}
+	return errors.New("неверное значение в JSON Merge Patch")
+}
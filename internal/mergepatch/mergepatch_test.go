@@ -0,0 +1,106 @@
+package mergepatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+type patchAddress struct {
+	City   string `json:"city,omitempty"`
+	Street string `json:"street,omitempty"`
+}
+
+type patchTarget struct {
+	Name        string        `json:"name"`
+	Description *string       `json:"description"`
+	Budget      *int          `json:"budget"`
+	Address     *patchAddress `json:"address"`
+}
+
+func TestApply(t *testing.T) {
+	text := func(value string) *string { return &value }
+	number := func(value int) *int { return &value }
+	current := func() patchTarget {
+		return patchTarget{
+			Name:        "ЖК Север",
+			Description: text("Первая очередь"),
+			Budget:      number(100),
+			Address:     &patchAddress{City: "Москва", Street: "Ленина"},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  patchTarget
+	}{
+		{
+			name:  "пустой патч ничего не меняет",
+			patch: `{}`,
+			want:  current(),
+		},
+		{
+			name:  "отсутствующие поля не меняются",
+			patch: `{"name": "ЖК Юг"}`,
+			want:  patchTarget{Name: "ЖК Юг", Description: text("Первая очередь"), Budget: number(100), Address: &patchAddress{City: "Москва", Street: "Ленина"}},
+		},
+		{
+			name:  "null очищает поле",
+			patch: `{"description": null, "budget": null}`,
+			want:  patchTarget{Name: "ЖК Север", Address: &patchAddress{City: "Москва", Street: "Ленина"}},
+		},
+		{
+			name:  "пустая строка - значение, а не очистка",
+			patch: `{"description": ""}`,
+			want:  patchTarget{Name: "ЖК Север", Description: text(""), Budget: number(100), Address: &patchAddress{City: "Москва", Street: "Ленина"}},
+		},
+		{
+			name:  "вложенный объект объединяется",
+			patch: `{"address": {"street": "Мира"}}`,
+			want:  patchTarget{Name: "ЖК Север", Description: text("Первая очередь"), Budget: number(100), Address: &patchAddress{City: "Москва", Street: "Мира"}},
+		},
+		{
+			name:  "null во вложенном объекте очищает только его поле",
+			patch: `{"address": {"city": null}}`,
+			want:  patchTarget{Name: "ЖК Север", Description: text("Первая очередь"), Budget: number(100), Address: &patchAddress{Street: "Ленина"}},
+		},
+		{
+			name:  "null очищает вложенный объект целиком",
+			patch: `{"address": null}`,
+			want:  patchTarget{Name: "ЖК Север", Description: text("Первая очередь"), Budget: number(100)},
+		},
+	}
+	for _, tt := range tests {
+		target := current()
+		if err := Apply(&target, []byte(tt.patch)); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(target, tt.want) {
+			t.Errorf("%s: получено %+v, ожидалось %+v", tt.name, target, tt.want)
+		}
+	}
+}
+
+func TestApplyRejectsInvalidPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"не JSON", `{"name":`, "неверный формат JSON Merge Patch"},
+		{"не объект", `["name"]`, "JSON Merge Patch должен быть объектом"},
+		{"неизвестное поле", `{"status": "closed"}`, "status: поле нельзя изменить"},
+		{"неверный тип", `{"budget": "много"}`, "budget: неверный тип значения"},
+	}
+	for _, tt := range tests {
+		target := patchTarget{Name: "ЖК Север"}
+		err := Apply(&target, []byte(tt.patch))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: ошибка %v, ожидалась %q", tt.name, err, tt.want)
+		}
+		if target.Name != "ЖК Север" {
+			t.Errorf("%s: структура изменена при ошибке", tt.name)
+		}
+	}
+}
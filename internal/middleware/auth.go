@@ -4,13 +4,14 @@ import (
 	"net/http"
 	"strings"
 
+	"SystemContorlBackend/internal/logging"
 	"SystemContorlBackend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthMiddleware проверяет JWT токен
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(tokens *services.TokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -28,7 +29,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Валидируем токен
-		claims, err := services.ValidateToken(parts[1])
+		claims, err := tokens.Validate(parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный или истекший токен"})
 			c.Abort()
@@ -39,6 +40,11 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role_code", claims.RoleCode)
+
+		// Записи сервисов в журнал по этому запросу содержат пользователя
+		ctx := c.Request.Context()
+		logger := logging.FromContext(ctx).With("user_id", claims.UserID, "role", claims.RoleCode)
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, logger))
 		c.Next()
 	}
 }
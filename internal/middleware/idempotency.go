@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Тело запроса до этого размера держится в памяти, больше - во временном файле
+const idempotencyMemoryLimit = 1 << 20
+
+// IdempotencyMiddleware выполняет изменяющий запрос с заголовком Idempotency-Key только один раз.
+// Повтор с тем же ключом и тем же телом получает сохраненный ответ (заголовок Idempotent-Replayed),
+// повтор во время выполнения - 409, тот же ключ с другим запросом - 422.
+// Ответы 5xx не сохраняются, чтобы запрос можно было повторить.
+func IdempotencyMiddleware(keys *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead ||
+			c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key длиннее 255 символов"})
+			c.Abort()
+			return
+		}
+
+		requestHash, cleanup, err := fingerprintRequest(c.Request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать тело запроса"})
+			c.Abort()
+			return
+		}
+		defer cleanup()
+
+		var userID uint
+		if value, exists := c.Get("user_id"); exists {
+			userID = value.(uint)
+		}
+
+		record, err := keys.Begin(userID, key, c.Request.Method, c.Request.URL.Path, requestHash)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrIdempotencyKeyReused):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrIdempotencyInProgress):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+
+		// Запрос уже выполнен - возвращаем сохраненный ответ
+		if record.StatusCode != 0 {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		completed := false
+		defer func() {
+			// Паника или ошибка сервера освобождают ключ для повтора
+			if !completed {
+				keys.Release(record.ID)
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		if err := keys.Complete(record.ID, status, writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+			return
+		}
+		completed = true
+	}
+}
+
+// recordingWriter копирует тело ответа, чтобы сохранить его для повторов
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// fingerprintRequest считает SHA-256 метода, пути и тела запроса и подменяет тело копией,
+// чтобы обработчик мог прочитать его заново. Для multipart учитываются имена полей, файлов
+// и их содержимое, но не граница частей: клиенты генерируют ее заново при каждом повторе.
+func fingerprintRequest(r *http.Request) (string, func(), error) {
+	body, cleanup, err := spoolBody(r.Body)
+	if err != nil {
+		return "", func() {}, err
+	}
+	r.Body = io.NopCloser(body)
+
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				cleanup()
+				return "", func() {}, err
+			}
+			io.WriteString(hash, part.FormName()+"\x00"+part.FileName()+"\x00")
+			if _, err := io.Copy(hash, part); err != nil {
+				cleanup()
+				return "", func() {}, err
+			}
+		}
+	} else if _, err := io.Copy(hash, body); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), cleanup, nil
+}
+
+// spoolBody копирует тело запроса в память или, если оно большое, во временный файл
+func spoolBody(body io.Reader) (io.ReadSeeker, func(), error) {
+	var buffer bytes.Buffer
+	n, err := io.CopyN(&buffer, body, idempotencyMemoryLimit+1)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if n <= idempotencyMemoryLimit {
+		return bytes.NewReader(buffer.Bytes()), func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	if _, err := io.Copy(file, io.MultiReader(&buffer, body)); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return file, cleanup, nil
+}
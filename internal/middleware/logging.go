@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"SystemContorlBackend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader - заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину идентификатора, пришедшего от клиента
+const maxRequestIDLength = 128
+
+// RequestID берет X-Request-ID из запроса или создает новый, возвращает его в ответе
+// и кладет в context логгер с полем request_id
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		logger := logging.FromContext(ctx).With("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, logger))
+		c.Next()
+	}
+}
+
+// validRequestID принимает идентификатор клиента, только если он безопасен для журнала
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID создает случайный идентификатор запроса
+func newRequestID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// AccessLog пишет в журнал строку о каждом запросе: метод, маршрут, код ответа,
+// время обработки и пользователя. Ответы 5xx пишутся с уровнем error, 4xx - warn,
+// проверки /healthz и /readyz - debug.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		logger := logging.FromContext(c.Request.Context())
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "(не найден)"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID, exists := c.Get("user_id"); exists {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		if roleCode, exists := c.Get("role_code"); exists {
+			attrs = append(attrs, slog.Any("role", roleCode))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case route == "/healthz" || route == "/readyz":
+			level = slog.LevelDebug
+		}
+		logger.LogAttrs(c.Request.Context(), level, "HTTP-запрос", attrs...)
+	}
+}
+
+// Recovery отвечает 500 на панику в обработчике и пишет ее в журнал со стеком вызовов
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error("Паника при обработке запроса",
+			"panic", fmt.Sprint(recovered),
+			"stack", string(debug.Stack()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+	})
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// DefectAssignment - запись истории назначений дефекта
+type DefectAssignment struct {
+	ID             uint          `gorm:"primarykey" json:"id"`
+	DefectID       uint          `gorm:"not null;index" json:"defect_id"`
+	AssigneeUserID *uint         `json:"assignee_user_id"`
+	AssigneeUser   *User         `gorm:"foreignKey:AssigneeUserID" json:"assignee_user,omitempty"`
+	AssigneeOrgID  *uint         `json:"assignee_org_id"`
+	AssigneeOrg    *Organization `gorm:"foreignKey:AssigneeOrgID" json:"assignee_org,omitempty"`
+	PreviousUserID *uint         `json:"previous_user_id"`
+	PreviousOrgID  *uint         `json:"previous_org_id"`
+	Comment        string        `gorm:"type:text" json:"comment"`
+	AssignedBy     uint          `json:"assigned_by"`
+	Assigner       User          `gorm:"foreignKey:AssignedBy" json:"assigner,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// DefectAssign - структура для назначения исполнителя дефекта
+type DefectAssign struct {
+	UserID         *uint  `json:"user_id"`
+	OrganizationID *uint  `json:"organization_id"`
+	Comment        string `json:"comment"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Holiday - нерабочий праздничный день производственного календаря
+type Holiday struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex" json:"date"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HolidayInput - структура для добавления праздничного дня
+type HolidayInput struct {
+	Date string `json:"date" binding:"required,datetime=2006-01-02"`
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// Константы состояний SLA дефекта
+const (
+	SLAStateOnTrack  = "on_track" // В срок
+	SLAStateAtRisk   = "at_risk"  // Срок подходит к концу
+	SLAStateBreached = "breached" // Срок нарушен
+)
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefectCategory - категория дефекта (бетонные работы, электрика, отделка...)
+type DefectCategory struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"size:255;not null;unique" json:"name"`
+	Code        string         `gorm:"size:50;not null;unique" json:"code"`
+	Description string         `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// SeverityLevel - уровень критичности дефекта со сроком устранения по умолчанию
+type SeverityLevel struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"size:100;not null;unique" json:"name"`
+	Code        string         `gorm:"size:50;not null;unique" json:"code"`
+	Rank        int            `gorm:"not null;default:0" json:"rank"`     // Чем больше, тем критичнее
+	FixDays     int            `gorm:"not null;default:0" json:"fix_days"` // Срок устранения по умолчанию (дней)
+	Description string         `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// NormReference - ссылка на пункт нормативного документа (СП, ГОСТ, СНиП)
+type NormReference struct {
+	ID           uint           `gorm:"primarykey" json:"id"`
+	DocumentType string         `gorm:"size:20;not null" json:"document_type"` // "SP", "GOST", "SNIP"
+	Document     string         `gorm:"size:100;not null" json:"document"`     // Например: "СП 70.13330.2012"
+	Clause       string         `gorm:"size:50" json:"clause"`                 // Номер пункта: "5.18.3"
+	Title        string         `gorm:"size:500;not null" json:"title"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Константы типов нормативных документов
+const (
+	NormDocumentSP   = "SP"   // Свод правил
+	NormDocumentGOST = "GOST" // ГОСТ
+	NormDocumentSNIP = "SNIP" // СНиП
+)
+
+// Коды уровней критичности по умолчанию
+const (
+	SeverityLow      = "low"      // Незначительный
+	SeverityMedium   = "medium"   // Значительный
+	SeverityHigh     = "high"     // Существенный
+	SeverityCritical = "critical" // Критический
+)
+
+// DefectCategoryInput - структура для создания/обновления категории
+type DefectCategoryInput struct {
+	Name        string `json:"name" binding:"required,min=2,max=255"`
+	Code        string `json:"code" binding:"required,max=50"`
+	Description string `json:"description"`
+}
+
+// SeverityLevelInput - структура для создания/обновления уровня критичности
+type SeverityLevelInput struct {
+	Name        string `json:"name" binding:"required,min=2,max=100"`
+	Code        string `json:"code" binding:"required,max=50"`
+	Rank        int    `json:"rank" binding:"min=0"`
+	FixDays     int    `json:"fix_days" binding:"min=0"`
+	Description string `json:"description"`
+}
+
+// NormReferenceInput - структура для создания/обновления нормативной ссылки
+type NormReferenceInput struct {
+	DocumentType string `json:"document_type" binding:"required,oneof=SP GOST SNIP"`
+	Document     string `json:"document" binding:"required,max=100"`
+	Clause       string `json:"clause" binding:"max=50"`
+	Title        string `json:"title" binding:"required,max=500"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment - комментарий к дефекту
+type Comment struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	DefectID  uint           `gorm:"not null;index" json:"defect_id"`
+	AuthorID  uint           `gorm:"not null" json:"author_id"`
+	Author    User           `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Text      string         `gorm:"type:text;not null" json:"text"`
+	Mentions  []User         `gorm:"many2many:comment_mentions" json:"mentions,omitempty"`
+	ClientID  *string        `gorm:"size:36;uniqueIndex" json:"client_id,omitempty"` // UUID, присвоенный мобильным приложением офлайн
+	Version   uint           `gorm:"not null;default:1" json:"version"`              // Увеличивается при каждом изменении, передается в ETag
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// CommentCreate - структура для создания комментария
+type CommentCreate struct {
+	Text       string  `json:"text" binding:"required,max=5000"`
+	MentionIDs []uint  `json:"mention_ids"`                        // Пользователи, упомянутые в комментарии
+	ClientID   *string `json:"client_id" binding:"omitempty,uuid"` // UUID записи, созданной на устройстве
+}
+
+// CommentUpdate - структура для редактирования комментария
+type CommentUpdate struct {
+	Text string `json:"text" binding:"required,max=5000"`
+}
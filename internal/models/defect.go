@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Defect - модель дефекта, выявленного на объекте
+type Defect struct {
+	ID               uint            `gorm:"primarykey" json:"id"`
+	ProjectID        uint            `gorm:"not null;index" json:"project_id"`
+	Project          *Project        `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	Title            string          `gorm:"size:255;not null" json:"title"`
+	Description      string          `gorm:"type:text" json:"description"`
+	Location         string          `gorm:"size:255" json:"location"` // Место на объекте: корпус, этаж, помещение
+	Status           string          `gorm:"size:50;default:'new';index" json:"status"`
+	Priority         string          `gorm:"size:20;default:'normal'" json:"priority"`
+	CategoryID       *uint           `gorm:"index" json:"category_id"`
+	Category         *DefectCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	SeverityID       *uint           `gorm:"index" json:"severity_id"`
+	Severity         *SeverityLevel  `gorm:"foreignKey:SeverityID" json:"severity,omitempty"`
+	Norms            []NormReference `gorm:"many2many:defect_norms" json:"norms,omitempty"`
+	AssigneeUserID   *uint           `gorm:"index" json:"assignee_user_id"`
+	AssigneeUser     *User           `gorm:"foreignKey:AssigneeUserID" json:"assignee_user,omitempty"`
+	AssigneeOrgID    *uint           `gorm:"index" json:"assignee_org_id"`
+	AssigneeOrg      *Organization   `gorm:"foreignKey:AssigneeOrgID" json:"assignee_org,omitempty"`
+	DueDate          *time.Time      `gorm:"index" json:"due_date"`
+	ClosedAt         *time.Time      `json:"closed_at"`
+	DeadlineWarnedAt *time.Time      `json:"-"` // Когда отправлено предупреждение о приближении срока
+	EscalatedAt      *time.Time      `json:"escalated_at"`
+	EscalatedTo      *uint           `json:"escalated_to"` // Менеджер проекта, которому передана эскалация
+	SLAState         string          `gorm:"-" json:"sla_state,omitempty"`
+	ClientID         *string         `gorm:"size:36;uniqueIndex" json:"client_id,omitempty"` // UUID, присвоенный мобильным приложением офлайн
+	Version          uint            `gorm:"not null;default:1" json:"version"`              // Увеличивается при каждом изменении, передается в ETag
+	CreatedBy        uint            `json:"created_by"`
+	Creator          User            `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	Attachments      []Attachment    `gorm:"foreignKey:EntityID;constraint:-" json:"attachments,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// DefectCreate - структура для регистрации дефекта
+type DefectCreate struct {
+	ProjectID   uint       `json:"project_id" binding:"required"`
+	Title       string     `json:"title" binding:"required,min=3,max=255"`
+	Description string     `json:"description"`
+	Location    string     `json:"location" binding:"max=255"`
+	Priority    string     `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	CategoryID  *uint      `json:"category_id"`
+	SeverityID  *uint      `json:"severity_id"`
+	NormIDs     []uint     `json:"norm_ids"`
+	DueDate     *time.Time `json:"due_date"`                           // Если не указан - рассчитывается по уровню критичности
+	ClientID    *string    `json:"client_id" binding:"omitempty,uuid"` // UUID записи, созданной на устройстве
+}
+
+// DefectUpdate - все редактируемые поля дефекта для PUT (полная замена): неуказанное поле
+// очищается. Проверяется по тем же правилам, что и DefectCreate.
+type DefectUpdate struct {
+	Title       string `json:"title" binding:"required,min=3,max=255"`
+	Description string `json:"description"`
+	Location    string `json:"location" binding:"max=255"`
+	Status      string `json:"status" binding:"required,oneof=new in_progress review closed cancelled"`
+	Priority    string `json:"priority" binding:"required,oneof=low normal high urgent"`
+	CategoryID  *uint  `json:"category_id"`
+	SeverityID  *uint  `json:"severity_id"`
+	NormIDs     []uint `json:"norm_ids"`
+	// DueDate не указан - срок определяется критичностью, как при регистрации
+	DueDate *time.Time `json:"due_date"`
+}
+
+// DefectPatch - редактируемые поля дефекта для PATCH (JSON Merge Patch).
+// Результат объединения проверяется по тем же правилам, что и DefectCreate.
+type DefectPatch = DefectUpdate
+
+// DefectFilter - параметры фильтрации списка дефектов
+type DefectFilter struct {
+	ProjectID      uint
+	Status         string // Один статус или несколько через запятую
+	CategoryID     uint
+	SeverityID     uint
+	NormID         uint
+	Overdue        bool
+	AssigneeUserID uint
+	AssigneeOrgID  uint
+	Location       string     // Подстрока места расположения
+	CreatedFrom    *time.Time // Период выявления (по дате создания)
+	CreatedTo      *time.Time
+	ProjectIDs     []uint // Проекты, доступные пользователю; nil - без ограничения
+}
+
+// Константы статусов дефекта
+const (
+	DefectStatusNew        = "new"         // Новый
+	DefectStatusInProgress = "in_progress" // В работе
+	DefectStatusReview     = "review"      // На проверке
+	DefectStatusClosed     = "closed"      // Закрыт
+	DefectStatusCancelled  = "cancelled"   // Отменен
+)
+
+// Константы приоритетов дефекта
+const (
+	DefectPriorityLow    = "low"    // Низкий
+	DefectPriorityNormal = "normal" // Обычный
+	DefectPriorityHigh   = "high"   // Высокий
+	DefectPriorityUrgent = "urgent" // Срочный
+)
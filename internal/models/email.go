@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// NotificationPreference - настройка email-канала для типа уведомления.
+// Если записи нет, действует значение по умолчанию (см. EmailDefaultEvents).
+type NotificationPreference struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_notification_pref" json:"-"`
+	EventType string    `gorm:"size:50;not null;uniqueIndex:idx_notification_pref" json:"event_type"`
+	Email     bool      `gorm:"not null" json:"email"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// EmailDigest - отметка об отправленной ежедневной сводке (защита от повторной отправки)
+type EmailDigest struct {
+	ID     uint      `gorm:"primarykey"`
+	UserID uint      `gorm:"not null;uniqueIndex:idx_email_digest_user_date"`
+	Date   time.Time `gorm:"type:date;not null;uniqueIndex:idx_email_digest_user_date"`
+	SentAt time.Time
+}
+
+// NotificationPreferenceInput - изменение настройки для одного типа уведомления
+type NotificationPreferenceInput struct {
+	EventType string `json:"event_type" binding:"required"`
+	Email     *bool  `json:"email" binding:"required"`
+}
+
+// NotificationPreferencesUpdate - структура для обновления настроек уведомлений
+type NotificationPreferencesUpdate struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" binding:"required,dive"`
+}
+
+// EventDigestDaily - тип ежедневной сводки для руководителей проектов
+const EventDigestDaily = "digest.daily"
+
+// EmailDefaultEvents - типы уведомлений, которые можно получать по email,
+// и включены ли они по умолчанию
+var EmailDefaultEvents = map[string]bool{
+	EventDefectAssigned:            true,
+	EventDefectStatusChanged:       false,
+	EventDefectDeadlineApproaching: true,
+	EventDefectEscalated:           true,
+	EventCommentMentioned:          true,
+	EventFileUploaded:              false,
+	EventDigestDaily:               true,
+}
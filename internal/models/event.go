@@ -0,0 +1,20 @@
+package models
+
+// Типы доменных событий (используются и как типы уведомлений)
+const (
+	EventProjectCreated            = "project.created"
+	EventProjectUpdated            = "project.updated"
+	EventProjectDeleted            = "project.deleted"
+	EventDefectCreated             = "defect.created"
+	EventDefectUpdated             = "defect.updated"
+	EventDefectDeleted             = "defect.deleted"
+	EventDefectAssigned            = "defect.assigned"
+	EventDefectStatusChanged       = "defect.status_changed"
+	EventDefectDeadlineApproaching = "defect.deadline_approaching"
+	EventDefectEscalated           = "defect.escalated"
+	EventCommentCreated            = "comment.created"
+	EventCommentMentioned          = "comment.mentioned"
+	EventFileUploaded              = "file.uploaded"
+	EventFileDeleted               = "file.deleted"
+	EventNotificationCreated       = "notification.created"
+)
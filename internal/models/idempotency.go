@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey - сохраненный ответ на изменяющий запрос с заголовком Idempotency-Key.
+// Повторный запрос с тем же ключом получает этот ответ вместо повторного выполнения.
+type IdempotencyKey struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_idempotency_key" json:"user_id"`
+	Key          string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_key" json:"key"`
+	Method       string    `gorm:"size:10;not null" json:"method"`
+	Path         string    `gorm:"size:500;not null" json:"path"`
+	RequestHash  string    `gorm:"size:64;not null" json:"request_hash"`  // SHA-256 метода, пути и тела запроса
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"` // 0 - запрос еще выполняется
+	ContentType  string    `gorm:"size:255" json:"content_type"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// ImportRowError - ошибки одной строки файла импорта
+type ImportRowError struct {
+	Row    int      `json:"row"` // Номер строки в файле (1 - заголовки)
+	Errors []string `json:"errors"`
+}
+
+// ImportResult - результат импорта или пробного прогона
+type ImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`    // Строк данных в файле
+	Valid    int              `json:"valid"`    // Строк без ошибок
+	Imported int              `json:"imported"` // Создано записей (0 при пробном прогоне или ошибках)
+	Errors   []ImportRowError `json:"errors"`
+}
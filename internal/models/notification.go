@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Notification - уведомление пользователя внутри приложения
+type Notification struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	UserID     uint       `gorm:"not null;index:idx_notifications_user_read" json:"user_id"`
+	Type       string     `gorm:"size:50;not null" json:"type"`
+	Title      string     `gorm:"size:255;not null" json:"title"`
+	Message    string     `gorm:"type:text" json:"message"`
+	ProjectID  uint       `json:"project_id"`
+	EntityType string     `gorm:"size:50" json:"entity_type"`
+	EntityID   uint       `json:"entity_id"`
+	ActorID    uint       `json:"actor_id"`
+	ReadAt     *time.Time `gorm:"index:idx_notifications_user_read" json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization - организация-участник строительства (генподрядчик, субподрядчик, заказчик)
+type Organization struct {
+	ID           uint           `gorm:"primarykey" json:"id"`
+	Name         string         `gorm:"size:255;not null" json:"name"`
+	INN          string         `gorm:"size:12;not null;uniqueIndex" json:"inn"`
+	Type         string         `gorm:"size:50;not null" json:"type"`
+	ContactName  string         `gorm:"size:255" json:"contact_name"`
+	ContactPhone string         `gorm:"size:20" json:"contact_phone"`
+	ContactEmail string         `gorm:"size:255" json:"contact_email"`
+	Users        []User         `gorm:"foreignKey:OrganizationID" json:"users,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// OrganizationInput - структура для создания/обновления организации
+type OrganizationInput struct {
+	Name         string `json:"name" binding:"required,min=2,max=255"`
+	INN          string `json:"inn" binding:"required,numeric,min=10,max=12"`
+	Type         string `json:"type" binding:"required,oneof=general_contractor subcontractor customer"`
+	ContactName  string `json:"contact_name" binding:"max=255"`
+	ContactPhone string `json:"contact_phone" binding:"max=20"`
+	ContactEmail string `json:"contact_email" binding:"omitempty,email"`
+}
+
+// OrganizationMemberInput - структура для добавления пользователя в организацию
+type OrganizationMemberInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// Константы типов организаций
+const (
+	OrganizationTypeGeneralContractor = "general_contractor" // Генподрядчик
+	OrganizationTypeSubcontractor     = "subcontractor"      // Субподрядчик
+	OrganizationTypeCustomer          = "customer"           // Заказчик
+)
@@ -15,9 +15,10 @@ type Project struct {
 	Status      string         `gorm:"size:50;default:'active'" json:"status"`
 	StartDate   *time.Time     `json:"start_date"`
 	EndDate     *time.Time     `json:"end_date"`
+	Version     uint           `gorm:"not null;default:1" json:"version"` // Увеличивается при каждом изменении, передается в ETag
 	CreatedBy   uint           `json:"created_by"`
 	Creator     User           `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
-	Attachments []Attachment   `gorm:"foreignKey:EntityID;where:entity_type = 'project'" json:"attachments,omitempty"`
+	Attachments []Attachment   `gorm:"foreignKey:EntityID;where:entity_type = 'project';constraint:-" json:"attachments,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -32,16 +33,17 @@ type ProjectCreate struct {
 	EndDate     *time.Time `json:"end_date"`
 }
 
-// ProjectUpdate - структура для обновления проекта
+// ProjectUpdate - все редактируемые поля проекта для PUT (полная замена):
+// неуказанное поле очищается. Проверяется по тем же правилам, что и ProjectCreate.
 type ProjectUpdate struct {
-	Name        string     `json:"name" binding:"omitempty,min=3,max=255"`
-	Description string     `json:"description"`
-	Address     string     `json:"address"`
-	Status      string     `json:"status" binding:"omitempty,oneof=active completed suspended"`
-	StartDate   *time.Time `json:"start_date"`
-	EndDate     *time.Time `json:"end_date"`
+	ProjectCreate
+	Status string `json:"status" binding:"required,oneof=active completed suspended"`
 }
 
+// ProjectPatch - редактируемые поля проекта для PATCH (JSON Merge Patch).
+// Результат объединения проверяется по тем же правилам, что и ProjectCreate.
+type ProjectPatch = ProjectUpdate
+
 // Константы статусов проекта
 const (
 	ProjectStatusActive    = "active"     // Активный
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ProjectMember - участник проекта (инженер или наблюдатель, допущенный к объекту)
+type ProjectMember struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ProjectID uint      `gorm:"not null;uniqueIndex:idx_project_member" json:"project_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_project_member;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	AddedBy   uint      `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProjectMemberInput - структура для добавления участника проекта
+type ProjectMemberInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedView - сохраненный набор фильтров списка (персональное представление)
+type SavedView struct {
+	ID         uint           `gorm:"primarykey" json:"id"`
+	UserID     uint           `gorm:"not null;index" json:"user_id"` // Владелец
+	User       *User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	EntityType string         `gorm:"size:50;not null;index" json:"entity_type"`
+	Name       string         `gorm:"size:100;not null" json:"name"`
+	Query      string         `gorm:"type:text" json:"query"`      // Параметры списка в виде query string: status=new&sort=-due_date
+	ProjectID  *uint          `gorm:"index" json:"project_id"`     // Для дефектов ограничивает список проектом
+	Shared     bool           `gorm:"default:false" json:"shared"` // Доступно участникам проекта ProjectID
+	Pinned     bool           `gorm:"-" json:"pinned"`             // Закреплено текущим пользователем
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// SavedViewPin - представление, закрепленное пользователем (свое или общее)
+type SavedViewPin struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_saved_view_pin" json:"user_id"`
+	SavedViewID uint      `gorm:"not null;uniqueIndex:idx_saved_view_pin;index" json:"saved_view_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SavedViewInput - структура для создания/обновления представления
+type SavedViewInput struct {
+	EntityType string `json:"entity_type" binding:"required,oneof=project defect"`
+	Name       string `json:"name" binding:"required,min=1,max=100"`
+	Query      string `json:"query" binding:"max=2000"`
+	ProjectID  *uint  `json:"project_id"`
+	Shared     bool   `json:"shared"`
+}
+
+// SavedViewCount - количество записей, попадающих в представление
+type SavedViewCount struct {
+	ViewID uint   `json:"view_id"`
+	Name   string `json:"name"`
+	Pinned bool   `json:"pinned"`
+	Count  int64  `json:"count"`
+}
+
+// Типы списков, для которых сохраняются представления
+const (
+	SavedViewEntityProject = "project"
+	SavedViewEntityDefect  = "defect"
+)
@@ -0,0 +1,20 @@
+package models
+
+// SearchResult - найденная запись полнотекстового поиска
+type SearchResult struct {
+	Type      string  `json:"type"` // project, defect, comment, file
+	ID        uint    `json:"id"`
+	ProjectID uint    `json:"project_id"`
+	DefectID  *uint   `json:"defect_id,omitempty"` // Для комментариев и файлов дефектов
+	Title     string  `json:"title"`
+	Snippet   string  `json:"snippet"` // Фрагмент текста, совпадения выделены тегом <mark>
+	Rank      float64 `json:"rank"`
+}
+
+// Типы результатов поиска
+const (
+	SearchTypeProject = "project"
+	SearchTypeDefect  = "defect"
+	SearchTypeComment = "comment"
+	SearchTypeFile    = "file"
+)
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// StatsItem - количество дефектов в группе (статус, критичность, исполнитель и т.п.)
+type StatsItem struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+	Open  int64  `json:"open"` // Из них не закрыты и не отменены
+}
+
+// StatsWeek - выявленные и закрытые за неделю дефекты
+type StatsWeek struct {
+	WeekStart time.Time `json:"week_start"`
+	Opened    int64     `json:"opened"`
+	Closed    int64     `json:"closed"`
+}
+
+// DefectStats - сводная аналитика по дефектам проекта или всего портфеля
+type DefectStats struct {
+	ProjectID   *uint       `json:"project_id"`
+	Total       int64       `json:"total"`
+	Open        int64       `json:"open"`
+	Closed      int64       `json:"closed"`
+	Overdue     int64       `json:"overdue"`
+	AvgFixHours *float64    `json:"avg_fix_hours"` // Среднее время от выявления до закрытия; nil - закрытых нет
+	ByStatus    []StatsItem `json:"by_status"`
+	BySeverity  []StatsItem `json:"by_severity"`
+	ByCategory  []StatsItem `json:"by_category"`
+	ByAssignee  []StatsItem `json:"by_assignee"`
+	ByLocation  []StatsItem `json:"by_location"`
+	ByProject   []StatsItem `json:"by_project,omitempty"` // Только для портфеля
+	Trend       []StatsWeek `json:"trend"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// StatsFilter - параметры аналитики
+type StatsFilter struct {
+	ProjectID   uint
+	CreatedFrom *time.Time // Период выявления дефектов
+	CreatedTo   *time.Time
+	Weeks       int            // Глубина недельного тренда
+	Location    *time.Location // Часовой пояс периода и границ недель; nil - UTC
+}
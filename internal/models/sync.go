@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SyncChanges - изменения записей одного типа с момента прошлой синхронизации
+type SyncChanges[T any] struct {
+	Created []T    `json:"created"`
+	Updated []T    `json:"updated"`
+	Deleted []uint `json:"deleted"` // ID удаленных записей
+}
+
+// SyncDelta - ответ синхронизации: изменения и токен для следующего запроса
+type SyncDelta struct {
+	Projects    SyncChanges[Project]    `json:"projects"`
+	Defects     SyncChanges[Defect]     `json:"defects"`
+	Comments    SyncChanges[Comment]    `json:"comments"`
+	Attachments SyncChanges[Attachment] `json:"attachments"`
+	SyncToken   string                  `json:"sync_token"`
+	HasMore     bool                    `json:"has_more"` // Изменений больше лимита - нужно запросить еще раз с новым токеном
+	ServerTime  time.Time               `json:"server_time"`
+}
+
+// SyncChange - изменение, сделанное на устройстве без связи
+type SyncChange struct {
+	Entity        string          `json:"entity" binding:"required,oneof=defect comment"`
+	Op            string          `json:"op" binding:"required,oneof=create update delete"`
+	ClientID      string          `json:"client_id" binding:"omitempty,uuid"` // UUID записи; обязателен для create
+	ID            uint            `json:"id"`                                 // Серверный ID (для update/delete вместо client_id)
+	BaseUpdatedAt *time.Time      `json:"base_updated_at"`                    // updated_at записи, которую редактировал клиент
+	Data          json.RawMessage `json:"data"`                               // DefectCreate, JSON Merge Patch дефекта, SyncCommentCreate или CommentUpdate
+}
+
+// SyncPush - пакет изменений с устройства
+type SyncPush struct {
+	Changes []SyncChange `json:"changes" binding:"required,min=1,max=500,dive"`
+}
+
+// SyncCommentCreate - комментарий, созданный офлайн; дефект может быть указан
+// серверным ID или client_id, если он создан в том же пакете
+type SyncCommentCreate struct {
+	DefectID       uint   `json:"defect_id"`
+	DefectClientID string `json:"defect_client_id" binding:"omitempty,uuid"`
+	Text           string `json:"text" binding:"required,max=5000"`
+	MentionIDs     []uint `json:"mention_ids"`
+}
+
+// SyncResult - результат применения одного изменения
+type SyncResult struct {
+	Index    int         `json:"index"` // Позиция изменения в пакете
+	Entity   string      `json:"entity"`
+	Op       string      `json:"op"`
+	ClientID string      `json:"client_id,omitempty"`
+	ID       uint        `json:"id,omitempty"`
+	Status   string      `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	Server   interface{} `json:"server,omitempty"` // Текущее состояние записи на сервере при конфликте
+}
+
+// Статусы применения изменений
+const (
+	SyncStatusApplied   = "applied"   // Изменение применено
+	SyncStatusDuplicate = "duplicate" // Запись с таким client_id уже создана ранее
+	SyncStatusConflict  = "conflict"  // Запись изменена на сервере после base_updated_at
+	SyncStatusError     = "error"     // Изменение отклонено
+)
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook - внешний адрес, на который отправляются события (ERP, портал заказчика)
+type Webhook struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	ProjectID   *uint          `gorm:"index" json:"project_id"` // nil - события всех проектов
+	URL         string         `gorm:"size:1000;not null" json:"url"`
+	Secret      string         `gorm:"size:255;not null" json:"-"` // Ключ подписи HMAC-SHA256
+	EventTypes  string         `gorm:"type:text" json:"-"`         // Через запятую; пусто - все события
+	Events      []string       `gorm:"-" json:"events"`
+	Description string         `gorm:"size:500" json:"description"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	CreatedBy   uint           `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// WebhookDelivery - попытка доставки события на webhook (очередь и журнал)
+type WebhookDelivery struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	WebhookID     uint       `gorm:"not null;index" json:"webhook_id"`
+	EventType     string     `gorm:"size:50;not null" json:"event_type"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"size:20;not null;index:idx_webhook_deliveries_queue" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time `gorm:"index:idx_webhook_deliveries_queue" json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	ResponseCode  int        `json:"response_code"`
+	ResponseBody  string     `gorm:"type:text" json:"response_body"`
+	Error         string     `gorm:"type:text" json:"error"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebhookInput - структура для регистрации/обновления webhook
+type WebhookInput struct {
+	ProjectID   *uint    `json:"project_id"`
+	URL         string   `json:"url" binding:"required,url,max=1000"`
+	Events      []string `json:"events"`
+	Description string   `json:"description" binding:"max=500"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// Константы статусов доставки webhook
+const (
+	WebhookDeliveryPending   = "pending"   // Ожидает отправки или повтора
+	WebhookDeliveryDelivered = "delivered" // Доставлено (ответ 2xx)
+	WebhookDeliveryFailed    = "failed"    // Исчерпаны попытки
+)
+
+// EventWebhookTest - тип тестового события, отправляемого вручную
+const EventWebhookTest = "webhook.test"
+
+// WebhookEventTypes - события, на которые можно подписать webhook
+var WebhookEventTypes = []string{
+	EventProjectCreated,
+	EventProjectUpdated,
+	EventProjectDeleted,
+	EventDefectCreated,
+	EventDefectUpdated,
+	EventDefectDeleted,
+	EventDefectAssigned,
+	EventDefectStatusChanged,
+	EventDefectEscalated,
+	EventCommentCreated,
+	EventFileUploaded,
+	EventFileDeleted,
+}
@@ -0,0 +1,89 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Message - событие, доставляемое клиентам по потоку реального времени
+type Message struct {
+	ID        uint64          `json:"id"`
+	Type      string          `json:"type"`
+	ProjectID uint            `json:"project_id"`
+	UserID    uint            `json:"user_id,omitempty"` // Адресат личного сообщения; 0 - всем участникам проекта
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Broker - шина публикации/подписки. Реализации: в памяти процесса
+// (один экземпляр API) и PostgreSQL LISTEN/NOTIFY (несколько экземпляров).
+type Broker interface {
+	// Publish присваивает сообщению ID и доставляет его всем подписчикам
+	Publish(msg Message) error
+	// Subscribe возвращает канал новых сообщений и функцию отписки
+	Subscribe() (<-chan Message, func())
+	// Since возвращает сообщения с ID больше lastID для повтора после переподключения
+	Since(lastID uint64, limit int) ([]Message, error)
+	// Close освобождает ресурсы брокера
+	Close() error
+}
+
+// Размер буфера канала подписчика; медленный клиент теряет сообщения сверх него
+// и догоняет их через Last-Event-ID при переподключении
+const subscriberBuffer = 64
+
+// hub - локальная рассылка сообщений подписчикам текущего процесса
+type hub struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]chan Message
+}
+
+func newHub() *hub {
+	return &hub{subscribers: make(map[int]chan Message)}
+}
+
+func (h *hub) subscribe() (<-chan Message, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	ch := make(chan Message, subscriberBuffer)
+	h.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers, id)
+			close(ch)
+		})
+	}
+}
+
+func (h *hub) broadcast(msg Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for id, ch := range h.subscribers {
+		select {
+		case ch <- msg:
+		default:
+			slog.Warn("Подписчик не успевает получать события, сообщение пропущено", "subscriber_id", id, "message_id", msg.ID)
+		}
+	}
+}
+
+func (h *hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, ch := range h.subscribers {
+		delete(h.subscribers, id)
+		close(ch)
+	}
+}
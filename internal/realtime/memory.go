@@ -0,0 +1,72 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryBroker - брокер в памяти процесса; хранит последние сообщения для повтора
+type MemoryBroker struct {
+	*hub
+	mu       sync.Mutex
+	lastID   uint64
+	history  []Message
+	capacity int
+}
+
+// NewMemoryBroker создает брокер в памяти с историей на capacity сообщений
+func NewMemoryBroker(capacity int) *MemoryBroker {
+	if capacity < 1 {
+		capacity = 1000
+	}
+	return &MemoryBroker{hub: newHub(), capacity: capacity}
+}
+
+// Publish сохраняет сообщение в истории и рассылает подписчикам
+func (b *MemoryBroker) Publish(msg Message) error {
+	b.mu.Lock()
+	// ID не меньше текущего времени в микросекундах: после перезапуска ID продолжают расти,
+	// и клиент с Last-Event-ID прошлого запуска не пропускает новые события
+	now := time.Now()
+	b.lastID = max(b.lastID+1, uint64(now.UnixMicro()))
+	msg.ID = b.lastID
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = now
+	}
+	b.history = append(b.history, msg)
+	if len(b.history) > b.capacity {
+		b.history = b.history[len(b.history)-b.capacity:]
+	}
+	b.mu.Unlock()
+
+	b.broadcast(msg)
+	return nil
+}
+
+// Subscribe подписывает на новые сообщения
+func (b *MemoryBroker) Subscribe() (<-chan Message, func()) {
+	return b.subscribe()
+}
+
+// Since возвращает сообщения из истории после lastID
+func (b *MemoryBroker) Since(lastID uint64, limit int) ([]Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []Message
+	for _, msg := range b.history {
+		if msg.ID > lastID {
+			result = append(result, msg)
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// Close отключает всех подписчиков
+func (b *MemoryBroker) Close() error {
+	b.closeAll()
+	return nil
+}
@@ -0,0 +1,32 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryBrokerIDsGrowAcrossRestarts(t *testing.T) {
+	before := NewMemoryBroker(10)
+	for i := 0; i < 100; i++ {
+		if err := before.Publish(Message{Type: "defect.updated"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last, _ := before.Since(0, 0)
+	lastID := last[len(last)-1].ID
+
+	// Клиент переподключается к перезапущенному брокеру с Last-Event-ID прошлого запуска;
+	// перезапуск занимает больше, чем публикация пачки событий
+	time.Sleep(time.Millisecond)
+	after := NewMemoryBroker(10)
+	if err := after.Publish(Message{Type: "defect.created"}); err != nil {
+		t.Fatal(err)
+	}
+	missed, err := after.Since(lastID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missed) != 1 || missed[0].Type != "defect.created" {
+		t.Fatalf("событие после перезапуска потеряно: %+v", missed)
+	}
+}
@@ -0,0 +1,244 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// Канал PostgreSQL NOTIFY для событий реального времени
+const notifyChannel = "realtime_events"
+
+// realtimeEvent - строка журнала событий; общий источник ID и истории для всех экземпляров API
+type realtimeEvent struct {
+	ID        uint64          `gorm:"primarykey"`
+	Type      string          `gorm:"size:50;not null"`
+	ProjectID uint            `gorm:"index"`
+	UserID    uint            `gorm:"index"`
+	Data      json.RawMessage `gorm:"type:jsonb"`
+	CreatedAt time.Time       `gorm:"index"`
+}
+
+func (realtimeEvent) TableName() string {
+	return "realtime_events"
+}
+
+func (e realtimeEvent) message() Message {
+	return Message{
+		ID:        e.ID,
+		Type:      e.Type,
+		ProjectID: e.ProjectID,
+		UserID:    e.UserID,
+		Data:      e.Data,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// PostgresBroker - брокер поверх PostgreSQL: события пишутся в таблицу,
+// а экземпляры API узнают о них через LISTEN/NOTIFY
+type PostgresBroker struct {
+	*hub
+	db        *gorm.DB
+	dsn       string
+	retention time.Duration
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu       sync.Mutex
+	lastSeen uint64
+}
+
+// NewPostgresBroker создает брокер и запускает прослушивание канала уведомлений
+func NewPostgresBroker(db *gorm.DB, dsn string, retention time.Duration) (*PostgresBroker, error) {
+	if err := db.AutoMigrate(&realtimeEvent{}); err != nil {
+		return nil, err
+	}
+
+	var lastID uint64
+	db.Model(&realtimeEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&lastID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &PostgresBroker{
+		hub:       newHub(),
+		db:        db,
+		dsn:       dsn,
+		retention: retention,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		lastSeen:  lastID,
+	}
+	go b.listen(ctx)
+	return b, nil
+}
+
+// Publish записывает событие в журнал и оповещает все экземпляры через NOTIFY
+func (b *PostgresBroker) Publish(msg Message) error {
+	event := realtimeEvent{
+		Type:      msg.Type,
+		ProjectID: msg.ProjectID,
+		UserID:    msg.UserID,
+		Data:      msg.Data,
+	}
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		// Уведомление доставляется слушателям после фиксации транзакции
+		return tx.Exec("SELECT pg_notify(?, ?)", notifyChannel, strconv.FormatUint(event.ID, 10)).Error
+	})
+}
+
+// Subscribe подписывает на новые сообщения
+func (b *PostgresBroker) Subscribe() (<-chan Message, func()) {
+	return b.subscribe()
+}
+
+// Since возвращает события журнала после lastID
+func (b *PostgresBroker) Since(lastID uint64, limit int) ([]Message, error) {
+	var events []realtimeEvent
+	query := b.db.Where("id > ?", lastID).Order("id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, len(events))
+	for _, event := range events {
+		messages = append(messages, event.message())
+	}
+	return messages, nil
+}
+
+// Close останавливает прослушивание и отключает подписчиков
+func (b *PostgresBroker) Close() error {
+	b.cancel()
+	<-b.done
+	b.closeAll()
+	return nil
+}
+
+// listen держит выделенное соединение с LISTEN и переподключается при обрывах
+func (b *PostgresBroker) listen(ctx context.Context) {
+	defer close(b.done)
+
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if err := b.listenOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Ошибка прослушивания событий PostgreSQL, переподключение", "error", err, "retry_in", backoff.String())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+	}
+}
+
+func (b *PostgresBroker) listenOnce(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	// События, опубликованные пока соединение было разорвано
+	b.deliverSince()
+
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		notification, err := conn.WaitForNotification(waitCtx)
+		cancel()
+
+		select {
+		case <-cleanup.C:
+			b.deleteExpired()
+		default:
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if waitCtx.Err() == context.DeadlineExceeded {
+				continue
+			}
+			return err
+		}
+
+		id, err := strconv.ParseUint(notification.Payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		b.deliver(id)
+	}
+}
+
+// deliver загружает событие по ID и рассылает его локальным подписчикам
+func (b *PostgresBroker) deliver(id uint64) {
+	var event realtimeEvent
+	if err := b.db.First(&event, id).Error; err != nil {
+		slog.Warn("Событие не найдено в журнале", "event_id", id, "error", err)
+		return
+	}
+
+	b.mu.Lock()
+	if id > b.lastSeen {
+		b.lastSeen = id
+	}
+	b.mu.Unlock()
+
+	b.broadcast(event.message())
+}
+
+// deliverSince рассылает события, пропущенные во время переподключения
+func (b *PostgresBroker) deliverSince() {
+	b.mu.Lock()
+	lastSeen := b.lastSeen
+	b.mu.Unlock()
+
+	messages, err := b.Since(lastSeen, 0)
+	if err != nil {
+		slog.Error("Не удалось загрузить пропущенные события", "error", err)
+		return
+	}
+
+	for _, msg := range messages {
+		b.mu.Lock()
+		if msg.ID > b.lastSeen {
+			b.lastSeen = msg.ID
+		}
+		b.mu.Unlock()
+		b.broadcast(msg)
+	}
+}
+
+// deleteExpired удаляет из журнала события старше срока хранения
+func (b *PostgresBroker) deleteExpired() {
+	if b.retention <= 0 {
+		return
+	}
+	if err := b.db.Where("created_at < ?", time.Now().Add(-b.retention)).Delete(&realtimeEvent{}).Error; err != nil {
+		slog.Error("Не удалось очистить журнал событий", "error", err)
+	}
+}
@@ -0,0 +1,314 @@
+package reports
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Шрифты с кириллицей встраиваются в бинарник, чтобы отчет не зависел от системы
+var (
+	//go:embed fonts/DejaVuSansCondensed.ttf
+	fontRegular []byte
+	//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+	fontBold []byte
+)
+
+const (
+	fontFamily = "DejaVu"
+
+	pageMargin = 15.0
+	lineHeight = 4.5
+	cellPad    = 1.5
+
+	// Размер миниатюры фотографии в отчете, мм
+	thumbBoxW = 42.0
+	thumbBoxH = 32.0
+)
+
+// DefectReport - данные акта осмотра
+type DefectReport struct {
+	ProjectName string
+	Address     string
+	Period      string // Сроки работ по проекту
+	Filters     string // Описание примененных фильтров
+	GeneratedAt time.Time
+	GeneratedBy string
+	Rows        []DefectRow
+}
+
+// DefectRow - строка таблицы дефектов
+type DefectRow struct {
+	Title       string
+	Description string
+	Location    string
+	Category    string
+	Severity    string
+	Status      string
+	DueDate     string
+	Photos      [][]byte // Миниатюры в JPEG (см. Thumbnail)
+}
+
+// Колонки таблицы: заголовок и ширина, мм (сумма - ширина страницы без полей)
+var defectColumns = []struct {
+	title string
+	width float64
+}{
+	{"№", 8},
+	{"Дефект", 62},
+	{"Место", 28},
+	{"Категория, критичность", 32},
+	{"Статус", 22},
+	{"Срок устранения", 28},
+}
+
+// WriteDefectsPDF формирует акт осмотра в PDF и записывает его в w
+func WriteDefectsPDF(w io.Writer, report DefectReport) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", fontRegular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", fontBold)
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(false, pageMargin)
+	pdf.AliasNbPages("{nb}")
+	pdf.SetTitle("Акт осмотра: "+report.ProjectName, true)
+	pdf.SetCreator("SystemControl", true)
+
+	inTable := false
+	pdf.SetHeaderFunc(func() {
+		if inTable {
+			drawTableHeader(pdf)
+		}
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pageMargin + 3)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 4, report.ProjectName, "", 0, "L", false, 0, "")
+		pdf.SetX(pageMargin)
+		pdf.CellFormat(0, 4, fmt.Sprintf("Страница %d из {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	drawTitle(pdf, report)
+
+	if len(report.Rows) == 0 {
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.CellFormat(0, 8, "Дефекты, соответствующие условиям отбора, не выявлены.", "", 1, "L", false, 0, "")
+	} else {
+		inTable = true
+		drawTableHeader(pdf)
+		for i, row := range report.Rows {
+			drawDefectRow(pdf, i+1, row)
+		}
+		inTable = false
+	}
+
+	drawSignatures(pdf, report)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// drawTitle выводит заголовок акта и реквизиты проекта
+func drawTitle(pdf *gofpdf.Fpdf, report DefectReport) {
+	pdf.SetFont(fontFamily, "B", 14)
+	pdf.CellFormat(0, 8, "АКТ ОСМОТРА", "", 1, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.CellFormat(0, 6, "выявленных дефектов строительно-монтажных работ", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.CellFormat(38, 5.5, label, "", 0, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.MultiCell(0, 5.5, value, "", "L", false)
+	}
+
+	field("Объект:", report.ProjectName)
+	field("Адрес:", report.Address)
+	field("Сроки работ:", report.Period)
+	field("Условия отбора:", report.Filters)
+	field("Дата составления:", report.GeneratedAt.Format("02.01.2006 15:04"))
+	field("Всего дефектов:", strconv.Itoa(len(report.Rows)))
+	pdf.Ln(4)
+}
+
+// drawTableHeader выводит шапку таблицы дефектов (повторяется на каждой странице)
+func drawTableHeader(pdf *gofpdf.Fpdf) {
+	pdf.SetFont(fontFamily, "B", 8.5)
+	pdf.SetFillColor(230, 230, 230)
+
+	height := 0.0
+	for _, col := range defectColumns {
+		if h := float64(len(pdf.SplitText(col.title, col.width)))*lineHeight + 2*cellPad; h > height {
+			height = h
+		}
+	}
+
+	x, y := pdf.GetX(), pdf.GetY()
+	for _, col := range defectColumns {
+		pdf.Rect(x, y, col.width, height, "FD")
+		pdf.SetXY(x, y+cellPad)
+		pdf.MultiCell(col.width, lineHeight, col.title, "", "C", false)
+		x += col.width
+	}
+	pdf.SetXY(pageMargin, y+height)
+}
+
+// drawDefectRow выводит строку дефекта и, если есть, миниатюры фотографий под ней
+func drawDefectRow(pdf *gofpdf.Fpdf, number int, row DefectRow) {
+	description := row.Title
+	if row.Description != "" {
+		description += "\n" + row.Description
+	}
+	category := row.Category
+	if row.Severity != "" {
+		if category != "" {
+			category += ", "
+		}
+		category += row.Severity
+	}
+	cells := []string{strconv.Itoa(number), description, row.Location, category, row.Status, row.DueDate}
+
+	pdf.SetFont(fontFamily, "", 8.5)
+	height := 0.0
+	for i, text := range cells {
+		if h := float64(len(pdf.SplitText(text, defectColumns[i].width)))*lineHeight + 2*cellPad; h > height {
+			height = h
+		}
+	}
+
+	ensureSpace(pdf, height)
+
+	x, y := pdf.GetX(), pdf.GetY()
+	for i, text := range cells {
+		width := defectColumns[i].width
+		pdf.Rect(x, y, width, height, "D")
+		pdf.SetXY(x, y+cellPad)
+		pdf.MultiCell(width, lineHeight, text, "", "L", false)
+		x += width
+	}
+	pdf.SetXY(pageMargin, y+height)
+
+	if len(row.Photos) > 0 {
+		drawPhotos(pdf, number, row.Photos)
+	}
+}
+
+// drawPhotos выводит миниатюры фотографий дефекта в строку под ним
+func drawPhotos(pdf *gofpdf.Fpdf, number int, photos [][]byte) {
+	tableWidth := 0.0
+	for _, col := range defectColumns {
+		tableWidth += col.width
+	}
+	perLine := int(tableWidth / thumbBoxW)
+
+	for start := 0; start < len(photos); start += perLine {
+		end := start + perLine
+		if end > len(photos) {
+			end = len(photos)
+		}
+
+		height := thumbBoxH + 2*cellPad
+		ensureSpace(pdf, height)
+
+		y := pdf.GetY()
+		pdf.Rect(pageMargin, y, tableWidth, height, "D")
+
+		x := pageMargin + cellPad
+		for i := start; i < end; i++ {
+			name := fmt.Sprintf("defect%d_photo%d", number, i)
+			options := gofpdf.ImageOptions{ImageType: "JPG"}
+			info := pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(photos[i]))
+			if info == nil || pdf.Err() {
+				// Поврежденное изображение не должно ломать весь отчет
+				pdf.ClearError()
+				continue
+			}
+
+			w, h := fitInto(info.Width(), info.Height(), thumbBoxW-2*cellPad, thumbBoxH)
+			pdf.ImageOptions(name, x+(thumbBoxW-2*cellPad-w)/2, y+cellPad+(thumbBoxH-h)/2, w, h, false, options, 0, "")
+			x += thumbBoxW
+		}
+		pdf.SetXY(pageMargin, y+height)
+	}
+}
+
+// drawSignatures выводит блоки подписей участников осмотра
+func drawSignatures(pdf *gofpdf.Fpdf, report DefectReport) {
+	signers := []struct {
+		role string
+		name string
+	}{
+		{"Составил", report.GeneratedBy},
+		{"Представитель заказчика", ""},
+		{"Представитель подрядчика", ""},
+	}
+
+	const blockHeight = 20.0
+	pdf.Ln(8)
+	ensureSpace(pdf, blockHeight*float64(len(signers))+8)
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(0, 6, "Подписи:", "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	for _, signer := range signers {
+		y := pdf.GetY()
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.CellFormat(55, 6, signer.role, "", 0, "L", false, 0, "")
+
+		// Линии для подписи и расшифровки
+		pdf.Line(pageMargin+55, y+6, pageMargin+95, y+6)
+		pdf.Line(pageMargin+100, y+6, pageMargin+150, y+6)
+		if signer.name != "" {
+			pdf.SetXY(pageMargin+100, y)
+			pdf.CellFormat(50, 6, signer.name, "", 0, "C", false, 0, "")
+		}
+
+		pdf.SetFont(fontFamily, "", 7)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.SetXY(pageMargin+55, y+6.5)
+		pdf.CellFormat(40, 3, "(подпись)", "", 0, "C", false, 0, "")
+		pdf.SetXY(pageMargin+100, y+6.5)
+		pdf.CellFormat(50, 3, "(Ф.И.О.)", "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetXY(pageMargin+55, y+11)
+		pdf.CellFormat(0, 5, "Дата: «___» ____________ 20___ г.", "", 0, "L", false, 0, "")
+
+		pdf.SetXY(pageMargin, y+blockHeight)
+	}
+}
+
+// ensureSpace переносит вывод на новую страницу, если блок высотой height не помещается
+func ensureSpace(pdf *gofpdf.Fpdf, height float64) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+height > pageHeight-pageMargin {
+		pdf.AddPage()
+	}
+}
+
+// fitInto вписывает изображение в прямоугольник с сохранением пропорций
+func fitInto(width, height, boxW, boxH float64) (float64, float64) {
+	if width <= 0 || height <= 0 {
+		return boxW, boxH
+	}
+	scale := boxW / width
+	if height*scale > boxH {
+		scale = boxH / height
+	}
+	return width * scale, height * scale
+}
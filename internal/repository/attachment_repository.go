@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// gormAttachmentRepository - хранилище записей о файлах в БД через GORM
+type gormAttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository создает хранилище файлов на подключении db
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &gormAttachmentRepository{db: db}
+}
+
+func (r *gormAttachmentRepository) FindByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *gormAttachmentRepository) ListByEntity(entityType string, entityID uint, options *listquery.Options) ([]models.Attachment, *listquery.PageInfo, error) {
+	var attachments []models.Attachment
+	query := r.db.Model(&models.Attachment{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+	if options == nil {
+		query = query.Order("attachments.id")
+	}
+
+	page, err := options.Find(query, &attachments)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachments, page, nil
+}
+
+func (r *gormAttachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *gormAttachmentRepository) Save(attachment *models.Attachment) error {
+	return r.db.Save(attachment).Error
+}
+
+func (r *gormAttachmentRepository) Delete(attachment *models.Attachment) error {
+	return r.db.Delete(attachment).Error
+}
+
+func (r *gormAttachmentRepository) DeleteByEntity(entityType string, entityID uint) error {
+	return r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Delete(&models.Attachment{}).Error
+}
+
+func (r *gormAttachmentRepository) ProjectIDOf(entityType string, entityID uint) uint {
+	if entityType == models.EntityTypeProject {
+		return entityID
+	}
+
+	var defect models.Defect
+	if err := r.db.Select("project_id").First(&defect, entityID).Error; err != nil {
+		return 0
+	}
+	return defect.ProjectID
+}
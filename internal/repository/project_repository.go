@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// gormProjectRepository - хранилище проектов в БД через GORM
+type gormProjectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository создает хранилище проектов на подключении db
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &gormProjectRepository{db: db}
+}
+
+func (r *gormProjectRepository) FindByID(id uint) (*models.Project, error) {
+	var project models.Project
+	if err := r.db.First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *gormProjectRepository) FindWithCreator(id uint) (*models.Project, error) {
+	var project models.Project
+	if err := r.db.Preload("Creator").First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *gormProjectRepository) List(status string, projectIDs []uint, options *listquery.Options) ([]models.Project, *listquery.PageInfo, error) {
+	var projects []models.Project
+	query := r.db.Model(&models.Project{}).Preload("Creator")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if projectIDs != nil {
+		query = query.Where("projects.id IN ?", projectIDs)
+	}
+
+	page, err := options.Find(query, &projects)
+	if err != nil {
+		return nil, nil, err
+	}
+	return projects, page, nil
+}
+
+func (r *gormProjectRepository) Create(project *models.Project) error {
+	return r.db.Create(project).Error
+}
+
+func (r *gormProjectRepository) Update(project *models.Project) error {
+	return SaveVersioned(r.db, project, &project.Version)
+}
+
+func (r *gormProjectRepository) Delete(project *models.Project) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		defectIDs := tx.Model(&models.Defect{}).Select("id").Where("project_id = ?", project.ID)
+		attachmentScope := func() *gorm.DB {
+			return tx.Where("(entity_type = ? AND entity_id = ?) OR (entity_type = ? AND entity_id IN (?))",
+				models.EntityTypeProject, project.ID, models.EntityTypeDefect, defectIDs)
+		}
+
+		if err := attachmentScope().Order("id").Find(&attachments).Error; err != nil {
+			return err
+		}
+		if err := attachmentScope().Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		// Уведомления о событиях проекта, его дефектов, комментариев и файлов
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := DeleteDefectDependents(tx, defectIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.Defect{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(project).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// DeleteDefectDependents удаляет в транзакции tx записи, относящиеся к дефектам defectIDs
+// (ID или подзапрос): комментарии удаляются мягко, чтобы офлайн-клиенты получили их удаление
+// при синхронизации, история назначений и уведомления о дефектах, их комментариях и файлах - совсем.
+// Файлы дефектов удаляет вызывающий код.
+func DeleteDefectDependents(tx *gorm.DB, defectIDs interface{}) error {
+	commentIDs := tx.Unscoped().Model(&models.Comment{}).Select("id").Where("defect_id IN (?)", defectIDs)
+	attachmentIDs := tx.Unscoped().Model(&models.Attachment{}).Select("id").
+		Where("entity_type = ? AND entity_id IN (?)", models.EntityTypeDefect, defectIDs)
+	err := tx.Where("(entity_type = ? AND entity_id IN (?)) OR (entity_type = ? AND entity_id IN (?)) OR "+
+		"(entity_type = ? AND entity_id IN (?))",
+		models.EntityTypeDefect, defectIDs, "comment", commentIDs, "file", attachmentIDs).
+		Delete(&models.Notification{}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("defect_id IN (?)", defectIDs).Delete(&models.DefectAssignment{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("defect_id IN (?)", defectIDs).Delete(&models.Comment{}).Error
+}
@@ -0,0 +1,54 @@
+// Package repository отделяет сервисы от хранилища: сервисы работают с интерфейсами,
+// реализации на GORM создаются в main, в тестах их можно заменить подделками.
+//
+// Если запись не найдена, методы Find* возвращают gorm.ErrRecordNotFound.
+package repository
+
+import (
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+)
+
+// UserRepository - хранилище пользователей
+type UserRepository interface {
+	// FindByID возвращает пользователя с ролью
+	FindByID(id uint) (*models.User, error)
+	// FindByEmail возвращает пользователя с ролью
+	FindByEmail(email string) (*models.User, error)
+	EmailExists(email string) (bool, error)
+	FindRoleByCode(code string) (*models.Role, error)
+	OrganizationExists(id uint) (bool, error)
+	Create(user *models.User) error
+	// List возвращает пользователей с ролью и организацией
+	List(options *listquery.Options) ([]models.User, *listquery.PageInfo, error)
+}
+
+// ProjectRepository - хранилище проектов
+type ProjectRepository interface {
+	FindByID(id uint) (*models.Project, error)
+	// FindWithCreator возвращает проект с создателем
+	FindWithCreator(id uint) (*models.Project, error)
+	// List возвращает проекты с создателями; status == "" - все статусы,
+	// projectIDs == nil - без ограничения по проектам
+	List(status string, projectIDs []uint, options *listquery.Options) ([]models.Project, *listquery.PageInfo, error)
+	Create(project *models.Project) error
+	// Update сохраняет проект, если его версия в хранилище не изменилась с момента чтения,
+	// и увеличивает версию; иначе возвращает ошибку конфликта
+	Update(project *models.Project) error
+	// Delete мягко удаляет проект вместе с его дефектами и их файлами в одной транзакции
+	// и возвращает удаленные записи о файлах, чтобы убрать файлы с диска
+	Delete(project *models.Project) ([]models.Attachment, error)
+}
+
+// AttachmentRepository - хранилище записей о файлах
+type AttachmentRepository interface {
+	FindByID(id uint) (*models.Attachment, error)
+	// ListByEntity возвращает файлы сущности; options == nil - все файлы в порядке загрузки
+	ListByEntity(entityType string, entityID uint, options *listquery.Options) ([]models.Attachment, *listquery.PageInfo, error)
+	Create(attachment *models.Attachment) error
+	Save(attachment *models.Attachment) error
+	Delete(attachment *models.Attachment) error
+	DeleteByEntity(entityType string, entityID uint) error
+	// ProjectIDOf возвращает проект, к которому относится сущность с файлами (0 - не найден)
+	ProjectIDOf(entityType string, entityID uint) uint
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// gormUserRepository - хранилище пользователей в БД через GORM
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository создает хранилище пользователей на подключении db
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("Role").First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("Role").Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) EmailExists(email string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) FindRoleByCode(code string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Where("code = ?", code).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *gormUserRepository) OrganizationExists(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Organization{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *gormUserRepository) List(options *listquery.Options) ([]models.User, *listquery.PageInfo, error) {
+	var users []models.User
+	query := r.db.Model(&models.User{}).Preload("Role").Preload("Organization")
+
+	page, err := options.Find(query, &users)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, page, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ошибки версионирования записей (заголовки ETag и If-Match)
+var (
+	// ErrVersionMismatch - версия из If-Match уже не текущая
+	ErrVersionMismatch = errors.New("версия записи устарела")
+	// ErrVersionConflict - запись изменили между чтением и сохранением
+	ErrVersionConflict = errors.New("запись изменена другим пользователем")
+)
+
+// SaveVersioned сохраняет все поля записи при условии, что ее версия в БД не изменилась
+// с момента чтения, и увеличивает версию. Если запись за это время изменил кто-то другой,
+// ничего не сохраняется и возвращается ErrVersionConflict.
+func SaveVersioned(tx *gorm.DB, model interface{}, version *uint) error {
+	loaded := *version
+	*version = loaded + 1
+
+	result := tx.Model(model).Where("version = ?", loaded).
+		Select("*").Omit(clause.Associations, "created_at").Updates(model)
+	if result.Error != nil {
+		*version = loaded
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = loaded
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package router_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+func TestEngineerListsOnlyAccessibleProjects(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+
+	memberProject := createProject(env, manager)
+	otherProject := createProject(env, manager)
+	memberDefect := createDefect(env, manager, memberProject, "Трещина в перекрытии")
+	createDefect(env, manager, otherProject, "Протечка в подвале")
+
+	env.JSON(http.MethodPost, "/api/v1/projects/"+strconv.Itoa(int(memberProject))+"/members", manager.Token,
+		map[string]uint{"user_id": engineer.ID}).ExpectStatus(http.StatusCreated)
+
+	var projects struct {
+		Projects []struct {
+			ID uint `json:"id"`
+		} `json:"projects"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/projects", engineer.Token, nil).ExpectStatus(http.StatusOK).Decode(&projects)
+	if len(projects.Projects) != 1 || projects.Projects[0].ID != memberProject {
+		t.Fatalf("инженер видит чужие проекты: %+v", projects.Projects)
+	}
+
+	var defects struct {
+		Defects []struct {
+			ID uint `json:"id"`
+		} `json:"defects"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/defects", engineer.Token, nil).ExpectStatus(http.StatusOK).Decode(&defects)
+	if len(defects.Defects) != 1 || defects.Defects[0].ID != memberDefect {
+		t.Fatalf("инженер видит дефекты чужих проектов: %+v", defects.Defects)
+	}
+
+	// Выгрузка ограничена так же, как список
+	if names := exportNames(t, env, engineer.Token, "/api/v1/defects/export?format=csv"); len(names) != 1 {
+		t.Fatalf("выгрузка содержит дефекты чужих проектов: %v", names)
+	}
+
+	// Менеджер по-прежнему видит все проекты
+	env.JSON(http.MethodGet, "/api/v1/projects", manager.Token, nil).ExpectStatus(http.StatusOK).Decode(&projects)
+	if len(projects.Projects) != 2 {
+		t.Fatalf("менеджер должен видеть все проекты: %+v", projects.Projects)
+	}
+}
+
+func TestEngineerCannotReachOtherProjectsByID(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+
+	memberProject := createProject(env, manager)
+	otherProject := createProject(env, manager)
+	env.JSON(http.MethodPost, "/api/v1/projects/"+strconv.Itoa(int(memberProject))+"/members", manager.Token,
+		map[string]uint{"user_id": engineer.ID}).ExpectStatus(http.StatusCreated)
+
+	memberDefect := createDefect(env, manager, memberProject, "Трещина в перекрытии")
+	otherDefect := createDefect(env, manager, otherProject, "Протечка в подвале")
+
+	var comment struct {
+		Comment struct {
+			ID uint `json:"id"`
+		} `json:"comment"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/defects/"+strconv.Itoa(int(otherDefect))+"/comments", manager.Token,
+		map[string]string{"text": "Нужна экспертиза"}).ExpectStatus(http.StatusCreated).Decode(&comment)
+
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeProject, otherProject, "план.pdf", "application/pdf", []byte("%PDF-1.4 план")).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+
+	other := "/api/v1/defects/" + strconv.Itoa(int(otherDefect))
+	update := map[string]string{"title": "Протечка устранена", "status": "in_progress", "priority": "high"}
+	denied := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodGet, "/api/v1/projects/" + strconv.Itoa(int(otherProject)), nil},
+		{http.MethodGet, "/api/v1/projects/" + strconv.Itoa(int(otherProject)) + "/members", nil},
+		{http.MethodGet, "/api/v1/projects/" + strconv.Itoa(int(otherProject)) + "/files", nil},
+		{http.MethodGet, other, nil},
+		{http.MethodPut, other, update},
+		{http.MethodGet, other + "/assignments", nil},
+		{http.MethodGet, other + "/comments", nil},
+		{http.MethodPost, other + "/comments", map[string]string{"text": "Чужой комментарий"}},
+		{http.MethodGet, "/api/v1/comments/" + strconv.Itoa(int(comment.Comment.ID)), nil},
+		{http.MethodPut, "/api/v1/comments/" + strconv.Itoa(int(comment.Comment.ID)), map[string]string{"text": "Изменено"}},
+		{http.MethodGet, "/api/v1/files/" + strconv.Itoa(int(uploaded.UploadedFiles[0].ID)), nil},
+		{http.MethodGet, "/api/v1/files?entity_type=project&entity_id=" + strconv.Itoa(int(otherProject)), nil},
+		{http.MethodPost, "/api/v1/defects", map[string]interface{}{"project_id": otherProject, "title": "Чужой дефект"}},
+	}
+	for _, r := range denied {
+		env.JSON(r.method, r.path, engineer.Token, r.body).ExpectStatus(http.StatusNotFound)
+	}
+
+	env.Do(http.MethodPatch, other, engineer.Token, "application/merge-patch+json",
+		strings.NewReader(`{"title": "Протечка устранена"}`)).ExpectStatus(http.StatusNotFound)
+	env.Upload(engineer.Token, models.EntityTypeDefect, otherDefect, "фото.pdf", "application/pdf", []byte("%PDF-1.4")).
+		ExpectStatus(http.StatusNotFound)
+
+	// Дефект и файл чужого проекта не изменены
+	var defect struct {
+		Defect struct {
+			Title string `json:"title"`
+		} `json:"defect"`
+	}
+	env.JSON(http.MethodGet, other, manager.Token, nil).ExpectStatus(http.StatusOK).Decode(&defect)
+	if defect.Defect.Title != "Протечка в подвале" {
+		t.Fatalf("инженер изменил дефект чужого проекта: %q", defect.Defect.Title)
+	}
+
+	// Записи своего проекта доступны как прежде
+	mine := "/api/v1/defects/" + strconv.Itoa(int(memberDefect))
+	env.JSON(http.MethodGet, "/api/v1/projects/"+strconv.Itoa(int(memberProject)), engineer.Token, nil).ExpectStatus(http.StatusOK)
+	env.JSON(http.MethodGet, mine, engineer.Token, nil).ExpectStatus(http.StatusOK)
+	env.JSON(http.MethodPut, mine, engineer.Token, update).ExpectStatus(http.StatusOK)
+	env.JSON(http.MethodPost, mine+"/comments", engineer.Token, map[string]string{"text": "Принято"}).
+		ExpectStatus(http.StatusCreated)
+	createDefect(env, engineer, memberProject, "Скол плитки")
+}
@@ -0,0 +1,116 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	env := testenv.New(t)
+	user := env.Register(models.RoleEngineer)
+
+	token := env.Login(user.Email, user.Password)
+	if token == "" {
+		t.Fatal("вход не вернул токен")
+	}
+
+	var profile struct {
+		User struct {
+			ID    uint   `json:"id"`
+			Email string `json:"email"`
+			Role  struct {
+				Code string `json:"code"`
+			} `json:"role"`
+		} `json:"user"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/profile", token, nil).ExpectStatus(http.StatusOK).Decode(&profile)
+	if profile.User.ID != user.ID || profile.User.Email != user.Email || profile.User.Role.Code != models.RoleEngineer {
+		t.Fatalf("неверный профиль: %+v", profile.User)
+	}
+}
+
+func TestRegisterRejectsDuplicateEmail(t *testing.T) {
+	env := testenv.New(t)
+	user := env.Register(models.RoleObserver)
+
+	env.JSON(http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"email":      user.Email,
+		"password":   "another-password",
+		"first_name": "Повтор",
+		"last_name":  "Повтор",
+		"role_code":  models.RoleObserver,
+	}).ExpectStatus(http.StatusBadRequest)
+}
+
+func TestRegisterValidatesInput(t *testing.T) {
+	env := testenv.New(t)
+
+	env.JSON(http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"email":      "admin@example.com",
+		"password":   "password123",
+		"first_name": "Иван",
+		"last_name":  "Иванов",
+		"role_code":  "admin",
+	}).ExpectStatus(http.StatusBadRequest)
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	env := testenv.New(t)
+	user := env.Register(models.RoleManager)
+
+	env.JSON(http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    user.Email,
+		"password": "wrong-password",
+	}).ExpectStatus(http.StatusUnauthorized)
+
+	env.JSON(http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    "nobody@example.com",
+		"password": user.Password,
+	}).ExpectStatus(http.StatusUnauthorized)
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	env := testenv.New(t)
+
+	env.JSON(http.MethodGet, "/api/v1/profile", "", nil).ExpectStatus(http.StatusUnauthorized)
+	env.JSON(http.MethodGet, "/api/v1/profile", "not-a-jwt", nil).ExpectStatus(http.StatusUnauthorized)
+
+	resp := env.Do(http.MethodGet, "/api/v1/projects", "", "", nil)
+	resp.ExpectStatus(http.StatusUnauthorized)
+}
+
+func TestRoleRestrictions(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+	observer := env.Register(models.RoleObserver)
+
+	newProject := map[string]string{"name": "Жилой комплекс", "address": "ул. Ленина, 1"}
+	newDefect := map[string]interface{}{"project_id": 1, "title": "Трещина в стене"}
+
+	tests := []struct {
+		name   string
+		user   *testenv.User
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"менеджер видит пользователей", manager, http.MethodGet, "/api/v1/users", nil, http.StatusOK},
+		{"инженер не видит пользователей", engineer, http.MethodGet, "/api/v1/users", nil, http.StatusForbidden},
+		{"наблюдатель не видит пользователей", observer, http.MethodGet, "/api/v1/users", nil, http.StatusForbidden},
+		{"инженер не создает проекты", engineer, http.MethodPost, "/api/v1/projects", newProject, http.StatusForbidden},
+		{"наблюдатель не создает проекты", observer, http.MethodPost, "/api/v1/projects", newProject, http.StatusForbidden},
+		{"наблюдатель не создает дефекты", observer, http.MethodPost, "/api/v1/defects", newDefect, http.StatusForbidden},
+		{"инженер не видит аналитику", engineer, http.MethodGet, "/api/v1/stats", nil, http.StatusForbidden},
+		{"все видят список проектов", observer, http.MethodGet, "/api/v1/projects", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if resp := env.JSON(tt.method, tt.path, tt.user.Token, tt.body); resp.Code != tt.status {
+			t.Errorf("%s: ожидался код %d, получен %d; тело: %s", tt.name, tt.status, resp.Code, resp.Body.String())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package router_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"SystemContorlBackend/internal/database"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+func TestCreateCategoryRestoresDeleted(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+
+	var created struct {
+		Category struct {
+			ID   uint   `json:"id"`
+			Name string `json:"name"`
+		} `json:"category"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/catalog/categories", manager.Token, map[string]string{
+		"name": "Фасадные работы",
+		"code": "facade",
+	}).ExpectStatus(http.StatusCreated).Decode(&created)
+
+	// Действующая запись с тем же кодом - конфликт
+	env.JSON(http.MethodPost, "/api/v1/catalog/categories", manager.Token, map[string]string{
+		"name": "Фасады",
+		"code": "facade",
+	}).ExpectStatus(http.StatusConflict)
+
+	env.JSON(http.MethodDelete, "/api/v1/catalog/categories/"+strconv.Itoa(int(created.Category.ID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+
+	// Удаленная запись восстанавливается с новыми значениями
+	var restored struct {
+		Category struct {
+			ID   uint   `json:"id"`
+			Name string `json:"name"`
+		} `json:"category"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/catalog/categories", manager.Token, map[string]string{
+		"name": "Фасады",
+		"code": "facade",
+	}).ExpectStatus(http.StatusCreated).Decode(&restored)
+	if restored.Category.ID != created.Category.ID || restored.Category.Name != "Фасады" {
+		t.Fatalf("категория не восстановлена: %+v", restored.Category)
+	}
+}
+
+func TestSeedSkipsDeletedCatalogEntries(t *testing.T) {
+	env := testenv.New(t)
+
+	if err := env.DB.Where("code = ?", models.SeverityLow).Delete(&models.SeverityLevel{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Повторный запуск не падает на уникальном индексе и не возвращает удаленную запись
+	if err := database.Seed(env.DB); err != nil {
+		t.Fatalf("повторное заполнение справочников: %v", err)
+	}
+
+	var count int64
+	env.DB.Model(&models.SeverityLevel{}).Where("code = ?", models.SeverityLow).Count(&count)
+	if count != 0 {
+		t.Fatalf("удаленный уровень критичности восстановлен при заполнении")
+	}
+}
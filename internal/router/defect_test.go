@@ -0,0 +1,291 @@
+package router_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+// createDefect регистрирует дефект в проекте и возвращает его ID
+func createDefect(env *testenv.Env, user *testenv.User, projectID uint, title string) uint {
+	var body struct {
+		Defect struct {
+			ID uint `json:"id"`
+		} `json:"defect"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/defects", user.Token, map[string]interface{}{
+		"project_id": projectID,
+		"title":      title,
+	}).ExpectStatus(http.StatusCreated).Decode(&body)
+	return body.Defect.ID
+}
+
+func TestDeleteProjectDeletesDefects(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	projectID := createProject(env, manager)
+	defectID := createDefect(env, manager, projectID, "Трещина в стяжке")
+	otherDefectID := createDefect(env, manager, createProject(env, manager), "Скол плитки")
+
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeDefect, defectID, "скол.png", "image/png", []byte("\x89PNG")).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+	stored := filepath.Join(env.UploadDir, models.EntityTypeDefect, uploaded.UploadedFiles[0].FileName)
+
+	env.JSON(http.MethodDelete, "/api/v1/projects/"+strconv.Itoa(int(projectID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+
+	env.JSON(http.MethodGet, "/api/v1/defects/"+strconv.Itoa(int(defectID)), manager.Token, nil).
+		ExpectStatus(http.StatusNotFound)
+	var list struct {
+		Defects []struct {
+			ID uint `json:"id"`
+		} `json:"defects"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/defects", manager.Token, nil).ExpectStatus(http.StatusOK).Decode(&list)
+	if len(list.Defects) != 1 || list.Defects[0].ID != otherDefectID {
+		t.Fatalf("в списке должен остаться только дефект другого проекта: %+v", list.Defects)
+	}
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Fatalf("файл дефекта остался на диске: %v", err)
+	}
+}
+
+func TestDeleteDefectRemovesFiles(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	defectID := createDefect(env, manager, createProject(env, manager), "Отслоение штукатурки")
+
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeDefect, defectID, "стена.png", "image/png", []byte("\x89PNG")).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+	// Файл лежит в настроенном каталоге загрузок, а не в каталоге по умолчанию
+	stored := filepath.Join(env.UploadDir, models.EntityTypeDefect, uploaded.UploadedFiles[0].FileName)
+
+	env.JSON(http.MethodDelete, "/api/v1/defects/"+strconv.Itoa(int(defectID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Fatalf("файл дефекта остался на диске: %v", err)
+	}
+}
+
+func TestUpdateDefectReplacesAllFields(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	projectID := createProject(env, manager)
+
+	var created struct {
+		Defect struct {
+			ID uint `json:"id"`
+		} `json:"defect"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/defects", manager.Token, map[string]interface{}{
+		"project_id":  projectID,
+		"title":       "Протечка кровли",
+		"description": "Над квартирой 12",
+		"location":    "Секция 2",
+		"category_id": 1,
+		"severity_id": 1,
+	}).ExpectStatus(http.StatusCreated).Decode(&created)
+	path := "/api/v1/defects/" + strconv.Itoa(int(created.Defect.ID))
+
+	// Без обязательных полей PUT отклоняется
+	env.JSON(http.MethodPut, path, manager.Token, map[string]string{"title": "Протечка"}).
+		ExpectStatus(http.StatusBadRequest)
+
+	var updated struct {
+		Defect struct {
+			Title       string  `json:"title"`
+			Description string  `json:"description"`
+			Location    string  `json:"location"`
+			CategoryID  *uint   `json:"category_id"`
+			SeverityID  *uint   `json:"severity_id"`
+			DueDate     *string `json:"due_date"`
+			Status      string  `json:"status"`
+		} `json:"defect"`
+	}
+	env.JSON(http.MethodPut, path, manager.Token, map[string]string{
+		"title":    "Протечка кровли устранена частично",
+		"status":   models.DefectStatusInProgress,
+		"priority": "high",
+	}).ExpectStatus(http.StatusOK).Decode(&updated)
+
+	defect := updated.Defect
+	if defect.Title != "Протечка кровли устранена частично" || defect.Status != models.DefectStatusInProgress {
+		t.Fatalf("дефект не обновлен: %+v", defect)
+	}
+	if defect.Description != "" || defect.Location != "" || defect.CategoryID != nil || defect.SeverityID != nil {
+		t.Fatalf("непереданные поля не очищены: %+v", defect)
+	}
+	if defect.DueDate != nil {
+		t.Fatalf("срок без критичности должен очищаться: %v", *defect.DueDate)
+	}
+}
+
+func TestDefectUpdateErrorStatuses(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	path := "/api/v1/defects/" + strconv.Itoa(int(createDefect(env, manager, createProject(env, manager), "Скол ступени")))
+	fields := map[string]interface{}{"title": "Скол ступени", "status": models.DefectStatusNew, "priority": "normal"}
+
+	// Отсутствующий дефект - 404 и для PUT, и для PATCH
+	env.JSON(http.MethodPut, "/api/v1/defects/999999", manager.Token, fields).ExpectStatus(http.StatusNotFound)
+	env.JSON(http.MethodPatch, "/api/v1/defects/999999", manager.Token, map[string]string{"title": "Скол"}).
+		ExpectStatus(http.StatusNotFound)
+
+	// Несуществующие справочники - ошибка данных запроса, а не сервера
+	for _, field := range []string{"category_id", "severity_id"} {
+		invalid := map[string]interface{}{field: 999999}
+		for key, value := range fields {
+			invalid[key] = value
+		}
+		env.JSON(http.MethodPut, path, manager.Token, invalid).ExpectStatus(http.StatusBadRequest)
+		env.JSON(http.MethodPatch, path, manager.Token, map[string]interface{}{field: 999999}).
+			ExpectStatus(http.StatusBadRequest)
+	}
+	fields["norm_ids"] = []uint{999999}
+	env.JSON(http.MethodPut, path, manager.Token, fields).ExpectStatus(http.StatusBadRequest)
+
+	// Дефект в отсутствующем проекте
+	env.JSON(http.MethodPost, "/api/v1/defects", manager.Token, map[string]interface{}{
+		"project_id": 999999,
+		"title":      "Скол",
+	}).ExpectStatus(http.StatusNotFound)
+}
+
+func TestDeleteDefectAndProjectRemoveDependents(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+	projectID := createProject(env, manager)
+	defectID := createDefect(env, manager, projectID, "Трещина в плите")
+	projectDefectID := createDefect(env, manager, projectID, "Протечка в подвале")
+
+	for _, id := range []uint{defectID, projectDefectID} {
+		path := "/api/v1/defects/" + strconv.Itoa(int(id))
+		env.JSON(http.MethodPost, path+"/comments", manager.Token, map[string]string{"text": "Проверить на объекте"}).
+			ExpectStatus(http.StatusCreated)
+		env.JSON(http.MethodPost, path+"/assign", manager.Token, map[string]uint{"user_id": engineer.ID}).
+			ExpectStatus(http.StatusOK)
+	}
+
+	// count считает записи дефекта; deleted - мягко удаленные
+	count := func(model interface{}, id uint, deleted bool) int64 {
+		var n int64
+		query := env.DB.Unscoped().Model(model).Where("defect_id = ?", id)
+		if deleted {
+			query = query.Where("deleted_at IS NOT NULL")
+		}
+		query.Count(&n)
+		return n
+	}
+	notifications := func(id uint) int64 {
+		var n int64
+		env.DB.Model(&models.Notification{}).Where("entity_type = ? AND entity_id = ?", models.EntityTypeDefect, id).Count(&n)
+		return n
+	}
+	if count(&models.DefectAssignment{}, defectID, false) != 1 || notifications(defectID) == 0 {
+		t.Fatal("назначение дефекта не записано")
+	}
+
+	env.JSON(http.MethodDelete, "/api/v1/defects/"+strconv.Itoa(int(defectID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+	env.JSON(http.MethodDelete, "/api/v1/projects/"+strconv.Itoa(int(projectID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+
+	for _, id := range []uint{defectID, projectDefectID} {
+		// Комментарии удаляются мягко, чтобы синхронизация передала их удаление
+		if count(&models.Comment{}, id, false) != 1 || count(&models.Comment{}, id, true) != 1 {
+			t.Errorf("комментарий дефекта %d не удален мягко", id)
+		}
+		if n := count(&models.DefectAssignment{}, id, false); n != 0 {
+			t.Errorf("осталось %d назначений дефекта %d", n, id)
+		}
+		if n := notifications(id); n != 0 {
+			t.Errorf("осталось %d уведомлений о дефекте %d", n, id)
+		}
+	}
+}
+
+func TestDefectNormsIgnoreRepeatedIDs(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+
+	var created struct {
+		Defect struct {
+			ID    uint `json:"id"`
+			Norms []struct {
+				ID uint `json:"id"`
+			} `json:"norms"`
+		} `json:"defect"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/defects", manager.Token, map[string]interface{}{
+		"project_id": createProject(env, manager),
+		"title":      "Неровная стяжка",
+		"norm_ids":   []uint{1, 1},
+	}).ExpectStatus(http.StatusCreated).Decode(&created)
+	if len(created.Defect.Norms) != 1 {
+		t.Fatalf("ожидался один норматив: %+v", created.Defect.Norms)
+	}
+
+	env.JSON(http.MethodPatch, "/api/v1/defects/"+strconv.Itoa(int(created.Defect.ID)), manager.Token,
+		map[string]interface{}{"norm_ids": []uint{2, 1, 2}}).ExpectStatus(http.StatusOK)
+}
+
+func TestDefectListFieldsMatchFullRecord(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+
+	var created struct {
+		Defect struct {
+			ID uint `json:"id"`
+		} `json:"defect"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/defects", manager.Token, map[string]interface{}{
+		"project_id":  createProject(env, manager),
+		"title":       "Трещина в стяжке",
+		"category_id": 1,
+		"severity_id": 2,
+		"norm_ids":    []uint{1},
+	}).ExpectStatus(http.StatusCreated).Decode(&created)
+	env.JSON(http.MethodPost, "/api/v1/defects/"+strconv.Itoa(int(created.Defect.ID))+"/assign", manager.Token,
+		map[string]interface{}{"user_id": engineer.ID}).ExpectStatus(http.StatusOK)
+
+	list := func(query string) map[string]interface{} {
+		var body struct {
+			Defects []map[string]interface{} `json:"defects"`
+		}
+		env.JSON(http.MethodGet, "/api/v1/defects?"+query, manager.Token, nil).ExpectStatus(http.StatusOK).Decode(&body)
+		if len(body.Defects) != 1 {
+			t.Fatalf("%s: получено %d дефектов", query, len(body.Defects))
+		}
+		return body.Defects[0]
+	}
+	full := list("")
+
+	// Каждое поле, выбранное отдельно (в том числе связи и вычисляемое состояние SLA),
+	// совпадает с полем полной записи, а лишних полей в ответе нет
+	for _, name := range []string{"title", "status", "category", "severity", "norms", "assignee_user",
+		"assignee_user_id", "due_date", "sla_state", "creator", "created_at"} {
+		for _, query := range []string{"fields=" + name, "fields=" + name + "&sort=-due_date&cursor="} {
+			item := list(query)
+			if len(item) != 2 || !reflect.DeepEqual(item["id"], full["id"]) || !reflect.DeepEqual(item[name], full[name]) {
+				t.Errorf("%s: получено %v, ожидалось %v", query, item, full[name])
+			}
+		}
+	}
+}
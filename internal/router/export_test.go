@@ -0,0 +1,61 @@
+package router_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+// exportNames возвращает значения колонки "Название" из CSV-выгрузки
+func exportNames(t *testing.T, env *testenv.Env, token, path string) []string {
+	t.Helper()
+	resp := env.JSON(http.MethodGet, path, token, nil).ExpectStatus(http.StatusOK)
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(resp.Body.String(), "\xEF\xBB\xBF")))
+	reader.Comma = ';'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("выгрузка не CSV: %v", err)
+	}
+
+	var names []string
+	for _, row := range rows[1:] {
+		names = append(names, row[1])
+	}
+	return names
+}
+
+func TestExportProjectsUsesListQuery(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	for _, name := range []string{"Бизнес-центр", "Автосалон", "Вокзал"} {
+		env.JSON(http.MethodPost, "/api/v1/projects", manager.Token, map[string]string{"name": name}).
+			ExpectStatus(http.StatusCreated)
+	}
+
+	names := exportNames(t, env, manager.Token, "/api/v1/projects/export?format=csv&sort=name&name[ne]=Вокзал")
+	if strings.Join(names, ",") != "Автосалон,Бизнес-центр" {
+		t.Fatalf("выгрузка не учитывает фильтр и сортировку списка: %v", names)
+	}
+
+	// Сохраненное представление подставляет те же параметры
+	var created struct {
+		View struct {
+			ID uint `json:"id"`
+		} `json:"view"`
+	}
+	env.JSON(http.MethodPost, "/api/v1/views", manager.Token, map[string]interface{}{
+		"entity_type": models.SavedViewEntityProject,
+		"name":        "Без вокзала",
+		"query":       "sort=-name&name[ne]=Вокзал",
+	}).ExpectStatus(http.StatusCreated).Decode(&created)
+
+	names = exportNames(t, env, manager.Token, "/api/v1/projects/export?format=csv&view="+strconv.Itoa(int(created.View.ID)))
+	if strings.Join(names, ",") != "Бизнес-центр,Автосалон" {
+		t.Fatalf("выгрузка не учитывает сохраненное представление: %v", names)
+	}
+}
@@ -0,0 +1,162 @@
+package router_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+// uploadBody - ответ на загрузку файлов
+type uploadBody struct {
+	UploadedFiles []models.AttachmentResponse `json:"uploaded_files"`
+	Errors        []string                    `json:"errors"`
+}
+
+// createProject создает проект от имени менеджера и возвращает его ID
+func createProject(env *testenv.Env, manager *testenv.User) uint {
+	var body projectBody
+	env.JSON(http.MethodPost, "/api/v1/projects", manager.Token, map[string]string{"name": "Складской комплекс"}).
+		ExpectStatus(http.StatusCreated).Decode(&body)
+	return body.Project.ID
+}
+
+func TestFileUploadDownloadDelete(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+	projectID := createProject(env, manager)
+	env.JSON(http.MethodPost, "/api/v1/projects/"+strconv.Itoa(int(projectID))+"/members", manager.Token,
+		map[string]uint{"user_id": engineer.ID}).ExpectStatus(http.StatusCreated)
+
+	content := []byte("%PDF-1.4 акт осмотра")
+	var uploaded uploadBody
+	env.Upload(engineer.Token, models.EntityTypeProject, projectID, "акт.pdf", "application/pdf", content).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 || len(uploaded.Errors) != 0 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+	file := uploaded.UploadedFiles[0]
+	if file.OriginalName != "акт.pdf" || file.FileType != models.FileTypeDocument || file.UploadedBy != engineer.ID {
+		t.Fatalf("неверные данные файла: %+v", file)
+	}
+
+	// Файл сохраняется в каталог загрузок теста
+	stored := filepath.Join(env.UploadDir, models.EntityTypeProject, file.FileName)
+	if _, err := os.Stat(stored); err != nil {
+		t.Fatalf("файл не сохранен на диск: %v", err)
+	}
+
+	var list struct {
+		Files []map[string]interface{} `json:"files"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/projects/"+strconv.Itoa(int(projectID))+"/files", manager.Token, nil).
+		ExpectStatus(http.StatusOK).Decode(&list)
+	if len(list.Files) != 1 {
+		t.Fatalf("ожидался один файл проекта, получено %d", len(list.Files))
+	}
+
+	download := env.Do(http.MethodGet, file.URL, manager.Token, "", nil).ExpectStatus(http.StatusOK)
+	if !bytes.Equal(download.Body.Bytes(), content) {
+		t.Fatalf("скачан другой файл: %q", download.Body.String())
+	}
+	if contentType := download.Header().Get("Content-Type"); contentType != "application/pdf" {
+		t.Fatalf("неверный Content-Type: %q", contentType)
+	}
+
+	// Удалить файл может только загрузивший его пользователь
+	env.JSON(http.MethodDelete, file.URL, manager.Token, nil).ExpectStatus(http.StatusForbidden)
+	env.JSON(http.MethodDelete, file.URL, engineer.Token, nil).ExpectStatus(http.StatusOK)
+
+	env.Do(http.MethodGet, file.URL, manager.Token, "", nil).ExpectStatus(http.StatusNotFound)
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Fatalf("файл остался на диске: %v", err)
+	}
+}
+
+func TestFileUploadRejectsUnsupportedType(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	projectID := createProject(env, manager)
+
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeProject, projectID, "script.sh", "application/x-sh", []byte("echo")).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 0 || len(uploaded.Errors) != 1 {
+		t.Fatalf("неподдерживаемый файл принят: %+v", uploaded)
+	}
+}
+
+func TestDeleteProjectRemovesFiles(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	projectID := createProject(env, manager)
+
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeProject, projectID, "фасад.png", "image/png", []byte("\x89PNG")).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+	stored := filepath.Join(env.UploadDir, models.EntityTypeProject, uploaded.UploadedFiles[0].FileName)
+
+	env.JSON(http.MethodDelete, "/api/v1/projects/"+strconv.Itoa(int(projectID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+
+	env.Do(http.MethodGet, uploaded.UploadedFiles[0].URL, manager.Token, "", nil).ExpectStatus(http.StatusNotFound)
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Fatalf("файл проекта остался на диске: %v", err)
+	}
+}
+
+func TestFileDownloadOutlivesWriteTimeout(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	projectID := createProject(env, manager)
+
+	content := []byte("%PDF-1.4 исполнительная документация")
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeProject, projectID, "схема.pdf", "application/pdf", content).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+
+	// Задержка перед обработчиком имитирует передачу, которая длится дольше таймаута записи
+	const writeTimeout = 100 * time.Millisecond
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * writeTimeout)
+		env.Router.ServeHTTP(w, r)
+	}))
+	server.Config.WriteTimeout = writeTimeout
+	server.Start()
+	defer server.Close()
+
+	get := func(path string) ([]byte, error) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+manager.Token)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	}
+
+	body, err := get("/api/v1/files/" + strconv.Itoa(int(uploaded.UploadedFiles[0].ID)))
+	if err != nil || !bytes.Equal(body, content) {
+		t.Fatalf("файл не скачан после таймаута записи: %v", err)
+	}
+	// Обычные ответы по-прежнему ограничены таймаутом
+	if _, err := get("/api/v1/projects"); err == nil {
+		t.Fatalf("ответ списка проектов не ограничен таймаутом записи")
+	}
+}
@@ -0,0 +1,91 @@
+package router_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"SystemContorlBackend/internal/testenv"
+)
+
+// readyBody - ответ /readyz
+type readyBody struct {
+	Status string `json:"status"`
+	Checks []struct {
+		Name  string `json:"name"`
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	} `json:"checks"`
+}
+
+// failedChecks возвращает названия непройденных проверок
+func (b readyBody) failedChecks() map[string]bool {
+	failed := map[string]bool{}
+	for _, check := range b.Checks {
+		if !check.OK {
+			failed[check.Name] = true
+		}
+	}
+	return failed
+}
+
+func TestHealthz(t *testing.T) {
+	env := testenv.New(t)
+	env.Do(http.MethodGet, "/healthz", "", "", nil).ExpectStatus(http.StatusOK)
+}
+
+func TestReadyz(t *testing.T) {
+	env := testenv.New(t)
+
+	var body readyBody
+	env.Do(http.MethodGet, "/readyz", "", "", nil).ExpectStatus(http.StatusOK).Decode(&body)
+	if body.Status != "ok" || len(body.failedChecks()) != 0 {
+		t.Fatalf("неожиданный ответ: %+v", body)
+	}
+}
+
+func TestReadyzFailsWhenUploadsNotWritable(t *testing.T) {
+	env := testenv.New(t)
+
+	// Каталог загрузок подменяется файлом - записать в него нельзя
+	if err := os.RemoveAll(env.UploadDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(env.UploadDir, []byte("not a directory"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(env.UploadDir) })
+
+	var body readyBody
+	env.Do(http.MethodGet, "/readyz", "", "", nil).ExpectStatus(http.StatusServiceUnavailable).Decode(&body)
+	if failed := body.failedChecks(); !failed["uploads"] || failed["database"] {
+		t.Fatalf("ожидалась ошибка только каталога загрузок: %+v", body)
+	}
+}
+
+func TestReadyzFailsWhenDatabaseClosed(t *testing.T) {
+	env := testenv.New(t)
+	sqlDB, err := env.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+
+	var body readyBody
+	env.Do(http.MethodGet, "/readyz", "", "", nil).ExpectStatus(http.StatusServiceUnavailable).Decode(&body)
+	if !body.failedChecks()["database"] {
+		t.Fatalf("ожидалась ошибка БД: %+v", body)
+	}
+}
+
+func TestReadyzFailsWhileDraining(t *testing.T) {
+	env := testenv.New(t)
+	env.Health.StartDraining()
+
+	var body readyBody
+	env.Do(http.MethodGet, "/readyz", "", "", nil).ExpectStatus(http.StatusServiceUnavailable).Decode(&body)
+	if !body.failedChecks()["shutdown"] {
+		t.Fatalf("ожидался отказ во время остановки: %+v", body)
+	}
+	env.Do(http.MethodGet, "/healthz", "", "", nil).ExpectStatus(http.StatusOK)
+}
@@ -0,0 +1,54 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+func TestIdempotentRequestReplaysResponse(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/projects", strings.NewReader(`{"name":"Жилой дом"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+manager.Token)
+		req.Header.Set("Idempotency-Key", "create-project-1")
+		recorder := httptest.NewRecorder()
+		env.Router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	first := send()
+	if first.Code != http.StatusCreated {
+		t.Fatalf("ожидался код 201, получен %d; тело: %s", first.Code, first.Body.String())
+	}
+	second := send()
+	if second.Code != http.StatusCreated || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("повтор должен вернуть сохраненный ответ: %d %v", second.Code, second.Header())
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("тело повтора отличается: %s", second.Body.String())
+	}
+
+	var count int64
+	env.DB.Model(&models.Project{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("проект создан %d раз", count)
+	}
+
+	// Срок хранения берется из сервиса, собранного в testenv (24 часа)
+	var key models.IdempotencyKey
+	if err := env.DB.Where("key = ?", "create-project-1").First(&key).Error; err != nil {
+		t.Fatalf("ключ не сохранен: %v", err)
+	}
+	if ttl := time.Until(key.ExpiresAt); ttl < 23*time.Hour || ttl > 24*time.Hour {
+		t.Fatalf("неверный срок хранения ключа: %v", ttl)
+	}
+}
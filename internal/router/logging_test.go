@@ -0,0 +1,158 @@
+package router_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/middleware"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+// captureLogs направляет журнал в буфер до конца теста
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// logEntries разбирает записи журнала с сообщением msg
+func logEntries(t *testing.T, buf *bytes.Buffer, msg string) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("запись журнала не JSON: %q", line)
+		}
+		if entry["msg"] == msg {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	env := testenv.New(t)
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"заданный клиентом", "client-request-42", true},
+		{"отсутствует", "", false},
+		{"недопустимые символы", "bad id\n", false},
+		{"слишком длинный", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		if tt.incoming != "" {
+			req.Header.Set(middleware.RequestIDHeader, tt.incoming)
+		}
+		resp := httptest.NewRecorder()
+		env.Router.ServeHTTP(resp, req)
+
+		got := resp.Header().Get(middleware.RequestIDHeader)
+		switch {
+		case got == "":
+			t.Errorf("%s: нет X-Request-ID в ответе", tt.name)
+		case tt.keep && got != tt.incoming:
+			t.Errorf("%s: ожидался %q, получен %q", tt.name, tt.incoming, got)
+		case !tt.keep && got == tt.incoming:
+			t.Errorf("%s: идентификатор клиента должен быть заменен", tt.name)
+		}
+	}
+}
+
+func TestAccessLogIncludesUserAndRequestID(t *testing.T) {
+	env := testenv.New(t)
+	user := env.Register(models.RoleEngineer)
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+user.Token)
+	req.Header.Set(middleware.RequestIDHeader, "trace-1")
+	env.Router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logEntries(t, logs, "HTTP-запрос")
+	if len(entries) != 1 {
+		t.Fatalf("ожидалась одна запись о запросе, получено %d:\n%s", len(entries), logs)
+	}
+	entry := entries[0]
+	if entry["request_id"] != "trace-1" || entry["route"] != "/api/v1/profile" || entry["status"] != float64(http.StatusOK) {
+		t.Errorf("неверная запись о запросе: %v", entry)
+	}
+	if entry["user_id"] != float64(user.ID) || entry["role"] != models.RoleEngineer {
+		t.Errorf("в записи нет пользователя: %v", entry)
+	}
+	if _, ok := entry["latency_ms"].(float64); !ok {
+		t.Errorf("в записи нет времени обработки: %v", entry)
+	}
+}
+
+func TestServiceLogsCarryRequestContext(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	projectID := createProject(env, manager)
+
+	var uploaded uploadBody
+	env.Upload(manager.Token, models.EntityTypeProject, projectID, "план.pdf", "application/pdf", []byte("%PDF-1.4")).
+		ExpectStatus(http.StatusOK).Decode(&uploaded)
+	if len(uploaded.UploadedFiles) != 1 {
+		t.Fatalf("файл не загружен: %+v", uploaded)
+	}
+
+	// Файл исчез с диска: сервис предупреждает об этом, но проект удаляется
+	os.Remove(filepath.Join(env.UploadDir, models.EntityTypeProject, uploaded.UploadedFiles[0].FileName))
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/projects/"+strconv.Itoa(int(projectID)), nil)
+	req.Header.Set("Authorization", "Bearer "+manager.Token)
+	req.Header.Set(middleware.RequestIDHeader, "trace-2")
+	resp := httptest.NewRecorder()
+	env.Router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("ожидался код 200, получен %d; тело: %s", resp.Code, resp.Body.String())
+	}
+
+	entries := logEntries(t, logs, "Не удалось удалить файл")
+	if len(entries) != 1 {
+		t.Fatalf("ожидалось предупреждение сервиса, журнал:\n%s", logs)
+	}
+	if entries[0]["request_id"] != "trace-2" || entries[0]["user_id"] != float64(manager.ID) {
+		t.Errorf("предупреждение без контекста запроса: %v", entries[0])
+	}
+}
+
+func TestLogLevelEndpoint(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+	initial := logging.Level()
+	t.Cleanup(func() { logging.SetLevel(initial) })
+
+	env.JSON(http.MethodPut, "/api/v1/log-level", engineer.Token, map[string]string{"level": "debug"}).
+		ExpectStatus(http.StatusForbidden)
+	env.JSON(http.MethodPut, "/api/v1/log-level", manager.Token, map[string]string{"level": "verbose"}).
+		ExpectStatus(http.StatusBadRequest)
+
+	env.JSON(http.MethodPut, "/api/v1/log-level", manager.Token, map[string]string{"level": "debug"}).
+		ExpectStatus(http.StatusOK)
+	body := env.JSON(http.MethodGet, "/api/v1/log-level", manager.Token, nil).ExpectStatus(http.StatusOK).Map()
+	if body["level"] != "debug" || logging.Level() != "debug" {
+		t.Fatalf("уровень не изменился: %v", body)
+	}
+}
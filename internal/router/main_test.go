@@ -0,0 +1,11 @@
+package router_test
+
+import (
+	"testing"
+
+	"SystemContorlBackend/internal/testenv"
+)
+
+func TestMain(m *testing.M) {
+	testenv.Main(m)
+}
@@ -0,0 +1,139 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+// projectBody - проект в ответах API
+type projectBody struct {
+	Project struct {
+		ID          uint   `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Address     string `json:"address"`
+		Status      string `json:"status"`
+		CreatedBy   uint   `json:"created_by"`
+		Version     uint   `json:"version"`
+	} `json:"project"`
+}
+
+func TestProjectCRUD(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	observer := env.Register(models.RoleObserver)
+
+	var created projectBody
+	env.JSON(http.MethodPost, "/api/v1/projects", manager.Token, map[string]string{
+		"name":        "Жилой комплекс «Северный»",
+		"description": "Корпус 1",
+		"address":     "ул. Ленина, 1",
+	}).ExpectStatus(http.StatusCreated).Decode(&created)
+
+	project := created.Project
+	if project.ID == 0 || project.Status != models.ProjectStatusActive || project.CreatedBy != manager.ID {
+		t.Fatalf("неверный созданный проект: %+v", project)
+	}
+	path := "/api/v1/projects/" + strconv.Itoa(int(project.ID))
+
+	var fetched projectBody
+	resp := env.JSON(http.MethodGet, path, observer.Token, nil).ExpectStatus(http.StatusOK)
+	resp.Decode(&fetched)
+	if fetched.Project.Name != project.Name {
+		t.Fatalf("получен другой проект: %+v", fetched.Project)
+	}
+	if etag := resp.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf("неверный ETag: %q", etag)
+	}
+
+	var list struct {
+		Projects []map[string]interface{} `json:"projects"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/projects", observer.Token, nil).ExpectStatus(http.StatusOK).Decode(&list)
+	if len(list.Projects) != 1 {
+		t.Fatalf("ожидался один проект в списке, получено %d", len(list.Projects))
+	}
+
+	var updated projectBody
+	env.JSON(http.MethodPut, path, manager.Token, map[string]string{
+		"name":   "Жилой комплекс «Южный»",
+		"status": models.ProjectStatusSuspended,
+	}).ExpectStatus(http.StatusOK).Decode(&updated)
+	if updated.Project.Name != "Жилой комплекс «Южный»" || updated.Project.Status != models.ProjectStatusSuspended {
+		t.Fatalf("проект не обновлен: %+v", updated.Project)
+	}
+	// PUT - полная замена: непереданные поля очищаются
+	if updated.Project.Description != "" || updated.Project.Address != "" {
+		t.Fatalf("непереданные поля не очищены: %+v", updated.Project)
+	}
+	if updated.Project.Version != 2 {
+		t.Fatalf("версия не увеличилась: %d", updated.Project.Version)
+	}
+
+	env.JSON(http.MethodPut, path, observer.Token, map[string]string{"name": "Чужой"}).
+		ExpectStatus(http.StatusForbidden)
+	env.JSON(http.MethodDelete, path, observer.Token, nil).ExpectStatus(http.StatusForbidden)
+
+	env.JSON(http.MethodDelete, path, manager.Token, nil).ExpectStatus(http.StatusOK)
+	env.JSON(http.MethodGet, path, manager.Token, nil).ExpectStatus(http.StatusNotFound)
+}
+
+func TestCreateProjectValidatesInput(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+
+	env.JSON(http.MethodPost, "/api/v1/projects", manager.Token, map[string]string{"name": "ЖК"}).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestUpdateProjectRequiresFullRecord(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	path := "/api/v1/projects/" + strconv.Itoa(int(createProject(env, manager)))
+
+	// PUT проверяется как создание: без статуса или с коротким названием - 400
+	env.JSON(http.MethodPut, path, manager.Token, map[string]string{"name": "Склад 2"}).
+		ExpectStatus(http.StatusBadRequest)
+	env.JSON(http.MethodPut, path, manager.Token, map[string]string{"name": "ЖК", "status": models.ProjectStatusActive}).
+		ExpectStatus(http.StatusBadRequest)
+
+	// Отсутствующий проект - 404, как и в PATCH
+	env.JSON(http.MethodPut, "/api/v1/projects/999999", manager.Token, map[string]string{"name": "Склад 2", "status": models.ProjectStatusActive}).
+		ExpectStatus(http.StatusNotFound)
+	env.JSON(http.MethodPatch, "/api/v1/projects/999999", manager.Token, map[string]string{"name": "Склад 2"}).
+		ExpectStatus(http.StatusNotFound)
+}
+
+func TestUpdateProjectChecksIfMatch(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+
+	var created projectBody
+	env.JSON(http.MethodPost, "/api/v1/projects", manager.Token, map[string]string{"name": "Бизнес-центр"}).
+		ExpectStatus(http.StatusCreated).Decode(&created)
+	path := "/api/v1/projects/" + strconv.Itoa(int(created.Project.ID))
+
+	env.JSON(http.MethodPut, path, manager.Token, map[string]string{"name": "Бизнес-центр 2", "status": models.ProjectStatusActive}).
+		ExpectStatus(http.StatusOK)
+
+	// Клиент, прочитавший проект до изменения, получает отказ
+	req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"name": "Бизнес-центр 3", "status": "active"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+manager.Token)
+	req.Header.Set("If-Match", `"1"`)
+	resp := httptest.NewRecorder()
+	env.Router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusPreconditionFailed {
+		t.Fatalf("ожидался код 412, получен %d; тело: %s", resp.Code, resp.Body.String())
+	}
+	if etag := resp.Header().Get("ETag"); etag != `"2"` {
+		t.Fatalf("в ответе на конфликт ожидался текущий ETag, получен %q", etag)
+	}
+}
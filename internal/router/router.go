@@ -0,0 +1,195 @@
+// Package router собирает HTTP-маршруты API поверх переданных обработчиков
+package router
+
+import (
+	"SystemContorlBackend/internal/handlers"
+	"SystemContorlBackend/internal/middleware"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// New создает Gin роутер со всеми маршрутами API; tokens проверяет токены защищенных маршрутов,
+// keys хранит ответы на запросы с Idempotency-Key.
+// Используется в main и в тестах, где обработчики собраны над тестовой БД.
+func New(h *handlers.Handler, tokens *services.TokenService, keys *services.IdempotencyService) *gin.Engine {
+	router := gin.New()
+
+	// Идентификатор запроса, журнал запросов и перехват паник
+	router.Use(middleware.RequestID(), middleware.AccessLog(), middleware.Recovery())
+
+	// Добавляем CORS middleware для работы с мобильным приложением
+	router.Use(func(c *gin.Context) {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, Idempotency-Key, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed, X-Request-ID")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	})
+
+	// Проверки для оркестратора (без аутентификации)
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
+
+	// API группа
+	api := router.Group("/api/v1")
+	{
+		// Публичные эндпоинты (без аутентификации)
+		auth := api.Group("/auth")
+		{
+			auth.POST("/register", h.Register)
+			auth.POST("/login", h.Login)
+		}
+
+		// Защищенные эндпоинты (требуют аутентификации)
+		protected := api.Group("/")
+		protected.Use(middleware.AuthMiddleware(tokens), middleware.IdempotencyMiddleware(keys))
+		{
+			// Профиль пользователя
+			protected.GET("/profile", h.GetProfile)
+			protected.GET("/stream", h.StreamEvents)              // Поток событий (SSE)
+			protected.GET("/me/assigned", h.GetMyAssignedDefects) // Дефекты, назначенные мне
+			protected.GET("/search", h.Search)                    // Полнотекстовый поиск
+			protected.GET("/sync", h.GetSyncDelta)                // Изменения для офлайн-клиента
+
+			// Сохраненные представления списков
+			protected.GET("/views", h.GetSavedViews)
+			protected.POST("/views", h.CreateSavedView)
+			protected.GET("/views/counts", h.GetSavedViewCounts) // Количество записей в каждом представлении
+			protected.GET("/views/:id", h.GetSavedView)
+			protected.PUT("/views/:id", h.UpdateSavedView)
+			protected.DELETE("/views/:id", h.DeleteSavedView)
+			protected.POST("/views/:id/pin", h.PinSavedView)
+			protected.DELETE("/views/:id/pin", h.UnpinSavedView)
+
+			// Уведомления
+			protected.GET("/notifications", h.GetNotifications)
+			protected.GET("/notifications/unread-count", h.GetUnreadNotificationsCount)
+			protected.POST("/notifications/:id/read", h.MarkNotificationRead)
+			protected.POST("/notifications/read-all", h.MarkAllNotificationsRead)
+			protected.GET("/notifications/preferences", h.GetNotificationPreferences)
+			protected.PUT("/notifications/preferences", h.UpdateNotificationPreferences)
+
+			// Работа с файлами
+			protected.POST("/files/upload", h.UploadFiles) // Загрузка файлов
+			protected.GET("/files/:id", h.GetFile)         // Скачать файл
+			protected.GET("/files", h.GetEntityFiles)      // Список файлов сущности
+			protected.DELETE("/files/:id", h.DeleteFile)   // Удалить файл
+
+			protected.GET("/projects/:id/files", h.GetProjectFiles)
+			protected.GET("/projects/:id/image", h.GetProjectMainImage)
+
+			// Проекты (доступ для всех авторизованных пользователей)
+			protected.GET("/projects", h.GetProjects)    // Список проектов
+			protected.GET("/projects/:id", h.GetProject) // Один проект
+			protected.GET("/projects/export", h.ExportProjects)
+			protected.GET("/projects/:id/members", h.GetProjectMembers)
+
+			// Дефекты (просмотр для всех авторизованных пользователей)
+			protected.GET("/defects", h.GetDefects)    // Список дефектов
+			protected.GET("/defects/:id", h.GetDefect) // Один дефект
+			protected.GET("/defects/export", h.ExportDefects)
+			protected.GET("/defects/:id/assignments", h.GetDefectAssignments)
+
+			// Комментарии к дефектам
+			protected.GET("/defects/:id/comments", h.GetDefectComments)
+			protected.POST("/defects/:id/comments", h.CreateDefectComment)
+			protected.GET("/comments/:id", h.GetComment)
+			protected.PUT("/comments/:id", h.UpdateComment)
+			protected.DELETE("/comments/:id", h.DeleteComment)
+
+			// Организации
+			protected.GET("/organizations", h.GetOrganizations)
+			protected.GET("/organizations/:id", h.GetOrganization)
+
+			// Справочники дефектов
+			protected.GET("/catalog/categories", h.GetDefectCategories)
+			protected.GET("/catalog/severities", h.GetSeverityLevels)
+			protected.GET("/catalog/norms", h.GetNormReferences)
+			protected.GET("/catalog/holidays", h.GetHolidays)
+
+			// Эндпоинты только для менеджеров
+			manager := protected.Group("/")
+			manager.Use(middleware.RoleMiddleware(models.RoleManager))
+			{
+				// Управление проектами
+				manager.POST("/projects", h.CreateProject)       // Создание проекта
+				manager.PUT("/projects/:id", h.UpdateProject)    // Обновление проекта
+				manager.PATCH("/projects/:id", h.PatchProject)   // Частичное изменение (JSON Merge Patch)
+				manager.DELETE("/projects/:id", h.DeleteProject) // Удаление проекта
+				manager.POST("/projects/:id/members", h.AddProjectMember)
+				manager.DELETE("/projects/:id/members/:user_id", h.RemoveProjectMember)
+				manager.GET("/projects/:id/reports/defects.pdf", h.GetDefectReportPDF) // Акт осмотра
+				manager.POST("/projects/import", h.ImportProjects)
+				manager.POST("/defects/import", h.ImportDefects)
+
+				// Управление дефектами
+				manager.DELETE("/defects/:id", h.DeleteDefect)      // Удаление дефекта
+				manager.POST("/defects/:id/assign", h.AssignDefect) // Назначение исполнителя
+
+				manager.GET("/users", h.GetUsers) // Список пользователей
+
+				// Управление организациями
+				manager.POST("/organizations", h.CreateOrganization)
+				manager.PUT("/organizations/:id", h.UpdateOrganization)
+				manager.DELETE("/organizations/:id", h.DeleteOrganization)
+				manager.POST("/organizations/:id/members", h.AddOrganizationMember)
+				manager.DELETE("/organizations/:id/members/:user_id", h.RemoveOrganizationMember)
+
+				// Управление справочниками
+				manager.POST("/catalog/categories", h.CreateDefectCategory)
+				manager.PUT("/catalog/categories/:id", h.UpdateDefectCategory)
+				manager.DELETE("/catalog/categories/:id", h.DeleteDefectCategory)
+				manager.POST("/catalog/severities", h.CreateSeverityLevel)
+				manager.PUT("/catalog/severities/:id", h.UpdateSeverityLevel)
+				manager.DELETE("/catalog/severities/:id", h.DeleteSeverityLevel)
+				manager.POST("/catalog/norms", h.CreateNormReference)
+				manager.PUT("/catalog/norms/:id", h.UpdateNormReference)
+				manager.DELETE("/catalog/norms/:id", h.DeleteNormReference)
+				manager.POST("/catalog/holidays", h.CreateHoliday)
+				manager.DELETE("/catalog/holidays/:id", h.DeleteHoliday)
+
+				// Исходящие webhook
+				manager.GET("/webhooks", h.GetWebhooks)
+				manager.POST("/webhooks", h.CreateWebhook)
+				manager.GET("/webhooks/:id", h.GetWebhook)
+				manager.PUT("/webhooks/:id", h.UpdateWebhook)
+				manager.DELETE("/webhooks/:id", h.DeleteWebhook)
+				manager.GET("/webhooks/:id/deliveries", h.GetWebhookDeliveries)
+				manager.POST("/webhooks/:id/test", h.TestWebhook)
+
+				// Уровень журнала на работающем сервере
+				manager.GET("/log-level", h.GetLogLevel)
+				manager.PUT("/log-level", h.SetLogLevel)
+			}
+
+			// Эндпоинты для менеджеров и инженеров
+			staff := protected.Group("/")
+			staff.Use(middleware.RoleMiddleware(models.RoleManager, models.RoleEngineer))
+			{
+				// Создание и редактирование дефектов
+				staff.POST("/defects", h.CreateDefect)
+				staff.PUT("/defects/:id", h.UpdateDefect)
+				staff.PATCH("/defects/:id", h.PatchDefect) // Частичное изменение (JSON Merge Patch)
+				staff.POST("/sync", h.PushSyncChanges)     // Изменения, сделанные офлайн
+			}
+
+			// Аналитика для менеджеров и наблюдателей
+			analytics := protected.Group("/")
+			analytics.Use(middleware.RoleMiddleware(models.RoleManager, models.RoleObserver))
+			{
+				analytics.GET("/stats", h.GetPortfolioStats)
+				analytics.GET("/projects/:id/stats", h.GetProjectStats)
+			}
+		}
+	}
+
+	return router
+}
@@ -0,0 +1,102 @@
+package router_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/testenv"
+)
+
+// syncResults - ответ на отправку офлайн-изменений
+type syncResults struct {
+	Results []struct {
+		ID     uint   `json:"id"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"results"`
+}
+
+// pushSync отправляет пакет офлайн-изменений и возвращает результаты
+func pushSync(env *testenv.Env, user *testenv.User, changes ...map[string]interface{}) syncResults {
+	var body syncResults
+	env.JSON(http.MethodPost, "/api/v1/sync", user.Token, map[string]interface{}{"changes": changes}).
+		ExpectStatus(http.StatusOK).Decode(&body)
+	return body
+}
+
+func TestSyncPushUpdatesComments(t *testing.T) {
+	env := testenv.New(t)
+	manager := env.Register(models.RoleManager)
+	engineer := env.Register(models.RoleEngineer)
+	projectID := createProject(env, manager)
+	defectID := createDefect(env, manager, projectID, "Трещина в перемычке")
+	env.JSON(http.MethodPost, "/api/v1/projects/"+strconv.Itoa(int(projectID))+"/members", manager.Token,
+		map[string]uint{"user_id": engineer.ID}).ExpectStatus(http.StatusCreated)
+
+	created := pushSync(env, engineer, map[string]interface{}{
+		"entity":    "comment",
+		"op":        "create",
+		"client_id": "0b7c3f5e-2a4d-4c1e-9f7a-3d2b1c0a9e8f",
+		"data":      map[string]interface{}{"defect_id": defectID, "text": "Нужна фотофиксация"},
+	})
+	commentID := created.Results[0].ID
+	if created.Results[0].Status != models.SyncStatusApplied || commentID == 0 {
+		t.Fatalf("комментарий не создан: %+v", created.Results)
+	}
+
+	var comment struct {
+		Comment struct {
+			Text      string    `json:"text"`
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"comment"`
+	}
+	env.JSON(http.MethodGet, "/api/v1/comments/"+strconv.Itoa(int(commentID)), engineer.Token, nil).
+		ExpectStatus(http.StatusOK).Decode(&comment)
+	base := comment.Comment.UpdatedAt
+
+	updated := pushSync(env, engineer, map[string]interface{}{
+		"entity":          "comment",
+		"op":              "update",
+		"id":              commentID,
+		"base_updated_at": base,
+		"data":            map[string]string{"text": "Фотофиксация выполнена"},
+	})
+	if updated.Results[0].Status != models.SyncStatusApplied {
+		t.Fatalf("изменение комментария не применено: %+v", updated.Results)
+	}
+	env.JSON(http.MethodGet, "/api/v1/comments/"+strconv.Itoa(int(commentID)), engineer.Token, nil).
+		ExpectStatus(http.StatusOK).Decode(&comment)
+	if comment.Comment.Text != "Фотофиксация выполнена" {
+		t.Fatalf("текст комментария не изменен: %q", comment.Comment.Text)
+	}
+
+	// Правка поверх устаревшей версии возвращается как конфликт
+	stale := pushSync(env, engineer, map[string]interface{}{
+		"entity":          "comment",
+		"op":              "update",
+		"id":              commentID,
+		"base_updated_at": base,
+		"data":            map[string]string{"text": "Старый текст"},
+	})
+	if stale.Results[0].Status != models.SyncStatusConflict {
+		t.Fatalf("ожидался конфликт: %+v", stale.Results)
+	}
+
+	// Без доступа к проекту комментарий нельзя ни изменить, ни удалить
+	env.JSON(http.MethodDelete, "/api/v1/projects/"+strconv.Itoa(int(projectID))+"/members/"+strconv.Itoa(int(engineer.ID)),
+		manager.Token, nil).ExpectStatus(http.StatusOK)
+	denied := pushSync(env, engineer,
+		map[string]interface{}{"entity": "comment", "op": "update", "id": commentID, "data": map[string]string{"text": "Чужой проект"}},
+		map[string]interface{}{"entity": "comment", "op": "delete", "id": commentID},
+	)
+	for _, result := range denied.Results {
+		if result.Status != models.SyncStatusError {
+			t.Errorf("изменение комментария недоступного проекта применено: %+v", result)
+		}
+	}
+	env.JSON(http.MethodGet, "/api/v1/comments/"+strconv.Itoa(int(commentID)), manager.Token, nil).
+		ExpectStatus(http.StatusOK)
+}
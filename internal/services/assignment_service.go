@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Assign назначает исполнителя дефекта (инженера и/или организацию) и пишет историю
+func (s *DefectService) Assign(ctx context.Context, defectID uint, input models.DefectAssign, assignedBy uint) (*models.Defect, error) {
+	if input.UserID == nil && input.OrganizationID == nil {
+		return nil, invalidInput("не указан исполнитель")
+	}
+
+	var defect models.Defect
+	if err := s.db.First(&defect, defectID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDefectNotFound
+		}
+		return nil, err
+	}
+
+	if defect.Status == models.DefectStatusClosed || defect.Status == models.DefectStatusCancelled {
+		return nil, invalidInput("нельзя назначить исполнителя закрытому дефекту")
+	}
+
+	orgID := input.OrganizationID
+	if input.UserID != nil {
+		var assignee models.User
+		if err := s.db.Preload("Role").First(&assignee, *input.UserID).Error; err != nil {
+			return nil, invalidInput("исполнитель не найден")
+		}
+		if !assignee.IsActive {
+			return nil, invalidInput("исполнитель заблокирован")
+		}
+		if assignee.Role.Code == models.RoleObserver {
+			return nil, invalidInput("наблюдателю нельзя назначить дефект")
+		}
+
+		// Инженер подрядчика: организация подставляется из его профиля
+		if orgID == nil {
+			orgID = assignee.OrganizationID
+		} else if assignee.OrganizationID == nil || *assignee.OrganizationID != *orgID {
+			return nil, invalidInput("исполнитель не состоит в указанной организации")
+		}
+	}
+
+	if orgID != nil {
+		var organization models.Organization
+		if err := s.db.First(&organization, *orgID).Error; err != nil {
+			return nil, invalidInput("организация не найдена")
+		}
+	}
+
+	assignment := models.DefectAssignment{
+		DefectID:       defect.ID,
+		AssigneeUserID: input.UserID,
+		AssigneeOrgID:  orgID,
+		PreviousUserID: defect.AssigneeUserID,
+		PreviousOrgID:  defect.AssigneeOrgID,
+		Comment:        input.Comment,
+		AssignedBy:     assignedBy,
+	}
+
+	updates := map[string]interface{}{
+		"assignee_user_id": input.UserID,
+		"assignee_org_id":  orgID,
+		"version":          gorm.Expr("version + 1"),
+	}
+	// Срок начинает отсчитываться с момента назначения, если он еще не задан
+	if defect.DueDate == nil {
+		if dueDate := s.sla.CalculateDueDate(time.Now(), defect.SeverityID); dueDate != nil {
+			updates["due_date"] = *dueDate
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Defect{}).Where("id = ?", defect.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(&assignment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.GetByID(defect.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Emit(DomainEvent{
+		Type:       models.EventDefectAssigned,
+		ProjectID:  assigned.ProjectID,
+		EntityType: models.EntityTypeDefect,
+		EntityID:   assigned.ID,
+		ActorID:    assignedBy,
+		Payload:    assigned,
+		Context:    ctx,
+	})
+
+	return assigned, nil
+}
+
+// ListAssignments возвращает историю назначений дефекта
+func (s *DefectService) ListAssignments(defectID uint) ([]models.DefectAssignment, error) {
+	var assignments []models.DefectAssignment
+	err := s.db.Preload("AssigneeUser").Preload("AssigneeOrg").Preload("Assigner").
+		Where("defect_id = ?", defectID).
+		Order("created_at DESC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// AssignedDefectListSchema - поля списка "мои дефекты"; по умолчанию ближайшие сроки сверху
+var AssignedDefectListSchema = DefectListSchema.WithDefaultSort("due_date")
+
+// ListAssigned возвращает дефекты, назначенные пользователю лично или его организации
+func (s *DefectService) ListAssigned(userID uint, status string, options *listquery.Options) ([]models.Defect, *listquery.PageInfo, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, nil, errors.New("пользователь не найден")
+	}
+
+	var defects []models.Defect
+
+	query := s.db.Model(&models.Defect{}).
+		Preload("Project").Preload("Category").Preload("Severity").
+		Preload("AssigneeUser").Preload("AssigneeOrg")
+
+	if user.OrganizationID != nil {
+		query = query.Where("assignee_user_id = ? OR (assignee_user_id IS NULL AND assignee_org_id = ?)",
+			userID, *user.OrganizationID)
+	} else {
+		query = query.Where("assignee_user_id = ?", userID)
+	}
+
+	// По умолчанию показываем только то, что еще нужно устранить
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status NOT IN ?", []string{models.DefectStatusClosed, models.DefectStatusCancelled})
+	}
+
+	page, err := options.Find(query, &defects)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fillSLAStates(defects)
+
+	return defects, page, nil
+}
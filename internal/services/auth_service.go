@@ -3,60 +3,91 @@ package services
 import (
 	"errors"
 
-	"SystemContorlBackend/internal/database"
 	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-// RegisterUser регистрирует нового пользователя
-func RegisterUser(userData models.UserRegister) (*models.User, error) {
+// UserService - регистрация, вход и справочник пользователей
+type UserService struct {
+	users  repository.UserRepository
+	tokens *TokenService
+}
+
+// NewUserService создает сервис пользователей поверх хранилища users
+func NewUserService(users repository.UserRepository, tokens *TokenService) *UserService {
+	return &UserService{users: users, tokens: tokens}
+}
+
+// Register регистрирует нового пользователя и выдает ему токен
+func (s *UserService) Register(userData models.UserRegister) (*models.User, string, error) {
 	// Проверяем, существует ли пользователь с таким email
-	var existingUser models.User
-	if err := database.DB.Where("email = ?", userData.Email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("пользователь с таким email уже существует")
+	exists, err := s.users.EmailExists(userData.Email)
+	if err != nil {
+		return nil, "", err
+	}
+	if exists {
+		return nil, "", errors.New("пользователь с таким email уже существует")
 	}
 
 	// Хешируем пароль с использованием bcrypt согласно ТЗ
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userData.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Находим роль по коду
-	var role models.Role
-	if err := database.DB.Where("code = ?", userData.RoleCode).First(&role).Error; err != nil {
-		return nil, errors.New("указанная роль не найдена")
+	role, err := s.users.FindRoleByCode(userData.RoleCode)
+	if err != nil {
+		return nil, "", errors.New("указанная роль не найдена")
+	}
+
+	// Проверяем организацию, если пользователь указал ее при регистрации
+	if userData.OrganizationID != nil {
+		exists, err := s.users.OrganizationExists(*userData.OrganizationID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !exists {
+			return nil, "", errors.New("указанная организация не найдена")
+		}
 	}
 
 	// Создаем пользователя
 	user := models.User{
-		Email:     userData.Email,
-		Password:  string(hashedPassword),
-		FirstName: userData.FirstName,
-		LastName:  userData.LastName,
-		Phone:     userData.Phone,
-		RoleID:    role.ID,
-		IsActive:  true,
+		Email:          userData.Email,
+		Password:       string(hashedPassword),
+		FirstName:      userData.FirstName,
+		LastName:       userData.LastName,
+		Phone:          userData.Phone,
+		RoleID:         role.ID,
+		IsActive:       true,
+		OrganizationID: userData.OrganizationID,
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
-		return nil, err
+	if err := s.users.Create(&user); err != nil {
+		return nil, "", err
 	}
 
-	// Загружаем роль для ответа
-	database.DB.Preload("Role").First(&user, user.ID)
+	// Роль уже загружена, отдаем ее в ответе
+	user.Role = *role
+
+	// Генерируем токен для нового пользователя
+	token, err := s.tokens.Generate(&user)
+	if err != nil {
+		return nil, "", err
+	}
 
-	return &user, nil
+	return &user, token, nil
 }
 
-// LoginUser выполняет вход пользователя
-func LoginUser(loginData models.UserLogin) (*models.User, string, error) {
-	var user models.User
-	
+// Login выполняет вход пользователя
+func (s *UserService) Login(loginData models.UserLogin) (*models.User, string, error) {
 	// Находим пользователя по email с подгрузкой роли
-	if err := database.DB.Preload("Role").Where("email = ?", loginData.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	user, err := s.users.FindByEmail(loginData.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, "", errors.New("неверный email или пароль")
 		}
 		return nil, "", err
@@ -73,19 +104,15 @@ func LoginUser(loginData models.UserLogin) (*models.User, string, error) {
 	}
 
 	// Генерируем JWT токен
-	token, err := GenerateToken(&user)
+	token, err := s.tokens.Generate(user)
 	if err != nil {
 		return nil, "", err
 	}
 
-	return &user, token, nil
+	return user, token, nil
 }
 
-// GetUserByID получает пользователя по ID
-func GetUserByID(userID uint) (*models.User, error) {
-	var user models.User
-	if err := database.DB.Preload("Role").First(&user, userID).Error; err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
\ No newline at end of file
+// GetByID получает пользователя по ID
+func (s *UserService) GetByID(userID uint) (*models.User, error) {
+	return s.users.FindByID(userID)
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"errors"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Код и название категорий и уровней критичности уникальны с учетом удаленных записей
+var (
+	ErrCategoryExists = errors.New("категория с таким кодом или названием уже существует")
+	ErrSeverityExists = errors.New("уровень критичности с таким кодом или названием уже существует")
+)
+
+// CatalogService - справочники категорий дефектов, уровней критичности и нормативов
+type CatalogService struct {
+	db *gorm.DB
+}
+
+// NewCatalogService создает сервис справочников на подключении db
+func NewCatalogService(db *gorm.DB) *CatalogService {
+	return &CatalogService{db: db}
+}
+
+// GetDefectCategories возвращает все категории дефектов
+func (s *CatalogService) GetDefectCategories() ([]models.DefectCategory, error) {
+	var categories []models.DefectCategory
+	if err := s.db.Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CreateDefectCategory создает категорию дефектов. Удаленная категория с тем же кодом
+// или названием восстанавливается с новыми значениями.
+func (s *CatalogService) CreateDefectCategory(input models.DefectCategoryInput) (*models.DefectCategory, error) {
+	var matches []models.DefectCategory
+	err := s.db.Unscoped().Where("code = ? OR name = ?", input.Code, input.Name).Find(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var category models.DefectCategory
+	switch {
+	case len(matches) > 1 || len(matches) == 1 && !matches[0].DeletedAt.Valid:
+		return nil, ErrCategoryExists
+	case len(matches) == 1:
+		category = matches[0]
+		category.DeletedAt = gorm.DeletedAt{}
+	}
+
+	category.Name = input.Name
+	category.Code = input.Code
+	category.Description = input.Description
+	if err := s.db.Unscoped().Save(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateDefectCategory обновляет категорию дефектов
+func (s *CatalogService) UpdateDefectCategory(id uint, input models.DefectCategoryInput) (*models.DefectCategory, error) {
+	var category models.DefectCategory
+	if err := s.db.First(&category, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("категория не найдена")
+		}
+		return nil, err
+	}
+
+	var taken int64
+	err := s.db.Unscoped().Model(&models.DefectCategory{}).
+		Where("id <> ? AND (code = ? OR name = ?)", id, input.Code, input.Name).Count(&taken).Error
+	if err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		return nil, ErrCategoryExists
+	}
+
+	category.Name = input.Name
+	category.Code = input.Code
+	category.Description = input.Description
+
+	if err := s.db.Save(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// DeleteDefectCategory удаляет категорию, если на нее не ссылаются дефекты
+func (s *CatalogService) DeleteDefectCategory(id uint) error {
+	var count int64
+	s.db.Model(&models.Defect{}).Where("category_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("категория используется в дефектах")
+	}
+
+	result := s.db.Delete(&models.DefectCategory{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("категория не найдена")
+	}
+	return nil
+}
+
+// GetSeverityLevels возвращает уровни критичности, от наименее к наиболее критичному
+func (s *CatalogService) GetSeverityLevels() ([]models.SeverityLevel, error) {
+	var levels []models.SeverityLevel
+	if err := s.db.Order("rank").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// CreateSeverityLevel создает уровень критичности. Удаленный уровень с тем же кодом
+// или названием восстанавливается с новыми значениями.
+func (s *CatalogService) CreateSeverityLevel(input models.SeverityLevelInput) (*models.SeverityLevel, error) {
+	var matches []models.SeverityLevel
+	err := s.db.Unscoped().Where("code = ? OR name = ?", input.Code, input.Name).Find(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var level models.SeverityLevel
+	switch {
+	case len(matches) > 1 || len(matches) == 1 && !matches[0].DeletedAt.Valid:
+		return nil, ErrSeverityExists
+	case len(matches) == 1:
+		level = matches[0]
+		level.DeletedAt = gorm.DeletedAt{}
+	}
+
+	level.Name = input.Name
+	level.Code = input.Code
+	level.Rank = input.Rank
+	level.FixDays = input.FixDays
+	level.Description = input.Description
+	if err := s.db.Unscoped().Save(&level).Error; err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// UpdateSeverityLevel обновляет уровень критичности
+func (s *CatalogService) UpdateSeverityLevel(id uint, input models.SeverityLevelInput) (*models.SeverityLevel, error) {
+	var level models.SeverityLevel
+	if err := s.db.First(&level, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("уровень критичности не найден")
+		}
+		return nil, err
+	}
+
+	var taken int64
+	err := s.db.Unscoped().Model(&models.SeverityLevel{}).
+		Where("id <> ? AND (code = ? OR name = ?)", id, input.Code, input.Name).Count(&taken).Error
+	if err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		return nil, ErrSeverityExists
+	}
+
+	level.Name = input.Name
+	level.Code = input.Code
+	level.Rank = input.Rank
+	level.FixDays = input.FixDays
+	level.Description = input.Description
+
+	if err := s.db.Save(&level).Error; err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// DeleteSeverityLevel удаляет уровень критичности, если на него не ссылаются дефекты
+func (s *CatalogService) DeleteSeverityLevel(id uint) error {
+	var count int64
+	s.db.Model(&models.Defect{}).Where("severity_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("уровень критичности используется в дефектах")
+	}
+
+	result := s.db.Delete(&models.SeverityLevel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("уровень критичности не найден")
+	}
+	return nil
+}
+
+// GetNormReferences возвращает нормативные ссылки с фильтром по типу документа
+func (s *CatalogService) GetNormReferences(documentType string) ([]models.NormReference, error) {
+	var norms []models.NormReference
+	query := s.db.Order("document, clause")
+	if documentType != "" {
+		query = query.Where("document_type = ?", documentType)
+	}
+	if err := query.Find(&norms).Error; err != nil {
+		return nil, err
+	}
+	return norms, nil
+}
+
+// CreateNormReference создает нормативную ссылку
+func (s *CatalogService) CreateNormReference(input models.NormReferenceInput) (*models.NormReference, error) {
+	var existing models.NormReference
+	if err := s.db.Where("document = ? AND clause = ?", input.Document, input.Clause).First(&existing).Error; err == nil {
+		return nil, errors.New("такой пункт нормативного документа уже существует")
+	}
+
+	norm := models.NormReference{
+		DocumentType: input.DocumentType,
+		Document:     input.Document,
+		Clause:       input.Clause,
+		Title:        input.Title,
+	}
+	if err := s.db.Create(&norm).Error; err != nil {
+		return nil, err
+	}
+	return &norm, nil
+}
+
+// UpdateNormReference обновляет нормативную ссылку
+func (s *CatalogService) UpdateNormReference(id uint, input models.NormReferenceInput) (*models.NormReference, error) {
+	var norm models.NormReference
+	if err := s.db.First(&norm, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("нормативная ссылка не найдена")
+		}
+		return nil, err
+	}
+
+	norm.DocumentType = input.DocumentType
+	norm.Document = input.Document
+	norm.Clause = input.Clause
+	norm.Title = input.Title
+
+	if err := s.db.Save(&norm).Error; err != nil {
+		return nil, err
+	}
+	return &norm, nil
+}
+
+// DeleteNormReference удаляет нормативную ссылку вместе с ее привязками к дефектам
+func (s *CatalogService) DeleteNormReference(id uint) error {
+	var norm models.NormReference
+	if err := s.db.First(&norm, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("нормативная ссылка не найдена")
+		}
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM defect_norms WHERE norm_reference_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&norm).Error
+	})
+}
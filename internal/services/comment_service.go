@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Ошибки работы с комментариями
+var (
+	// ErrCommentNotFound - комментария нет или он удален
+	ErrCommentNotFound = errors.New("комментарий не найден")
+	// ErrNotCommentAuthor - изменять и удалять комментарий может только автор
+	ErrNotCommentAuthor = errors.New("недостаточно прав: изменять и удалять комментарий может только автор")
+)
+
+// CommentService - обсуждение дефектов
+type CommentService struct {
+	db     *gorm.DB
+	events *EventBus
+}
+
+// NewCommentService создает сервис комментариев на подключении db; о новых
+// комментариях и упоминаниях сообщается в events
+func NewCommentService(db *gorm.DB, events *EventBus) *CommentService {
+	return &CommentService{db: db, events: events}
+}
+
+// Create добавляет комментарий к дефекту и уведомляет упомянутых пользователей
+func (s *CommentService) Create(ctx context.Context, defectID uint, input models.CommentCreate, authorID uint) (*models.Comment, error) {
+	var defect models.Defect
+	if err := s.db.First(&defect, defectID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDefectNotFound
+		}
+		return nil, err
+	}
+
+	// Повторная отправка комментария, созданного офлайн
+	if input.ClientID != nil {
+		var count int64
+		s.db.Unscoped().Model(&models.Comment{}).Where("client_id = ?", *input.ClientID).Count(&count)
+		if count > 0 {
+			return nil, errors.New("комментарий с таким client_id уже существует")
+		}
+	}
+
+	var mentions []models.User
+	if len(input.MentionIDs) > 0 {
+		if err := s.db.Where("id IN ?", input.MentionIDs).Find(&mentions).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	comment := models.Comment{
+		DefectID: defect.ID,
+		AuthorID: authorID,
+		Text:     input.Text,
+		Mentions: mentions,
+		ClientID: input.ClientID,
+	}
+
+	if err := s.db.Omit("Mentions.*").Create(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	s.db.Preload("Author").Preload("Mentions").First(&comment, comment.ID)
+
+	event := DomainEvent{
+		Type:       models.EventCommentCreated,
+		ProjectID:  defect.ProjectID,
+		EntityType: "comment",
+		EntityID:   comment.ID,
+		ActorID:    authorID,
+		Payload:    &comment,
+		Context:    ctx,
+	}
+	s.events.Emit(event)
+
+	if len(comment.Mentions) > 0 {
+		event.Type = models.EventCommentMentioned
+		s.events.Emit(event)
+	}
+
+	return &comment, nil
+}
+
+// ListByDefect получает комментарии дефекта в хронологическом порядке
+func (s *CommentService) ListByDefect(defectID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := s.db.Preload("Author").Preload("Mentions").
+		Where("defect_id = ?", defectID).
+		Order("created_at ASC").
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Delete удаляет комментарий (только автор)
+func (s *CommentService) Delete(id uint, userID uint) error {
+	var comment models.Comment
+	if err := s.db.First(&comment, id).Error; err != nil {
+		return ErrCommentNotFound
+	}
+
+	if comment.AuthorID != userID {
+		return ErrNotCommentAuthor
+	}
+
+	if err := s.db.Delete(&comment).Error; err != nil {
+		return errors.New("не удалось удалить комментарий")
+	}
+	return nil
+}
+
+// GetByID получает комментарий по ID
+func (s *CommentService) GetByID(id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.db.Preload("Author").Preload("Mentions").First(&comment, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ProjectIDOf возвращает проект дефекта, к которому относится комментарий,
+// чтобы проверить доступ к нему
+func (s *CommentService) ProjectIDOf(id uint) (uint, error) {
+	var comment models.Comment
+	if err := s.db.Select("id", "defect_id").First(&comment, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, ErrCommentNotFound
+		}
+		return 0, err
+	}
+
+	var defect models.Defect
+	if err := s.db.Unscoped().Select("id", "project_id").First(&defect, comment.DefectID).Error; err != nil {
+		return 0, err
+	}
+	return defect.ProjectID, nil
+}
+
+// Update изменяет текст комментария (только автор).
+// version - версия из If-Match (0 - не проверять).
+func (s *CommentService) Update(id uint, input models.CommentUpdate, userID uint, version uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.db.First(&comment, id).Error; err != nil {
+		return nil, ErrCommentNotFound
+	}
+
+	if comment.AuthorID != userID {
+		return nil, ErrNotCommentAuthor
+	}
+	if err := checkVersion(comment.Version, version); err != nil {
+		return nil, err
+	}
+
+	comment.Text = input.Text
+	if err := saveVersioned(s.db, &comment, &comment.Version); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(comment.ID)
+}
@@ -0,0 +1,573 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/mergepatch"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrDefectNotFound - дефекта нет или он удален
+var ErrDefectNotFound = errors.New("дефект не найден")
+
+// DefectService - регистрация, изменение, назначение и удаление дефектов
+type DefectService struct {
+	db     *gorm.DB
+	files  *FileService
+	users  repository.UserRepository
+	sla    *SLAService
+	events *EventBus
+}
+
+// NewDefectService создает сервис дефектов на подключении db; files удаляет файлы
+// удаленных дефектов, users - поиск исполнителей, sla рассчитывает сроки устранения,
+// об изменениях сообщается в events
+func NewDefectService(db *gorm.DB, files *FileService, users repository.UserRepository, sla *SLAService, events *EventBus) *DefectService {
+	return &DefectService{db: db, files: files, users: users, sla: sla, events: events}
+}
+
+// Create регистрирует новый дефект на объекте
+func (s *DefectService) Create(ctx context.Context, defectData models.DefectCreate, createdBy uint) (*models.Defect, error) {
+	var project models.Project
+	if err := s.db.First(&project, defectData.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.validateReferences(defectData.CategoryID, defectData.SeverityID); err != nil {
+		return nil, err
+	}
+
+	// Повторная отправка дефекта, созданного офлайн
+	if defectData.ClientID != nil {
+		var count int64
+		s.db.Unscoped().Model(&models.Defect{}).Where("client_id = ?", *defectData.ClientID).Count(&count)
+		if count > 0 {
+			return nil, invalidInput("дефект с таким client_id уже существует")
+		}
+	}
+
+	norms, err := loadNorms(s.db, defectData.NormIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := defectData.Priority
+	if priority == "" {
+		priority = models.DefectPriorityNormal
+	}
+
+	// Срок устранения: указанный вручную или рассчитанный по критичности
+	dueDate := defectData.DueDate
+	if dueDate == nil {
+		dueDate = s.sla.CalculateDueDate(time.Now(), defectData.SeverityID)
+	}
+
+	defect := models.Defect{
+		ProjectID:   defectData.ProjectID,
+		Title:       defectData.Title,
+		Description: defectData.Description,
+		Location:    defectData.Location,
+		Status:      models.DefectStatusNew,
+		Priority:    priority,
+		CategoryID:  defectData.CategoryID,
+		SeverityID:  defectData.SeverityID,
+		Norms:       norms,
+		DueDate:     dueDate,
+		ClientID:    defectData.ClientID,
+		CreatedBy:   createdBy,
+	}
+
+	if err := s.db.Create(&defect).Error; err != nil {
+		return nil, err
+	}
+
+	created, err := s.GetByID(defect.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Emit(DomainEvent{
+		Type:       models.EventDefectCreated,
+		ProjectID:  created.ProjectID,
+		EntityType: models.EntityTypeDefect,
+		EntityID:   created.ID,
+		ActorID:    createdBy,
+		Payload:    created,
+		Context:    ctx,
+	})
+
+	return created, nil
+}
+
+// DefectListSchema - поля дефектов, доступные для сортировки, фильтров и выборки
+var DefectListSchema = listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":               {Column: "defects.id", Type: listquery.Number},
+		"project_id":       {Column: "defects.project_id", Type: listquery.Number},
+		"title":            {Column: "defects.title", Type: listquery.String},
+		"location":         {Column: "defects.location", Type: listquery.String},
+		"status":           {Column: "defects.status", Type: listquery.String},
+		"priority":         {Column: "defects.priority", Type: listquery.String},
+		"category_id":      {Column: "defects.category_id", Type: listquery.Number},
+		"severity_id":      {Column: "defects.severity_id", Type: listquery.Number},
+		"assignee_user_id": {Column: "defects.assignee_user_id", Type: listquery.Number},
+		"assignee_org_id":  {Column: "defects.assignee_org_id", Type: listquery.Number},
+		"due_date":         {Column: "defects.due_date", Type: listquery.Time},
+		"closed_at":        {Column: "defects.closed_at", Type: listquery.Time},
+		"creator_id":       {Column: "defects.created_by", Type: listquery.Number},
+		"created_at":       {Column: "defects.created_at", Type: listquery.Time},
+		"updated_at":       {Column: "defects.updated_at", Type: listquery.Time},
+	},
+	Selectable: []string{"project_id", "title", "description", "location", "status", "priority",
+		"category_id", "category", "severity_id", "severity", "norms", "assignee_user_id", "assignee_user",
+		"assignee_org_id", "assignee_org", "due_date", "closed_at", "escalated_at", "escalated_to",
+		"sla_state", "created_by", "creator", "created_at", "updated_at"},
+	// Состояние SLA считается по сроку, статусу и датам дефекта (ComputeSLAState)
+	Computed: map[string][]string{
+		"sla_state": {"due_date", "status", "closed_at", "created_at"},
+	},
+	DefaultSort:  "-created_at",
+	IDColumn:     "defects.id",
+	DefaultLimit: 10,
+}
+
+// List получает список дефектов с фильтрацией, сортировкой и пагинацией
+func (s *DefectService) List(filter models.DefectFilter, options *listquery.Options) ([]models.Defect, *listquery.PageInfo, error) {
+	var defects []models.Defect
+
+	query := s.db.Model(&models.Defect{}).
+		Preload("Creator").Preload("Category").Preload("Severity").Preload("Norms").
+		Preload("AssigneeUser").Preload("AssigneeOrg")
+	query = applyDefectFilter(query, filter)
+
+	page, err := options.Find(query, &defects)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fillSLAStates(defects)
+
+	return defects, page, nil
+}
+
+// GetByID получает дефект по ID
+func (s *DefectService) GetByID(id uint) (*models.Defect, error) {
+	var defect models.Defect
+	err := s.db.Preload("Creator").Preload("Category").Preload("Severity").Preload("Norms").
+		Preload("AssigneeUser").Preload("AssigneeOrg").
+		First(&defect, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDefectNotFound
+		}
+		return nil, err
+	}
+	defect.SLAState = ComputeSLAState(&defect, time.Now())
+	return &defect, nil
+}
+
+// ProjectIDOf возвращает проект дефекта, чтобы проверить доступ к нему
+func (s *DefectService) ProjectIDOf(id uint) (uint, error) {
+	var defect models.Defect
+	if err := s.db.Select("id", "project_id").First(&defect, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrDefectNotFound
+		}
+		return 0, err
+	}
+	return defect.ProjectID, nil
+}
+
+// Update заменяет все редактируемые поля дефекта.
+// version - версия из If-Match (0 - не проверять).
+func (s *DefectService) Update(ctx context.Context, id uint, updateData models.DefectUpdate, updatedBy uint, version uint) (*models.Defect, error) {
+	var defect models.Defect
+	if err := s.db.First(&defect, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDefectNotFound
+		}
+		return nil, err
+	}
+	if err := checkVersion(defect.Version, version); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateReferences(updateData.CategoryID, updateData.SeverityID); err != nil {
+		return nil, err
+	}
+
+	// PUT заменяет все редактируемые поля; частичное изменение - через PatchDefect
+	defect.Title = updateData.Title
+	defect.Description = updateData.Description
+	defect.Location = updateData.Location
+	defect.Priority = updateData.Priority
+	defect.CategoryID = updateData.CategoryID
+	statusChanged := setDefectStatus(&defect, updateData.Status)
+
+	// Срок, не указанный явно, определяется критичностью: пересчитывается при ее смене
+	// и очищается, если критичность не задана
+	dueDate := updateData.DueDate
+	if dueDate == nil {
+		switch {
+		case updateData.SeverityID == nil:
+		case sameUint(updateData.SeverityID, defect.SeverityID):
+			dueDate = defect.DueDate
+		default:
+			dueDate = s.sla.CalculateDueDate(time.Now(), updateData.SeverityID)
+		}
+	}
+	defect.SeverityID = updateData.SeverityID
+	if !sameTime(dueDate, defect.DueDate) {
+		setDefectDueDate(&defect, dueDate)
+	}
+
+	normIDs := updateData.NormIDs
+	if normIDs == nil {
+		normIDs = []uint{}
+	}
+	return s.save(ctx, &defect, normIDs, updatedBy, statusChanged)
+}
+
+// Patch изменяет дефект по JSON Merge Patch (RFC 7386): null очищает поле.
+// Результат проверяется по тем же правилам, что и при регистрации дефекта.
+func (s *DefectService) Patch(ctx context.Context, id uint, patch []byte, updatedBy uint, version uint) (*models.Defect, error) {
+	var defect models.Defect
+	if err := s.db.Preload("Norms").First(&defect, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDefectNotFound
+		}
+		return nil, err
+	}
+	if err := checkVersion(defect.Version, version); err != nil {
+		return nil, err
+	}
+
+	normIDs := make([]uint, 0, len(defect.Norms))
+	for _, norm := range defect.Norms {
+		normIDs = append(normIDs, norm.ID)
+	}
+	fields := models.DefectPatch{
+		Title:       defect.Title,
+		Description: defect.Description,
+		Location:    defect.Location,
+		Status:      defect.Status,
+		Priority:    defect.Priority,
+		CategoryID:  defect.CategoryID,
+		SeverityID:  defect.SeverityID,
+		NormIDs:     normIDs,
+		DueDate:     defect.DueDate,
+	}
+	if err := mergepatch.Apply(&fields, patch); err != nil {
+		return nil, invalidInput(err.Error())
+	}
+	if errs := validateStruct(fields); len(errs) > 0 {
+		return nil, invalidInput(strings.Join(errs, "; "))
+	}
+	if err := s.validateReferences(fields.CategoryID, fields.SeverityID); err != nil {
+		return nil, err
+	}
+
+	defect.Title = fields.Title
+	defect.Description = fields.Description
+	defect.Location = fields.Location
+	defect.Priority = fields.Priority
+	defect.CategoryID = fields.CategoryID
+	statusChanged := setDefectStatus(&defect, fields.Status)
+
+	dueDate := fields.DueDate
+	dueDateChanged := !sameTime(dueDate, defect.DueDate)
+	if !sameUint(fields.SeverityID, defect.SeverityID) {
+		defect.SeverityID = fields.SeverityID
+		// Новая критичность - новый срок, если он не изменен в том же патче
+		if !dueDateChanged {
+			dueDate, dueDateChanged = s.sla.CalculateDueDate(time.Now(), defect.SeverityID), true
+		}
+	}
+	if dueDateChanged {
+		setDefectDueDate(&defect, dueDate)
+	}
+
+	if fields.NormIDs == nil {
+		fields.NormIDs = []uint{}
+	}
+	defect.Norms = nil
+	return s.save(ctx, &defect, fields.NormIDs, updatedBy, statusChanged)
+}
+
+// setDefectStatus меняет статус дефекта и отметку закрытия; возвращает true, если статус изменился
+func setDefectStatus(defect *models.Defect, status string) bool {
+	if status == defect.Status {
+		return false
+	}
+	defect.Status = status
+	if status == models.DefectStatusClosed || status == models.DefectStatusCancelled {
+		now := time.Now()
+		defect.ClosedAt = &now
+	} else {
+		defect.ClosedAt = nil
+	}
+	return true
+}
+
+// setDefectDueDate задает новый срок устранения и сбрасывает предупреждение и эскалацию по старому сроку
+func setDefectDueDate(defect *models.Defect, dueDate *time.Time) {
+	defect.DueDate = dueDate
+	defect.DeadlineWarnedAt = nil
+	defect.EscalatedAt = nil
+	defect.EscalatedTo = nil
+}
+
+// save сохраняет измененный дефект с проверкой версии и рассылает события обновления.
+// normIDs == nil - нормативы не меняются.
+func (s *DefectService) save(ctx context.Context, defect *models.Defect, normIDs []uint, updatedBy uint, statusChanged bool) (*models.Defect, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, defect, &defect.Version); err != nil {
+			return err
+		}
+
+		if normIDs != nil {
+			norms, err := loadNorms(tx, normIDs)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(defect).Association("Norms").Replace(norms); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetByID(defect.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := DomainEvent{
+		Type:       models.EventDefectUpdated,
+		ProjectID:  updated.ProjectID,
+		EntityType: models.EntityTypeDefect,
+		EntityID:   updated.ID,
+		ActorID:    updatedBy,
+		Payload:    updated,
+		Context:    ctx,
+	}
+	s.events.Emit(event)
+
+	if statusChanged {
+		event.Type = models.EventDefectStatusChanged
+		s.events.Emit(event)
+	}
+
+	return updated, nil
+}
+
+// sameTime сравнивает необязательные даты
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// sameUint сравнивает необязательные идентификаторы
+func sameUint(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// Delete удаляет дефект (мягкое удаление)
+func (s *DefectService) Delete(ctx context.Context, id uint, deletedBy uint) error {
+	var defect models.Defect
+	if err := s.db.First(&defect, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrDefectNotFound
+		}
+		return err
+	}
+
+	if err := s.files.DeleteByEntity(ctx, models.EntityTypeDefect, id); err != nil {
+		logging.FromContext(ctx).Warn("Не удалось удалить файлы дефекта", "defect_id", id, "error", err)
+	}
+
+	// Комментарии, история назначений и уведомления удаляются вместе с дефектом
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.DeleteDefectDependents(tx, []uint{defect.ID}); err != nil {
+			return err
+		}
+		return tx.Delete(&defect).Error
+	})
+	if err != nil {
+		return errors.New("не удалось удалить дефект")
+	}
+
+	s.events.Emit(DomainEvent{
+		Type:       models.EventDefectDeleted,
+		ProjectID:  defect.ProjectID,
+		EntityType: models.EntityTypeDefect,
+		EntityID:   defect.ID,
+		ActorID:    deletedBy,
+		Payload:    &defect,
+		Context:    ctx,
+	})
+
+	return nil
+}
+
+// applyDefectFilter добавляет к запросу условия фильтрации дефектов
+func applyDefectFilter(query *gorm.DB, filter models.DefectFilter) *gorm.DB {
+	if filter.ProjectID != 0 {
+		query = query.Where("defects.project_id = ?", filter.ProjectID)
+	}
+	if filter.ProjectIDs != nil {
+		query = query.Where("defects.project_id IN ?", filter.ProjectIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("defects.status IN ?", strings.Split(filter.Status, ","))
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("defects.category_id = ?", filter.CategoryID)
+	}
+	if filter.SeverityID != 0 {
+		query = query.Where("defects.severity_id = ?", filter.SeverityID)
+	}
+	if filter.AssigneeUserID != 0 {
+		query = query.Where("defects.assignee_user_id = ?", filter.AssigneeUserID)
+	}
+	if filter.AssigneeOrgID != 0 {
+		query = query.Where("defects.assignee_org_id = ?", filter.AssigneeOrgID)
+	}
+	if filter.Overdue {
+		query = query.Where("defects.due_date < ? AND defects.status NOT IN ?",
+			time.Now(), []string{models.DefectStatusClosed, models.DefectStatusCancelled})
+	}
+	if filter.Location != "" {
+		query = query.Where("defects.location ILIKE ?", "%"+filter.Location+"%")
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("defects.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("defects.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.NormID != 0 {
+		query = query.Where("defects.id IN (SELECT defect_id FROM defect_norms WHERE norm_reference_id = ?)",
+			filter.NormID)
+	}
+	return query
+}
+
+// ParseDefectFilter собирает фильтр дефектов из параметров запроса
+// (project_id, status, category_id, severity_id, norm_id, overdue, assignee_user_id,
+// assignee_org_id, location, from, to)
+func ParseDefectFilter(values url.Values) (models.DefectFilter, error) {
+	filter := models.DefectFilter{
+		ProjectID:      valuesUint(values, "project_id"),
+		Status:         values.Get("status"),
+		CategoryID:     valuesUint(values, "category_id"),
+		SeverityID:     valuesUint(values, "severity_id"),
+		NormID:         valuesUint(values, "norm_id"),
+		Overdue:        values.Get("overdue") == "true",
+		AssigneeUserID: valuesUint(values, "assignee_user_id"),
+		AssigneeOrgID:  valuesUint(values, "assignee_org_id"),
+		Location:       values.Get("location"),
+	}
+
+	from, to, err := ParseDateRange(values)
+	if err != nil {
+		return filter, err
+	}
+	filter.CreatedFrom, filter.CreatedTo = from, to
+
+	return filter, nil
+}
+
+// ParseDateRange читает период из параметров from и to (2006-01-02, обе границы включительно)
+// и возвращает его как полуинтервал [from, to)
+func ParseDateRange(values url.Values) (from, to *time.Time, err error) {
+	return ParseDateRangeIn(values, time.Local)
+}
+
+// ParseDateRangeIn читает период, как ParseDateRange, с датами в часовом поясе loc
+func ParseDateRangeIn(values url.Values, loc *time.Location) (from, to *time.Time, err error) {
+	if value := values.Get("from"); value != "" {
+		date, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return nil, nil, errors.New("неверный формат даты from, ожидается ГГГГ-ММ-ДД")
+		}
+		from = &date
+	}
+	if value := values.Get("to"); value != "" {
+		date, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return nil, nil, errors.New("неверный формат даты to, ожидается ГГГГ-ММ-ДД")
+		}
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+	return from, to, nil
+}
+
+// valuesUint читает необязательный числовой параметр (0 - не задан)
+func valuesUint(values url.Values, key string) uint {
+	value, err := strconv.ParseUint(values.Get(key), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(value)
+}
+
+// validateReferences проверяет существование категории и уровня критичности
+func (s *DefectService) validateReferences(categoryID, severityID *uint) error {
+	if categoryID != nil {
+		var category models.DefectCategory
+		if err := s.db.First(&category, *categoryID).Error; err != nil {
+			return invalidInput("категория дефекта не найдена")
+		}
+	}
+	if severityID != nil {
+		var severity models.SeverityLevel
+		if err := s.db.First(&severity, *severityID).Error; err != nil {
+			return invalidInput("уровень критичности не найден")
+		}
+	}
+	return nil
+}
+
+// loadNorms загружает нормативные ссылки по списку ID через db (подключение или транзакцию);
+// повторяющиеся ID учитываются один раз
+func loadNorms(db *gorm.DB, ids []uint) ([]models.NormReference, error) {
+	if len(ids) == 0 {
+		return []models.NormReference{}, nil
+	}
+
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+
+	var norms []models.NormReference
+	if err := db.Where("id IN ?", ids).Find(&norms).Error; err != nil {
+		return nil, err
+	}
+	if len(norms) != len(unique) {
+		return nil, invalidInput("нормативная ссылка не найдена")
+	}
+	return norms, nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/mailer"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Размер очереди писем
+const emailQueueSize = 256
+
+// EmailService формирует письма с уведомлениями и ежедневные сводки
+type EmailService struct {
+	db     *gorm.DB
+	appURL string // адрес веб-приложения для ссылок в письмах (может быть пустым)
+
+	// Очередь писем: отправка по SMTP не должна задерживать запрос, породивший уведомление
+	queue chan mailer.Message
+}
+
+// NewEmailService создает сервис писем на подключении db со ссылками на веб-приложение appURL
+func NewEmailService(db *gorm.DB, appURL string) *EmailService {
+	return &EmailService{db: db, appURL: appURL, queue: make(chan mailer.Message, emailQueueSize)}
+}
+
+// SendNotification отправляет созданное уведомление по email, если пользователь это разрешил
+// (подписчик EventBus).
+func (s *EmailService) SendNotification(event DomainEvent) {
+	if event.Type != models.EventNotificationCreated {
+		return
+	}
+	notification, ok := event.Payload.(*models.Notification)
+	if !ok || !s.Enabled(notification.UserID, notification.Type) {
+		return
+	}
+
+	var user models.User
+	if err := s.db.First(&user, notification.UserID).Error; err != nil || !user.IsActive {
+		return
+	}
+
+	var projectName string
+	if notification.ProjectID != 0 {
+		s.db.Model(&models.Project{}).Where("id = ?", notification.ProjectID).Pluck("name", &projectName)
+	}
+
+	text, html, err := mailer.Render("notification", map[string]interface{}{
+		"Name":        user.FirstName,
+		"Title":       notification.Title,
+		"Message":     notification.Message,
+		"ProjectName": projectName,
+		"AppURL":      s.appURL,
+	})
+	if err != nil {
+		logging.FromContext(event.Context).Error("Не удалось сформировать письмо уведомления", "notification_id", notification.ID, "error", err)
+		return
+	}
+
+	s.enqueue(event.Context, mailer.Message{
+		To:      []string{user.Email},
+		Subject: notification.Title,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+// enqueue ставит письмо в очередь; при переполнении письмо теряется, уведомление в приложении остается
+func (s *EmailService) enqueue(ctx context.Context, msg mailer.Message) {
+	select {
+	case s.queue <- msg:
+	default:
+		logging.FromContext(ctx).Warn("Очередь писем переполнена, письмо не отправлено", "subject", msg.Subject)
+	}
+}
+
+// StartWorker отправляет письма из очереди до отмены контекста.
+// При отмене отправляет письма, уже стоящие в очереди, чтобы они не потерялись при остановке.
+func (s *EmailService) StartWorker(ctx context.Context, sender mailer.Sender) {
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case msg := <-s.queue:
+					sendEmail(sender, msg)
+				default:
+					return
+				}
+			}
+		case msg := <-s.queue:
+			sendEmail(sender, msg)
+		}
+	}
+}
+
+// sendEmail отправляет письмо и пишет ошибку в журнал
+func sendEmail(sender mailer.Sender, msg mailer.Message) {
+	if err := sender.Send(msg); err != nil {
+		slog.Error("Ошибка отправки письма", "subject", msg.Subject, "error", err)
+	}
+}
+
+// Enabled проверяет, получает ли пользователь уведомления этого типа по email
+func (s *EmailService) Enabled(userID uint, eventType string) bool {
+	enabled, known := models.EmailDefaultEvents[eventType]
+	if !known {
+		return false
+	}
+
+	var preference models.NotificationPreference
+	err := s.db.Where("user_id = ? AND event_type = ?", userID, eventType).First(&preference).Error
+	if err == nil {
+		return preference.Email
+	}
+	return enabled
+}
+
+// GetPreferences возвращает настройки email-уведомлений пользователя
+// по всем типам, с учетом значений по умолчанию
+func (s *EmailService) GetPreferences(userID uint) ([]models.NotificationPreference, error) {
+	var saved []models.NotificationPreference
+	if err := s.db.Where("user_id = ?", userID).Find(&saved).Error; err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]bool, len(models.EmailDefaultEvents))
+	for eventType, enabled := range models.EmailDefaultEvents {
+		values[eventType] = enabled
+	}
+	for _, preference := range saved {
+		if _, known := values[preference.EventType]; known {
+			values[preference.EventType] = preference.Email
+		}
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(values))
+	for eventType, enabled := range values {
+		preferences = append(preferences, models.NotificationPreference{
+			UserID:    userID,
+			EventType: eventType,
+			Email:     enabled,
+		})
+	}
+	sort.Slice(preferences, func(i, j int) bool {
+		return preferences[i].EventType < preferences[j].EventType
+	})
+	return preferences, nil
+}
+
+// UpdatePreferences сохраняет настройки email-уведомлений пользователя
+func (s *EmailService) UpdatePreferences(userID uint, inputs []models.NotificationPreferenceInput) ([]models.NotificationPreference, error) {
+	for _, input := range inputs {
+		if _, known := models.EmailDefaultEvents[input.EventType]; !known {
+			return nil, fmt.Errorf("неизвестный тип уведомления: %s", input.EventType)
+		}
+	}
+
+	for _, input := range inputs {
+		preference := models.NotificationPreference{
+			UserID:    userID,
+			EventType: input.EventType,
+			Email:     *input.Email,
+		}
+		err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"email", "updated_at"}),
+		}).Create(&preference).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPreferences(userID)
+}
+
+// digestProject - сводка по одному проекту для ежедневного письма
+type digestProject struct {
+	Name           string
+	Address        string
+	New            int64
+	Overdue        int64
+	Closed         int64
+	OverdueDefects []digestDefect
+}
+
+type digestDefect struct {
+	Title   string
+	DueDate string
+}
+
+// Сколько просроченных дефектов перечисляется в сводке по проекту
+const digestOverdueLimit = 10
+
+// StartDigestScheduler ежедневно в hour часов (локальное время) рассылает сводки
+func (s *EmailService) StartDigestScheduler(ctx context.Context, hour int) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+			if sent, err := s.SendDailyDigests(ctx, time.Now()); err != nil {
+				slog.Error("Ошибка рассылки ежедневной сводки", "error", err)
+			} else {
+				slog.Info("Ежедневная сводка отправлена", "recipients", sent)
+			}
+		}
+	}
+}
+
+// SendDailyDigests формирует сводку за последние сутки каждому руководителю
+// по созданным им проектам: новые, просроченные и закрытые дефекты.
+// Возвращает количество отправленных писем.
+func (s *EmailService) SendDailyDigests(ctx context.Context, now time.Time) (int, error) {
+	var managers []models.User
+	err := s.db.Joins("JOIN roles ON roles.id = users.role_id").
+		Where("roles.code = ? AND users.is_active = ?", models.RoleManager, true).
+		Find(&managers).Error
+	if err != nil {
+		return 0, err
+	}
+
+	since := now.Add(-24 * time.Hour)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	sent := 0
+
+	for _, manager := range managers {
+		if !s.Enabled(manager.ID, models.EventDigestDaily) {
+			continue
+		}
+
+		projects, err := s.buildDigest(manager.ID, since, now)
+		if err != nil {
+			return sent, err
+		}
+		if len(projects) == 0 {
+			continue
+		}
+
+		// Отметка о сводке за день; если ее уже сделал другой экземпляр, письмо не отправляется
+		mark := models.EmailDigest{UserID: manager.ID, Date: date, SentAt: now}
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&mark)
+		if result.Error != nil {
+			return sent, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		text, html, err := mailer.Render("digest", map[string]interface{}{
+			"Name":     manager.FirstName,
+			"Date":     now.Format("02.01.2006"),
+			"Projects": projects,
+			"AppURL":   s.appURL,
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		s.enqueue(ctx, mailer.Message{
+			To:      []string{manager.Email},
+			Subject: "Сводка по дефектам за " + now.Format("02.01.2006"),
+			Text:    text,
+			HTML:    html,
+		})
+		sent++
+	}
+
+	return sent, nil
+}
+
+// buildDigest собирает сводку по проектам руководителя; проекты без изменений пропускаются
+func (s *EmailService) buildDigest(managerID uint, since, now time.Time) ([]digestProject, error) {
+	var projects []models.Project
+	if err := s.db.Where("created_by = ?", managerID).Order("name").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	finalStatuses := []string{models.DefectStatusClosed, models.DefectStatusCancelled}
+	var result []digestProject
+
+	for _, project := range projects {
+		item := digestProject{Name: project.Name, Address: project.Address}
+		defects := func() *gorm.DB {
+			return s.db.Model(&models.Defect{}).Where("project_id = ?", project.ID)
+		}
+
+		if err := defects().Where("created_at >= ?", since).Count(&item.New).Error; err != nil {
+			return nil, err
+		}
+		if err := defects().Where("closed_at >= ?", since).Count(&item.Closed).Error; err != nil {
+			return nil, err
+		}
+		if err := defects().Where("status NOT IN ? AND due_date < ?", finalStatuses, now).Count(&item.Overdue).Error; err != nil {
+			return nil, err
+		}
+
+		if item.Overdue > 0 {
+			var overdue []models.Defect
+			err := defects().Where("status NOT IN ? AND due_date < ?", finalStatuses, now).
+				Order("due_date").Limit(digestOverdueLimit).Find(&overdue).Error
+			if err != nil {
+				return nil, err
+			}
+			for _, defect := range overdue {
+				item.OverdueDefects = append(item.OverdueDefects, digestDefect{
+					Title:   defect.Title,
+					DueDate: defect.DueDate.Format("02.01.2006"),
+				})
+			}
+		}
+
+		if item.New+item.Closed+item.Overdue > 0 {
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"SystemContorlBackend/internal/logging"
+)
+
+// DomainEvent - событие предметной области, порождаемое сервисами
+type DomainEvent struct {
+	Type       string      // Тип события (models.Event*)
+	ProjectID  uint        // Проект, к которому относится событие
+	EntityType string      // Тип сущности: "project", "defect", "comment", "file"
+	EntityID   uint        // ID сущности
+	ActorID    uint        // Пользователь, совершивший действие (0 - система)
+	UserID     uint        // Адресат личного события (уведомления); 0 - событие проекта
+	Payload    interface{} // Сама сущность после изменения
+	OccurredAt time.Time
+
+	// Контекст операции, породившей событие. Из него подписчики берут логгер запроса
+	// (с request_id и пользователем); nil - событие фоновой задачи.
+	Context context.Context
+}
+
+// EventHandler - обработчик доменных событий
+type EventHandler func(event DomainEvent)
+
+// EventBus передает доменные события сервисов подписчикам: уведомлениям, webhook,
+// письмам и потоку реального времени. Подписчики регистрируются при запуске приложения.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+// NewEventBus создает шину событий без подписчиков
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe регистрирует обработчик доменных событий
+func (b *EventBus) Subscribe(handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Emit синхронно передает событие всем обработчикам.
+// Ошибка одного обработчика не должна ломать операцию, которая породила событие.
+func (b *EventBus) Emit(event DomainEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]EventHandler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logging.FromContext(event.Context).Error("Ошибка обработчика события", "event", event.Type, "panic", r)
+				}
+			}()
+			handler(event)
+		}()
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"strings"
+
+	"SystemContorlBackend/internal/export"
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Размер пачки при выгрузке: в памяти одновременно держится не больше этого числа записей
+const exportBatchSize = 500
+
+// ExportService - выгрузка списков проектов и дефектов в файлы
+type ExportService struct {
+	db *gorm.DB
+}
+
+// NewExportService создает сервис выгрузки на подключении db
+func NewExportService(db *gorm.DB) *ExportService {
+	return &ExportService{db: db}
+}
+
+// Названия статусов проекта для выгрузки
+var projectStatusTitles = map[string]string{
+	models.ProjectStatusActive:    "Активный",
+	models.ProjectStatusCompleted: "Завершенный",
+	models.ProjectStatusSuspended: "Приостановленный",
+}
+
+// Названия приоритетов дефекта для выгрузки
+var defectPriorityTitles = map[string]string{
+	models.DefectPriorityLow:    "Низкий",
+	models.DefectPriorityNormal: "Обычный",
+	models.DefectPriorityHigh:   "Высокий",
+	models.DefectPriorityUrgent: "Срочный",
+}
+
+// ProjectExportColumns - колонки выгрузки проектов
+var ProjectExportColumns = []export.Column{
+	{Title: "ID", Width: 8, Kind: export.KindNumber},
+	{Title: "Название", Width: 40},
+	{Title: "Описание", Width: 50},
+	{Title: "Адрес", Width: 40},
+	{Title: "Статус", Width: 18},
+	{Title: "Дата начала", Width: 14, Kind: export.KindDate},
+	{Title: "Дата окончания", Width: 14, Kind: export.KindDate},
+	{Title: "Создатель", Width: 28},
+	{Title: "Создан", Width: 18, Kind: export.KindDateTime},
+}
+
+// DefectExportColumns - колонки выгрузки дефектов
+var DefectExportColumns = []export.Column{
+	{Title: "ID", Width: 8, Kind: export.KindNumber},
+	{Title: "Проект", Width: 30},
+	{Title: "Название", Width: 40},
+	{Title: "Описание", Width: 50},
+	{Title: "Место", Width: 25},
+	{Title: "Статус", Width: 14},
+	{Title: "Приоритет", Width: 12},
+	{Title: "Категория", Width: 22},
+	{Title: "Критичность", Width: 14},
+	{Title: "Исполнитель", Width: 28},
+	{Title: "Организация-исполнитель", Width: 30},
+	{Title: "Срок устранения", Width: 18, Kind: export.KindDateTime},
+	{Title: "Автор", Width: 28},
+	{Title: "Создан", Width: 18, Kind: export.KindDateTime},
+	{Title: "Закрыт", Width: 18, Kind: export.KindDateTime},
+}
+
+// Projects выгружает проекты пачками с теми же фильтрами и сортировкой, что и список
+// проектов: status, фильтры с операторами и параметры сохраненного представления;
+// projectIDs - доступные пользователю проекты (nil - все)
+func (s *ExportService) Projects(w export.Writer, status string, projectIDs []uint, options *listquery.Options) error {
+	query := s.db.Model(&models.Project{}).Preload("Creator")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if projectIDs != nil {
+		query = query.Where("projects.id IN ?", projectIDs)
+	}
+
+	var projects []models.Project
+	return options.Each(query, &projects, exportBatchSize, func() error {
+		for _, project := range projects {
+			err := w.WriteRow([]interface{}{
+				project.ID,
+				project.Name,
+				project.Description,
+				project.Address,
+				projectStatusTitles[project.Status],
+				project.StartDate,
+				project.EndDate,
+				userFullName(&project.Creator),
+				project.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Defects выгружает дефекты пачками с теми же фильтрами и сортировкой, что и список дефектов
+func (s *ExportService) Defects(w export.Writer, filter models.DefectFilter, options *listquery.Options) error {
+	query := s.db.Model(&models.Defect{}).
+		Preload("Project").Preload("Creator").Preload("Category").Preload("Severity").
+		Preload("AssigneeUser").Preload("AssigneeOrg")
+	query = applyDefectFilter(query, filter)
+
+	var defects []models.Defect
+	return options.Each(query, &defects, exportBatchSize, func() error {
+		for _, defect := range defects {
+			var projectName, category, severity, assigneeOrg string
+			if defect.Project != nil {
+				projectName = defect.Project.Name
+			}
+			if defect.Category != nil {
+				category = defect.Category.Name
+			}
+			if defect.Severity != nil {
+				severity = defect.Severity.Name
+			}
+			if defect.AssigneeOrg != nil {
+				assigneeOrg = defect.AssigneeOrg.Name
+			}
+
+			err := w.WriteRow([]interface{}{
+				defect.ID,
+				projectName,
+				defect.Title,
+				defect.Description,
+				defect.Location,
+				defectStatusTitles[defect.Status],
+				defectPriorityTitles[defect.Priority],
+				category,
+				severity,
+				userFullName(defect.AssigneeUser),
+				assigneeOrg,
+				defect.DueDate,
+				userFullName(&defect.Creator),
+				defect.CreatedAt,
+				defect.ClosedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// userFullName возвращает "Фамилия Имя" пользователя или пустую строку
+func userFullName(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	return strings.TrimSpace(user.LastName + " " + user.FirstName)
+}
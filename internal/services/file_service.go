@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -10,16 +11,15 @@ import (
 	"strings"
 	"time"
 
-	"SystemContorlBackend/internal/database"
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/logging"
 	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/repository"
 )
 
 const (
 	// Максимальный размер файла: 10MB
 	MaxFileSize = 10 << 20 // 10MB
-	
-	// Директория для загрузки файлов
-	UploadDir = "uploads"
 )
 
 // Разрешенные типы файлов
@@ -38,8 +38,21 @@ var AllowedDocumentTypes = map[string]bool{
 	"text/plain": true,
 }
 
-// UploadFile загружает файл и сохраняет информацию в БД
-func UploadFile(file *multipart.FileHeader, entityType string, entityID uint, uploadedBy uint) (*models.Attachment, error) {
+// FileService - хранение вложений проектов и дефектов: файлы на диске, записи в БД
+type FileService struct {
+	attachments repository.AttachmentRepository
+	uploadDir   string
+	events      *EventBus
+}
+
+// NewFileService создает сервис файлов, сохраняющий загрузки в каталог uploadDir;
+// о загрузке и удалении файлов сообщается в events
+func NewFileService(attachments repository.AttachmentRepository, uploadDir string, events *EventBus) *FileService {
+	return &FileService{attachments: attachments, uploadDir: uploadDir, events: events}
+}
+
+// Upload загружает файл и сохраняет информацию в БД
+func (s *FileService) Upload(ctx context.Context, file *multipart.FileHeader, entityType string, entityID uint, uploadedBy uint) (*models.Attachment, error) {
 	// Проверяем размер файла
 	if file.Size > MaxFileSize {
 		return nil, errors.New("файл слишком большой. Максимальный размер: 10MB")
@@ -53,7 +66,7 @@ func UploadFile(file *multipart.FileHeader, entityType string, entityID uint, up
 	}
 
 	// Создаем директорию если не существует
-	entityDir := filepath.Join(UploadDir, entityType)
+	entityDir := filepath.Join(s.uploadDir, entityType)
 	if err := os.MkdirAll(entityDir, 0755); err != nil {
 		return nil, fmt.Errorf("ошибка создания директории: %v", err)
 	}
@@ -96,22 +109,48 @@ func UploadFile(file *multipart.FileHeader, entityType string, entityID uint, up
 		UploadedBy:   uploadedBy,
 	}
 
-	if err := database.DB.Create(&attachment).Error; err != nil {
+	if err := s.attachments.Create(&attachment); err != nil {
 		// Удаляем файл если не удалось сохранить в БД
 		os.Remove(filePath)
 		return nil, fmt.Errorf("ошибка сохранения в базе данных: %v", err)
 	}
 
+	s.events.Emit(DomainEvent{
+		Type:       models.EventFileUploaded,
+		ProjectID:  s.attachments.ProjectIDOf(entityType, entityID),
+		EntityType: "file",
+		EntityID:   attachment.ID,
+		ActorID:    uploadedBy,
+		Payload:    &attachment,
+		Context:    ctx,
+	})
+
 	return &attachment, nil
 }
 
-// GetAttachments получает список файлов для сущности
-func GetAttachments(entityType string, entityID uint) ([]models.AttachmentResponse, error) {
-	var attachments []models.Attachment
-	
-	if err := database.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
-		Find(&attachments).Error; err != nil {
-		return nil, err
+// AttachmentListSchema - поля файлов, доступные для сортировки, фильтров и выборки
+var AttachmentListSchema = listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":            {Column: "attachments.id", Type: listquery.Number},
+		"original_name": {Column: "attachments.original_name", Type: listquery.String},
+		"file_size":     {Column: "attachments.file_size", Type: listquery.Number},
+		"content_type":  {Column: "attachments.content_type", Type: listquery.String},
+		"file_type":     {Column: "attachments.file_type", Type: listquery.String},
+		"uploaded_by":   {Column: "attachments.uploaded_by", Type: listquery.Number},
+		"created_at":    {Column: "attachments.created_at", Type: listquery.Time},
+	},
+	Selectable: []string{"file_name", "original_name", "file_size", "content_type", "file_type",
+		"uploaded_by", "created_at", "url"},
+	DefaultSort: "created_at",
+	IDColumn:    "attachments.id",
+}
+
+// List получает список файлов для сущности.
+// options задает фильтры, сортировку и пагинацию; nil - все файлы в порядке загрузки.
+func (s *FileService) List(entityType string, entityID uint, options *listquery.Options) ([]models.AttachmentResponse, *listquery.PageInfo, error) {
+	attachments, page, err := s.attachments.ListByEntity(entityType, entityID, options)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Преобразуем в response формат
@@ -130,15 +169,14 @@ func GetAttachments(entityType string, entityID uint) ([]models.AttachmentRespon
 		})
 	}
 
-	return responses, nil
+	return responses, page, nil
 }
 
-// DeleteAttachment удаляет ОДИН конкретный файл по его ID
-func DeleteAttachment(attachmentID uint, userID uint) error {
-	var attachment models.Attachment
-
+// Delete удаляет ОДИН конкретный файл по его ID
+func (s *FileService) Delete(ctx context.Context, attachmentID uint, userID uint) error {
 	// Получаем файл по ID
-	if err := database.DB.First(&attachment, attachmentID).Error; err != nil {
+	attachment, err := s.attachments.FindByID(attachmentID)
+	if err != nil {
 		return errors.New("файл не найден")
 	}
 
@@ -151,25 +189,33 @@ func DeleteAttachment(attachmentID uint, userID uint) error {
 	// Удаляем файл с диска
 	if err := os.Remove(attachment.FilePath); err != nil {
 		// Логируем ошибку, но продолжаем удаление из БД
-		fmt.Printf("Предупреждение: не удалось удалить файл %s: %v\n", attachment.FilePath, err)
+		logging.FromContext(ctx).Warn("Не удалось удалить файл", "path", attachment.FilePath, "error", err)
 	}
 
 	// Удаляем запись из БД
-	if err := database.DB.Delete(&attachment).Error; err != nil {
+	if err := s.attachments.Delete(attachment); err != nil {
 		return errors.New("не удалось удалить запись из базы данных")
 	}
 
+	s.events.Emit(DomainEvent{
+		Type:       models.EventFileDeleted,
+		ProjectID:  s.attachments.ProjectIDOf(attachment.EntityType, attachment.EntityID),
+		EntityType: "file",
+		EntityID:   attachment.ID,
+		ActorID:    userID,
+		Payload:    attachment,
+		Context:    ctx,
+	})
+
 	return nil
 }
 
-// DeleteAttachmentsByEntity удаляет ВСЕ файлы, связанные с сущностью (проект, дефект)
-// Эта функция вызывается при удалении самого проекта/дефекта
-func DeleteAttachmentsByEntity(entityType string, entityID uint) error {
-	var attachments []models.Attachment
-
+// DeleteByEntity удаляет ВСЕ файлы, связанные с сущностью (проект, дефект)
+// Этот метод вызывается при удалении самого проекта/дефекта
+func (s *FileService) DeleteByEntity(ctx context.Context, entityType string, entityID uint) error {
 	// Получаем все файлы, связанные с сущностью
-	if err := database.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
-		Find(&attachments).Error; err != nil {
+	attachments, _, err := s.attachments.ListByEntity(entityType, entityID, nil)
+	if err != nil {
 		return err
 	}
 
@@ -179,29 +225,38 @@ func DeleteAttachmentsByEntity(entityType string, entityID uint) error {
 	}
 
 	// Удаляем каждый файл с диска
-	for _, attachment := range attachments {
-		if err := os.Remove(attachment.FilePath); err != nil {
-			// Логируем ошибку, но продолжаем
-			fmt.Printf("Предупреждение: не удалось удалить файл %s: %v\n", attachment.FilePath, err)
-		}
-	}
+	s.RemoveFiles(ctx, attachments)
 
 	// Удаляем все записи из БД
-	if err := database.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
-		Delete(&models.Attachment{}).Error; err != nil {
+	if err := s.attachments.DeleteByEntity(entityType, entityID); err != nil {
 		return errors.New("не удалось удалить записи из базы данных")
 	}
 
 	return nil
 }
 
+// RemoveFiles удаляет с диска файлы, записи о которых уже удалены из БД
+func (s *FileService) RemoveFiles(ctx context.Context, attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		if err := os.Remove(attachment.FilePath); err != nil {
+			// Логируем ошибку, но продолжаем
+			logging.FromContext(ctx).Warn("Не удалось удалить файл", "path", attachment.FilePath, "error", err)
+		}
+	}
+}
 
-func GetAttachmentByID(id uint) (*models.Attachment, error) {
-	var attachment models.Attachment
-	if err := database.DB.First(&attachment, id).Error; err != nil {
+// GetByID получает запись о файле по ID
+func (s *FileService) GetByID(id uint) (*models.Attachment, error) {
+	attachment, err := s.attachments.FindByID(id)
+	if err != nil {
 		return nil, errors.New("файл не найден")
 	}
-	return &attachment, nil
+	return attachment, nil
+}
+
+// ProjectIDOf возвращает проект, к которому относится сущность с файлами (0 - не найден)
+func (s *FileService) ProjectIDOf(entityType string, entityID uint) uint {
+	return s.attachments.ProjectIDOf(entityType, entityID)
 }
 
 // getFileType определяет тип файла по MIME типу
@@ -220,11 +275,11 @@ func IsImageFile(contentType string) bool {
 	return strings.HasPrefix(contentType, "image/")
 }
 
-// ReplaceAttachment заменяет существующий файл новым
-func ReplaceAttachment(id uint, file *multipart.FileHeader, userID uint) (*models.Attachment, error) {
+// Replace заменяет существующий файл новым
+func (s *FileService) Replace(ctx context.Context, id uint, file *multipart.FileHeader, userID uint) (*models.Attachment, error) {
 	// Получаем старый файл
-	var oldAttachment models.Attachment
-	if err := database.DB.First(&oldAttachment, id).Error; err != nil {
+	oldAttachment, err := s.attachments.FindByID(id)
+	if err != nil {
 		return nil, errors.New("файл не найден")
 	}
 
@@ -250,7 +305,7 @@ func ReplaceAttachment(id uint, file *multipart.FileHeader, userID uint) (*model
 	timestamp := time.Now().Unix()
 	ext := filepath.Ext(file.Filename)
 	fileName := fmt.Sprintf("%d_%d%s", timestamp, oldAttachment.EntityID, ext)
-	filePath := filepath.Join(UploadDir, oldAttachment.EntityType, fileName)
+	filePath := filepath.Join(s.uploadDir, oldAttachment.EntityType, fileName)
 
 	// Открываем загруженный файл
 	src, err := file.Open()
@@ -283,7 +338,7 @@ func ReplaceAttachment(id uint, file *multipart.FileHeader, userID uint) (*model
 	oldAttachment.FileType = fileType
 
 	// Обновляем в базе данных
-	if err := database.DB.Save(&oldAttachment).Error; err != nil {
+	if err := s.attachments.Save(oldAttachment); err != nil {
 		// Удаляем новый файл если не удалось обновить БД
 		os.Remove(filePath)
 		return nil, fmt.Errorf("ошибка обновления в базе данных: %v", err)
@@ -292,8 +347,8 @@ func ReplaceAttachment(id uint, file *multipart.FileHeader, userID uint) (*model
 	// Удаляем старый файл с диска
 	if err := os.Remove(oldFilePath); err != nil {
 		// Логируем ошибку, но не прерываем операцию
-		fmt.Printf("Предупреждение: не удалось удалить старый файл %s: %v\n", oldFilePath, err)
+		logging.FromContext(ctx).Warn("Не удалось удалить старый файл", "path", oldFilePath, "error", err)
 	}
 
-	return &oldAttachment, nil
+	return oldAttachment, nil
 }
\ No newline at end of file
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"SystemContorlBackend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// readinessTimeout ограничивает время одной проверки готовности
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck - результат одной проверки готовности
+type ReadinessCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// HealthService проверяет, может ли экземпляр API принимать запросы
+type HealthService struct {
+	db              *gorm.DB
+	uploadDir       string
+	checkMigrations bool
+	draining        atomic.Bool
+}
+
+// NewHealthService создает проверки готовности. checkMigrations - проверять, что применены
+// все SQL-миграции (не нужно, когда схемой управляет AutoMigrate).
+func NewHealthService(db *gorm.DB, uploadDir string, checkMigrations bool) *HealthService {
+	return &HealthService{db: db, uploadDir: uploadDir, checkMigrations: checkMigrations}
+}
+
+// StartDraining отмечает, что сервер останавливается: проверка готовности перестает
+// проходить, и балансировщик больше не направляет сюда новые запросы
+func (s *HealthService) StartDraining() {
+	s.draining.Store(true)
+}
+
+// Ready выполняет все проверки и сообщает, готов ли экземпляр принимать запросы
+func (s *HealthService) Ready(ctx context.Context) (bool, []ReadinessCheck) {
+	checks := []ReadinessCheck{
+		result("database", s.checkDatabase(ctx)),
+	}
+	if s.checkMigrations {
+		checks = append(checks, result("migrations", s.checkPendingMigrations()))
+	}
+	checks = append(checks, result("uploads", s.checkUploadDir()))
+	if s.draining.Load() {
+		checks = append(checks, result("shutdown", errors.New("сервер останавливается")))
+	}
+
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.OK
+	}
+	return ready, checks
+}
+
+// result оформляет итог проверки
+func result(name string, err error) ReadinessCheck {
+	if err != nil {
+		return ReadinessCheck{Name: name, Error: err.Error()}
+	}
+	return ReadinessCheck{Name: name, OK: true}
+}
+
+// checkDatabase проверяет соединение с БД
+func (s *HealthService) checkDatabase(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// checkPendingMigrations проверяет, что схема БД актуальна
+func (s *HealthService) checkPendingMigrations() error {
+	pending, err := database.PendingMigrations(s.db)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("не применено миграций: %d", len(pending))
+	}
+	return nil
+}
+
+// checkUploadDir проверяет, что в каталог загрузок можно записать файл
+func (s *HealthService) checkUploadDir() error {
+	if err := os.MkdirAll(s.uploadDir, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(s.uploadDir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	name := probe.Name()
+	_, writeErr := probe.WriteString("ok")
+	closeErr := probe.Close()
+	os.Remove(name)
+	if writeErr != nil {
+		return writeErr
+	}
+	return closeErr
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Запрос, который выполняется дольше, считается прерванным: ключ можно использовать повторно
+const idempotencyLockTimeout = 5 * time.Minute
+
+// Ошибки резервирования ключа идемпотентности
+var (
+	// ErrIdempotencyKeyReused - ключ уже использован для запроса с другим методом, путем или телом
+	ErrIdempotencyKeyReused = errors.New("ключ идемпотентности уже использован для другого запроса")
+	// ErrIdempotencyInProgress - запрос с этим ключом еще выполняется
+	ErrIdempotencyInProgress = errors.New("запрос с этим ключом идемпотентности еще выполняется")
+)
+
+// IdempotencyService хранит ответы на запросы с Idempotency-Key в течение ttl
+type IdempotencyService struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewIdempotencyService создает хранилище ключей идемпотентности на подключении db
+func NewIdempotencyService(db *gorm.DB, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{db: db, ttl: ttl}
+}
+
+// Begin резервирует ключ идемпотентности для запроса.
+// Возвращает сохраненный ответ, если запрос с этим ключом уже выполнен,
+// или новую запись-блокировку (StatusCode = 0), если запрос нужно выполнить.
+func (s *IdempotencyService) Begin(userID uint, key, method, path, requestHash string) (*models.IdempotencyKey, error) {
+	// Вторая попытка нужна, если найденный ключ истек и был удален
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		record := models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      method,
+			Path:        path,
+			RequestHash: requestHash,
+			ExpiresAt:   now.Add(s.ttl),
+		}
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return &record, nil
+		}
+
+		var existing models.IdempotencyKey
+		err := s.db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error
+		if err != nil {
+			continue
+		}
+
+		expired := existing.ExpiresAt.Before(now)
+		abandoned := existing.StatusCode == 0 && existing.CreatedAt.Before(now.Add(-idempotencyLockTimeout))
+		if expired || abandoned {
+			// Условие по created_at не дает удалить ключ, который успел занять другой запрос
+			s.db.Where("id = ? AND created_at = ?", existing.ID, existing.CreatedAt).
+				Delete(&models.IdempotencyKey{})
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if existing.StatusCode == 0 {
+			return nil, ErrIdempotencyInProgress
+		}
+		return &existing, nil
+	}
+	return nil, errors.New("не удалось зарезервировать ключ идемпотентности")
+}
+
+// Complete сохраняет ответ на запрос для повторов с тем же ключом
+func (s *IdempotencyService) Complete(id uint, statusCode int, contentType string, body []byte) error {
+	return s.db.Model(&models.IdempotencyKey{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+		}).Error
+}
+
+// Release освобождает ключ, если запрос завершился ошибкой сервера,
+// чтобы клиент мог повторить его
+func (s *IdempotencyService) Release(id uint) error {
+	return s.db.Delete(&models.IdempotencyKey{}, id).Error
+}
+
+// DeleteExpired удаляет ключи, срок хранения которых истек
+func (s *IdempotencyService) DeleteExpired() (int64, error) {
+	result := s.db.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+// StartCleanup периодически удаляет истекшие ключи идемпотентности
+func (s *IdempotencyService) StartCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeleteExpired(); err != nil {
+				slog.Error("Ошибка очистки ключей идемпотентности", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"SystemContorlBackend/internal/importer"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Колонки файла импорта: код и русское название (как в выгрузке)
+var (
+	importColProjectName  = []string{"name", "Название"}
+	importColDescription  = []string{"description", "Описание"}
+	importColAddress      = []string{"address", "Адрес"}
+	importColStatus       = []string{"status", "Статус"}
+	importColStartDate    = []string{"start_date", "Дата начала"}
+	importColEndDate      = []string{"end_date", "Дата окончания"}
+	importColCreatorEmail = []string{"creator_email", "Email автора"}
+
+	importColProjectID     = []string{"project_id", "ID проекта"}
+	importColProject       = []string{"project", "Проект"}
+	importColTitle         = []string{"title", "Название"}
+	importColLocation      = []string{"location", "Место"}
+	importColPriority      = []string{"priority", "Приоритет"}
+	importColCategory      = []string{"category", "Категория"}
+	importColSeverity      = []string{"severity", "Критичность"}
+	importColDueDate       = []string{"due_date", "Срок устранения"}
+	importColAssigneeEmail = []string{"assignee_email", "Email исполнителя"}
+)
+
+// ImportService - загрузка проектов и дефектов из таблиц Excel и CSV
+type ImportService struct {
+	db     *gorm.DB
+	sla    *SLAService
+	events *EventBus
+}
+
+// NewImportService создает сервис импорта на подключении db; sla рассчитывает сроки
+// импортированных дефектов, о созданных записях сообщается в events
+func NewImportService(db *gorm.DB, sla *SLAService, events *EventBus) *ImportService {
+	return &ImportService{db: db, sla: sla, events: events}
+}
+
+// importLookup кэширует справочники, чтобы не обращаться к БД на каждую строку
+type importLookup struct {
+	db         *gorm.DB
+	users      map[string]*models.User
+	projects   map[string][]models.Project
+	categories map[string]uint
+	severities map[string]uint
+}
+
+func newImportLookup(db *gorm.DB) (*importLookup, error) {
+	lookup := &importLookup{
+		db:         db,
+		users:      make(map[string]*models.User),
+		projects:   make(map[string][]models.Project),
+		categories: make(map[string]uint),
+		severities: make(map[string]uint),
+	}
+
+	var categories []models.DefectCategory
+	if err := db.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		lookup.categories[strings.ToLower(category.Code)] = category.ID
+		lookup.categories[strings.ToLower(category.Name)] = category.ID
+	}
+
+	var severities []models.SeverityLevel
+	if err := db.Find(&severities).Error; err != nil {
+		return nil, err
+	}
+	for _, severity := range severities {
+		lookup.severities[strings.ToLower(severity.Code)] = severity.ID
+		lookup.severities[strings.ToLower(severity.Name)] = severity.ID
+	}
+
+	return lookup, nil
+}
+
+// user находит активного пользователя по email
+func (l *importLookup) user(email string) (*models.User, error) {
+	key := strings.ToLower(email)
+	if user, ok := l.users[key]; ok {
+		if user == nil {
+			return nil, fmt.Errorf("пользователь %s не найден", email)
+		}
+		return user, nil
+	}
+
+	var user models.User
+	if err := l.db.Where("LOWER(email) = ? AND is_active = ?", key, true).First(&user).Error; err != nil {
+		l.users[key] = nil
+		return nil, fmt.Errorf("пользователь %s не найден", email)
+	}
+	l.users[key] = &user
+	return &user, nil
+}
+
+// project находит проект по ID или по точному названию
+func (l *importLookup) project(idValue, name string) (uint, error) {
+	if idValue != "" {
+		id, err := strconv.ParseUint(idValue, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("неверный ID проекта: %s", idValue)
+		}
+		var project models.Project
+		if err := l.db.First(&project, id).Error; err != nil {
+			return 0, fmt.Errorf("проект %d не найден", id)
+		}
+		return project.ID, nil
+	}
+
+	if name == "" {
+		return 0, nil
+	}
+
+	key := strings.ToLower(name)
+	projects, ok := l.projects[key]
+	if !ok {
+		l.db.Where("LOWER(name) = ?", key).Find(&projects)
+		l.projects[key] = projects
+	}
+	switch len(projects) {
+	case 0:
+		return 0, fmt.Errorf("проект «%s» не найден", name)
+	case 1:
+		return projects[0].ID, nil
+	}
+	return 0, fmt.Errorf("несколько проектов с названием «%s», укажите ID проекта", name)
+}
+
+// Projects проверяет строки файла и, если ошибок нет и это не пробный прогон,
+// создает все проекты в одной транзакции
+func (s *ImportService) Projects(ctx context.Context, table *importer.Table, importedBy uint, dryRun bool) (*models.ImportResult, error) {
+	lookup, err := newImportLookup(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{DryRun: dryRun, Total: len(table.Rows), Errors: []models.ImportRowError{}}
+	projects := make([]models.Project, 0, len(table.Rows))
+
+	for _, row := range table.Rows {
+		var rowErrors []string
+
+		data := models.ProjectCreate{
+			Name:        table.Value(row, importColProjectName...),
+			Description: table.Value(row, importColDescription...),
+			Address:     table.Value(row, importColAddress...),
+		}
+		if data.StartDate, err = importer.ParseDate(table.Value(row, importColStartDate...)); err != nil {
+			rowErrors = append(rowErrors, "start_date: "+err.Error())
+		}
+		if data.EndDate, err = importer.ParseDate(table.Value(row, importColEndDate...)); err != nil {
+			rowErrors = append(rowErrors, "end_date: "+err.Error())
+		}
+		rowErrors = append(rowErrors, validateStruct(data)...)
+
+		if data.StartDate != nil && data.EndDate != nil && data.EndDate.Before(*data.StartDate) {
+			rowErrors = append(rowErrors, "end_date: дата окончания раньше даты начала")
+		}
+
+		status := models.ProjectStatusActive
+		if value := table.Value(row, importColStatus...); value != "" {
+			if status = codeByTitle(value, projectStatusTitles); status == "" {
+				rowErrors = append(rowErrors, "status: неизвестный статус "+value)
+			}
+		}
+
+		createdBy := importedBy
+		if email := table.Value(row, importColCreatorEmail...); email != "" {
+			if user, err := lookup.user(email); err != nil {
+				rowErrors = append(rowErrors, "creator_email: "+err.Error())
+			} else {
+				createdBy = user.ID
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, models.ImportRowError{Row: row.Number, Errors: rowErrors})
+			continue
+		}
+
+		result.Valid++
+		projects = append(projects, models.Project{
+			Name:        data.Name,
+			Description: data.Description,
+			Address:     data.Address,
+			Status:      status,
+			StartDate:   data.StartDate,
+			EndDate:     data.EndDate,
+			CreatedBy:   createdBy,
+		})
+	}
+
+	if dryRun || len(result.Errors) > 0 || len(projects) == 0 {
+		return result, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&projects, 200).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(projects)
+
+	for i := range projects {
+		s.events.Emit(DomainEvent{
+			Type:       models.EventProjectCreated,
+			ProjectID:  projects[i].ID,
+			EntityType: models.EntityTypeProject,
+			EntityID:   projects[i].ID,
+			ActorID:    importedBy,
+			Payload:    &projects[i],
+			Context:    ctx,
+		})
+	}
+
+	return result, nil
+}
+
+// Defects проверяет строки файла и, если ошибок нет и это не пробный прогон,
+// создает все дефекты в одной транзакции
+func (s *ImportService) Defects(ctx context.Context, table *importer.Table, importedBy uint, dryRun bool) (*models.ImportResult, error) {
+	lookup, err := newImportLookup(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{DryRun: dryRun, Total: len(table.Rows), Errors: []models.ImportRowError{}}
+	defects := make([]models.Defect, 0, len(table.Rows))
+	now := time.Now()
+
+	for _, row := range table.Rows {
+		var rowErrors []string
+
+		projectID, projectErr := lookup.project(table.Value(row, importColProjectID...), table.Value(row, importColProject...))
+		if projectErr != nil {
+			rowErrors = append(rowErrors, "project: "+projectErr.Error())
+		}
+
+		data := models.DefectCreate{
+			ProjectID:   projectID,
+			Title:       table.Value(row, importColTitle...),
+			Description: table.Value(row, importColDescription...),
+			Location:    table.Value(row, importColLocation...),
+		}
+		if value := table.Value(row, importColPriority...); value != "" {
+			data.Priority = codeByTitle(value, defectPriorityTitles)
+			if data.Priority == "" {
+				rowErrors = append(rowErrors, "priority: неизвестный приоритет "+value)
+			}
+		}
+		if value := table.Value(row, importColCategory...); value != "" {
+			if id, ok := lookup.categories[strings.ToLower(value)]; ok {
+				data.CategoryID = &id
+			} else {
+				rowErrors = append(rowErrors, "category: неизвестная категория "+value)
+			}
+		}
+		if value := table.Value(row, importColSeverity...); value != "" {
+			if id, ok := lookup.severities[strings.ToLower(value)]; ok {
+				data.SeverityID = &id
+			} else {
+				rowErrors = append(rowErrors, "severity: неизвестный уровень критичности "+value)
+			}
+		}
+		if data.DueDate, err = importer.ParseDate(table.Value(row, importColDueDate...)); err != nil {
+			rowErrors = append(rowErrors, "due_date: "+err.Error())
+		}
+		for _, message := range validateStruct(data) {
+			// Ненайденный проект уже описан выше
+			if projectErr != nil && strings.HasPrefix(message, "project_id:") {
+				continue
+			}
+			rowErrors = append(rowErrors, message)
+		}
+
+		status := models.DefectStatusNew
+		if value := table.Value(row, importColStatus...); value != "" {
+			if status = codeByTitle(value, defectStatusTitles); status == "" {
+				rowErrors = append(rowErrors, "status: неизвестный статус "+value)
+			}
+		}
+
+		createdBy := importedBy
+		if email := table.Value(row, importColCreatorEmail...); email != "" {
+			if user, err := lookup.user(email); err != nil {
+				rowErrors = append(rowErrors, "creator_email: "+err.Error())
+			} else {
+				createdBy = user.ID
+			}
+		}
+
+		var assigneeID *uint
+		if email := table.Value(row, importColAssigneeEmail...); email != "" {
+			if user, err := lookup.user(email); err != nil {
+				rowErrors = append(rowErrors, "assignee_email: "+err.Error())
+			} else {
+				assigneeID = &user.ID
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, models.ImportRowError{Row: row.Number, Errors: rowErrors})
+			continue
+		}
+
+		priority := data.Priority
+		if priority == "" {
+			priority = models.DefectPriorityNormal
+		}
+		dueDate := data.DueDate
+		if dueDate == nil {
+			dueDate = s.sla.CalculateDueDate(now, data.SeverityID)
+		}
+
+		defect := models.Defect{
+			ProjectID:      data.ProjectID,
+			Title:          data.Title,
+			Description:    data.Description,
+			Location:       data.Location,
+			Status:         status,
+			Priority:       priority,
+			CategoryID:     data.CategoryID,
+			SeverityID:     data.SeverityID,
+			AssigneeUserID: assigneeID,
+			DueDate:        dueDate,
+			CreatedBy:      createdBy,
+		}
+		if status == models.DefectStatusClosed {
+			defect.ClosedAt = &now
+		}
+
+		result.Valid++
+		defects = append(defects, defect)
+	}
+
+	if dryRun || len(result.Errors) > 0 || len(defects) == 0 {
+		return result, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Norms").CreateInBatches(&defects, 200).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(defects)
+
+	for i := range defects {
+		s.events.Emit(DomainEvent{
+			Type:       models.EventDefectCreated,
+			ProjectID:  defects[i].ProjectID,
+			EntityType: models.EntityTypeDefect,
+			EntityID:   defects[i].ID,
+			ActorID:    importedBy,
+			Payload:    &defects[i],
+			Context:    ctx,
+		})
+	}
+
+	return result, nil
+}
+
+// codeByTitle возвращает код значения по коду или его русскому названию (пусто - не найдено)
+func codeByTitle(value string, titles map[string]string) string {
+	for code, title := range titles {
+		if strings.EqualFold(value, code) || strings.EqualFold(value, title) {
+			return code
+		}
+	}
+	return ""
+}
@@ -2,10 +2,9 @@ package services
 
 import (
 	"errors"
-	"os"
-	"strconv"
 	"time"
 
+	"SystemContorlBackend/internal/config"
 	"SystemContorlBackend/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,11 +18,24 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// GenerateToken генерирует JWT токен для пользователя
-func GenerateToken(user *models.User) (string, error) {
-	expireHours, _ := strconv.Atoi(os.Getenv("JWT_EXPIRE_HOURS"))
-	if expireHours == 0 {
-		expireHours = 24
+// TokenService выпускает и проверяет JWT токены доступа
+type TokenService struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewTokenService создает сервис токенов с секретом и сроком жизни из конфигурации
+func NewTokenService(cfg config.JWTConfig) *TokenService {
+	return &TokenService{
+		secret: []byte(cfg.Secret),
+		ttl:    time.Duration(cfg.ExpireHours) * time.Hour,
+	}
+}
+
+// Generate генерирует JWT токен для пользователя
+func (s *TokenService) Generate(user *models.User) (string, error) {
+	if len(s.secret) == 0 {
+		return "", errors.New("не задан секрет подписи токенов")
 	}
 
 	claims := &Claims{
@@ -31,13 +43,13 @@ func GenerateToken(user *models.User) (string, error) {
 		Email:    user.Email,
 		RoleCode: user.Role.Code,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expireHours) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	tokenString, err := token.SignedString(s.secret)
 	if err != nil {
 		return "", err
 	}
@@ -45,13 +57,17 @@ func GenerateToken(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken проверяет и парсит JWT токен
-func ValidateToken(tokenString string) (*Claims, error) {
+// Validate проверяет и парсит JWT токен
+func (s *TokenService) Validate(tokenString string) (*Claims, error) {
+	if len(s.secret) == 0 {
+		return nil, errors.New("не задан секрет подписи токенов")
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
 		}
-		return []byte(os.Getenv("JWT_SECRET")), nil
+		return s.secret, nil
 	})
 
 	if err != nil {
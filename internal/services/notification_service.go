@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// NotificationService - уведомления пользователей о событиях их дефектов
+type NotificationService struct {
+	db     *gorm.DB
+	events *EventBus
+}
+
+// NewNotificationService создает сервис уведомлений на подключении db;
+// о созданных уведомлениях сообщается в events
+func NewNotificationService(db *gorm.DB, events *EventBus) *NotificationService {
+	return &NotificationService{db: db, events: events}
+}
+
+// Названия статусов дефекта для текстов уведомлений
+var defectStatusTitles = map[string]string{
+	models.DefectStatusNew:        "Новый",
+	models.DefectStatusInProgress: "В работе",
+	models.DefectStatusReview:     "На проверке",
+	models.DefectStatusClosed:     "Закрыт",
+	models.DefectStatusCancelled:  "Отменен",
+}
+
+// HandleEvent формирует уведомления получателям доменного события (подписчик EventBus)
+func (s *NotificationService) HandleEvent(event DomainEvent) {
+	var recipients []uint
+	var title, message string
+
+	switch event.Type {
+	case models.EventDefectAssigned:
+		defect, ok := event.Payload.(*models.Defect)
+		if !ok {
+			return
+		}
+		recipients = s.defectAssigneeIDs(defect)
+		title = "Вам назначен дефект"
+		message = fmt.Sprintf("Дефект «%s» назначен вам на устранение", defect.Title)
+
+	case models.EventDefectStatusChanged:
+		defect, ok := event.Payload.(*models.Defect)
+		if !ok {
+			return
+		}
+		recipients = append(s.defectAssigneeIDs(defect), defect.CreatedBy)
+		title = "Изменен статус дефекта"
+		message = fmt.Sprintf("Дефект «%s»: статус «%s»", defect.Title, defectStatusTitles[defect.Status])
+
+	case models.EventDefectDeadlineApproaching:
+		defect, ok := event.Payload.(*models.Defect)
+		if !ok {
+			return
+		}
+		recipients = s.defectAssigneeIDs(defect)
+		title = "Приближается срок устранения"
+		message = fmt.Sprintf("Срок устранения дефекта «%s» истекает %s",
+			defect.Title, defect.DueDate.Format("02.01.2006 15:04"))
+
+	case models.EventDefectEscalated:
+		defect, ok := event.Payload.(*models.Defect)
+		if !ok || defect.EscalatedTo == nil {
+			return
+		}
+		recipients = []uint{*defect.EscalatedTo}
+		title = "Просрочен дефект"
+		message = fmt.Sprintf("Срок устранения дефекта «%s» нарушен", defect.Title)
+
+	case models.EventCommentMentioned:
+		comment, ok := event.Payload.(*models.Comment)
+		if !ok {
+			return
+		}
+		for _, user := range comment.Mentions {
+			recipients = append(recipients, user.ID)
+		}
+		title = "Вас упомянули в комментарии"
+		message = comment.Text
+
+	case models.EventFileUploaded:
+		attachment, ok := event.Payload.(*models.Attachment)
+		if !ok || attachment.EntityType != models.EntityTypeDefect {
+			return
+		}
+		var defect models.Defect
+		if err := s.db.First(&defect, attachment.EntityID).Error; err != nil {
+			return
+		}
+		recipients = append(s.defectAssigneeIDs(&defect), defect.CreatedBy)
+		title = "Новый файл в дефекте"
+		message = fmt.Sprintf("К дефекту «%s» загружен файл %s", defect.Title, attachment.OriginalName)
+
+	default:
+		return
+	}
+
+	seen := map[uint]bool{event.ActorID: true}
+	for _, userID := range recipients {
+		if userID == 0 || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		notification := models.Notification{
+			UserID:     userID,
+			Type:       event.Type,
+			Title:      title,
+			Message:    message,
+			ProjectID:  event.ProjectID,
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			ActorID:    event.ActorID,
+		}
+		if err := s.db.Create(&notification).Error; err != nil {
+			logging.FromContext(event.Context).Error("Не удалось создать уведомление", "user_id", userID, "error", err)
+			continue
+		}
+
+		s.events.Emit(DomainEvent{
+			Type:       models.EventNotificationCreated,
+			ProjectID:  notification.ProjectID,
+			EntityType: "notification",
+			EntityID:   notification.ID,
+			ActorID:    event.ActorID,
+			UserID:     userID,
+			Payload:    &notification,
+			Context:    event.Context,
+		})
+	}
+}
+
+// defectAssigneeIDs возвращает исполнителей дефекта: назначенного инженера
+// или, если назначена только организация, всех ее сотрудников
+func (s *NotificationService) defectAssigneeIDs(defect *models.Defect) []uint {
+	if defect.AssigneeUserID != nil {
+		return []uint{*defect.AssigneeUserID}
+	}
+	if defect.AssigneeOrgID == nil {
+		return nil
+	}
+
+	var ids []uint
+	s.db.Model(&models.User{}).
+		Where("organization_id = ? AND is_active = ?", *defect.AssigneeOrgID, true).
+		Pluck("id", &ids)
+	return ids
+}
+
+// NotificationListSchema - поля уведомлений, доступные для сортировки, фильтров и выборки
+var NotificationListSchema = listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":          {Column: "notifications.id", Type: listquery.Number},
+		"type":        {Column: "notifications.type", Type: listquery.String},
+		"project_id":  {Column: "notifications.project_id", Type: listquery.Number},
+		"entity_type": {Column: "notifications.entity_type", Type: listquery.String},
+		"read_at":     {Column: "notifications.read_at", Type: listquery.Time},
+		"created_at":  {Column: "notifications.created_at", Type: listquery.Time},
+	},
+	Selectable: []string{"user_id", "type", "title", "message", "project_id", "entity_type", "entity_id",
+		"actor_id", "read_at", "created_at"},
+	DefaultSort:  "-created_at",
+	IDColumn:     "notifications.id",
+	DefaultLimit: 20,
+}
+
+// List получает уведомления пользователя и количество непрочитанных
+func (s *NotificationService) List(userID uint, unreadOnly bool, options *listquery.Options) ([]models.Notification, *listquery.PageInfo, int64, error) {
+	var notifications []models.Notification
+
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	page, err := options.Find(query, &notifications)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	unread, err := s.CountUnread(userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return notifications, page, unread, nil
+}
+
+// CountUnread считает непрочитанные уведомления пользователя
+func (s *NotificationService) CountUnread(userID uint) (int64, error) {
+	var unread int64
+	err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unread).Error
+	return unread, err
+}
+
+// MarkRead отмечает уведомление прочитанным
+func (s *NotificationService) MarkRead(id, userID uint) error {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return errors.New("уведомление не найдено")
+	}
+
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	return s.db.Model(&notification).Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead отмечает все уведомления пользователя прочитанными
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// OrganizationService - подрядные и заказывающие организации и их сотрудники
+type OrganizationService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationService создает сервис организаций на подключении db
+func NewOrganizationService(db *gorm.DB) *OrganizationService {
+	return &OrganizationService{db: db}
+}
+
+// List получает список организаций с фильтром по типу
+func (s *OrganizationService) List(orgType string) ([]models.Organization, error) {
+	var organizations []models.Organization
+	query := s.db.Order("name")
+	if orgType != "" {
+		query = query.Where("type = ?", orgType)
+	}
+	if err := query.Find(&organizations).Error; err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// GetByID получает организацию вместе с ее сотрудниками
+func (s *OrganizationService) GetByID(id uint) (*models.Organization, error) {
+	var organization models.Organization
+	if err := s.db.Preload("Users.Role").First(&organization, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("организация не найдена")
+		}
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// Create создает организацию
+func (s *OrganizationService) Create(input models.OrganizationInput) (*models.Organization, error) {
+	if err := validateINN(input.INN); err != nil {
+		return nil, err
+	}
+
+	var existing models.Organization
+	if err := s.db.Where("inn = ?", input.INN).First(&existing).Error; err == nil {
+		return nil, errors.New("организация с таким ИНН уже существует")
+	}
+
+	organization := models.Organization{
+		Name:         input.Name,
+		INN:          input.INN,
+		Type:         input.Type,
+		ContactName:  input.ContactName,
+		ContactPhone: input.ContactPhone,
+		ContactEmail: input.ContactEmail,
+	}
+	if err := s.db.Create(&organization).Error; err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// Update обновляет данные организации
+func (s *OrganizationService) Update(id uint, input models.OrganizationInput) (*models.Organization, error) {
+	if err := validateINN(input.INN); err != nil {
+		return nil, err
+	}
+
+	var organization models.Organization
+	if err := s.db.First(&organization, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("организация не найдена")
+		}
+		return nil, err
+	}
+
+	var existing models.Organization
+	if err := s.db.Where("inn = ? AND id <> ?", input.INN, id).First(&existing).Error; err == nil {
+		return nil, errors.New("организация с таким ИНН уже существует")
+	}
+
+	organization.Name = input.Name
+	organization.INN = input.INN
+	organization.Type = input.Type
+	organization.ContactName = input.ContactName
+	organization.ContactPhone = input.ContactPhone
+	organization.ContactEmail = input.ContactEmail
+
+	if err := s.db.Save(&organization).Error; err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// Delete удаляет организацию, если за ней нет открытых дефектов
+func (s *OrganizationService) Delete(id uint) error {
+	var organization models.Organization
+	if err := s.db.First(&organization, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("организация не найдена")
+		}
+		return err
+	}
+
+	var openDefects int64
+	s.db.Model(&models.Defect{}).
+		Where("assignee_org_id = ? AND status NOT IN ?", id,
+			[]string{models.DefectStatusClosed, models.DefectStatusCancelled}).
+		Count(&openDefects)
+	if openDefects > 0 {
+		return errors.New("за организацией числятся незакрытые дефекты")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("organization_id = ?", id).
+			Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&organization).Error
+	})
+}
+
+// AddMember привязывает пользователя к организации
+func (s *OrganizationService) AddMember(organizationID, userID uint) error {
+	var organization models.Organization
+	if err := s.db.First(&organization, organizationID).Error; err != nil {
+		return errors.New("организация не найдена")
+	}
+
+	result := s.db.Model(&models.User{}).Where("id = ?", userID).
+		Update("organization_id", organizationID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("пользователь не найден")
+	}
+	return nil
+}
+
+// RemoveMember отвязывает пользователя от организации
+func (s *OrganizationService) RemoveMember(organizationID, userID uint) error {
+	result := s.db.Model(&models.User{}).
+		Where("id = ? AND organization_id = ?", userID, organizationID).
+		Update("organization_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("пользователь не состоит в организации")
+	}
+	return nil
+}
+
+// validateINN проверяет ИНН: 10 цифр для юрлица или 12 для ИП, с контрольными разрядами
+func validateINN(inn string) error {
+	digits := make([]int, len(inn))
+	for i, r := range inn {
+		if r < '0' || r > '9' {
+			return errors.New("ИНН должен состоять из цифр")
+		}
+		digits[i] = int(r - '0')
+	}
+
+	checksum := func(weights []int) int {
+		sum := 0
+		for i, w := range weights {
+			sum += w * digits[i]
+		}
+		return sum % 11 % 10
+	}
+
+	switch len(digits) {
+	case 10:
+		if checksum([]int{2, 4, 10, 3, 5, 9, 4, 6, 8}) == digits[9] {
+			return nil
+		}
+	case 12:
+		if checksum([]int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == digits[10] &&
+			checksum([]int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == digits[11] {
+			return nil
+		}
+	default:
+		return errors.New("ИНН должен содержать 10 или 12 цифр")
+	}
+	return errors.New("неверная контрольная сумма ИНН")
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ProjectMemberService - участники проектов и доступ пользователей к проектам
+type ProjectMemberService struct {
+	db *gorm.DB
+}
+
+// NewProjectMemberService создает сервис участников проектов на подключении db
+func NewProjectMemberService(db *gorm.DB) *ProjectMemberService {
+	return &ProjectMemberService{db: db}
+}
+
+// List получает участников проекта
+func (s *ProjectMemberService) List(projectID uint) ([]models.ProjectMember, error) {
+	var members []models.ProjectMember
+	err := s.db.Preload("User.Role").
+		Where("project_id = ?", projectID).
+		Order("created_at").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Add добавляет пользователя в участники проекта
+func (s *ProjectMemberService) Add(projectID, userID, addedBy uint) (*models.ProjectMember, error) {
+	var project models.Project
+	if err := s.db.First(&project, projectID).Error; err != nil {
+		return nil, errors.New("проект не найден")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, errors.New("пользователь не найден")
+	}
+
+	var existing models.ProjectMember
+	if err := s.db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&existing).Error; err == nil {
+		return nil, errors.New("пользователь уже участвует в проекте")
+	}
+
+	member := models.ProjectMember{
+		ProjectID: projectID,
+		UserID:    userID,
+		AddedBy:   addedBy,
+	}
+	if err := s.db.Create(&member).Error; err != nil {
+		return nil, err
+	}
+
+	s.db.Preload("User.Role").First(&member, member.ID)
+	return &member, nil
+}
+
+// Remove исключает пользователя из участников проекта
+func (s *ProjectMemberService) Remove(projectID, userID uint) error {
+	result := s.db.Where("project_id = ? AND user_id = ?", projectID, userID).
+		Delete(&models.ProjectMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("пользователь не участвует в проекте")
+	}
+	return nil
+}
+
+// AccessibleProjectIDs возвращает проекты, доступные пользователю.
+// Менеджеры и наблюдатели видят все проекты (all = true); инженер - проекты,
+// в которых он участник, создатель или исполнитель дефектов (лично или через организацию).
+func (s *ProjectMemberService) AccessibleProjectIDs(userID uint, roleCode string) (ids []uint, all bool, err error) {
+	if roleCode == models.RoleManager || roleCode == models.RoleObserver {
+		return nil, true, nil
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, false, errors.New("пользователь не найден")
+	}
+
+	assigned := s.db.Model(&models.Defect{}).Select("project_id").Where("assignee_user_id = ?", userID)
+	if user.OrganizationID != nil {
+		assigned = assigned.Or("assignee_org_id = ?", *user.OrganizationID)
+	}
+
+	err = s.db.Model(&models.Project{}).
+		Where("created_by = ?", userID).
+		Or("id IN (?)", s.db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID)).
+		Or("id IN (?)", assigned).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return ids, false, nil
+}
+
+// AccessibleProjectScope возвращает ограничение списков проектами, доступными пользователю:
+// nil - все проекты, иначе ID доступных проектов (пустой срез - ни одного)
+func (s *ProjectMemberService) AccessibleProjectScope(userID uint, roleCode string) ([]uint, error) {
+	ids, all, err := s.AccessibleProjectIDs(userID, roleCode)
+	if err != nil || all {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []uint{}
+	}
+	return ids, nil
+}
+
+// CanAccessProject проверяет, доступен ли проект пользователю
+func (s *ProjectMemberService) CanAccessProject(userID uint, roleCode string, projectID uint) bool {
+	ids, all, err := s.AccessibleProjectIDs(userID, roleCode)
+	if err != nil {
+		return false
+	}
+	if all {
+		return true
+	}
+	for _, id := range ids {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
@@ -1,16 +1,36 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"SystemContorlBackend/internal/database"
+	"strings"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/mergepatch"
 	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/repository"
 	"gorm.io/gorm"
 )
 
-// CreateProject создает новый проект
-func CreateProject(projectData models.ProjectCreate, createdBy uint) (*models.Project, error) {
-	project := models.Project{
+// ErrProjectNotFound - проекта нет или он удален
+var ErrProjectNotFound = errors.New("проект не найден")
+
+// ProjectService - работа с проектами
+type ProjectService struct {
+	projects repository.ProjectRepository
+	files    *FileService
+	events   *EventBus
+}
+
+// NewProjectService создает сервис проектов; files удаляет вложения удаляемых проектов,
+// об изменениях сообщается в events
+func NewProjectService(projects repository.ProjectRepository, files *FileService, events *EventBus) *ProjectService {
+	return &ProjectService{projects: projects, files: files, events: events}
+}
+
+// Create создает новый проект
+func (s *ProjectService) Create(ctx context.Context, projectData models.ProjectCreate, createdBy uint) (*models.Project, error) {
+	project := &models.Project{
 		Name:        projectData.Name,
 		Description: projectData.Description,
 		Address:     projectData.Address,
@@ -20,110 +40,200 @@ func CreateProject(projectData models.ProjectCreate, createdBy uint) (*models.Pr
 		CreatedBy:   createdBy,
 	}
 
-	if err := database.DB.Create(&project).Error; err != nil {
+	if err := s.projects.Create(project); err != nil {
 		return nil, err
 	}
 
 	// Загружаем информацию о создателе
-	database.DB.Preload("Creator").First(&project, project.ID)
-
-	return &project, nil
+	s.reloadCreator(project)
+
+	s.events.Emit(DomainEvent{
+		Type:       models.EventProjectCreated,
+		ProjectID:  project.ID,
+		EntityType: models.EntityTypeProject,
+		EntityID:   project.ID,
+		ActorID:    createdBy,
+		Payload:    project,
+		Context:    ctx,
+	})
+
+	return project, nil
 }
 
-// GetProjects получает список проектов с фильтрацией
-func GetProjects(status string, limit, offset int) ([]models.Project, int64, error) {
-	var projects []models.Project
-	var total int64
+// ProjectListSchema - поля проектов, доступные для сортировки, фильтров и выборки
+var ProjectListSchema = listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":         {Column: "projects.id", Type: listquery.Number},
+		"name":       {Column: "projects.name", Type: listquery.String},
+		"address":    {Column: "projects.address", Type: listquery.String},
+		"status":     {Column: "projects.status", Type: listquery.String},
+		"start_date": {Column: "projects.start_date", Type: listquery.Time},
+		"end_date":   {Column: "projects.end_date", Type: listquery.Time},
+		"creator_id": {Column: "projects.created_by", Type: listquery.Number},
+		"created_at": {Column: "projects.created_at", Type: listquery.Time},
+		"updated_at": {Column: "projects.updated_at", Type: listquery.Time},
+	},
+	Selectable: []string{"name", "description", "address", "status", "start_date", "end_date",
+		"created_by", "creator", "created_at", "updated_at"},
+	DefaultSort:  "-created_at",
+	IDColumn:     "projects.id",
+	DefaultLimit: 10,
+}
 
-	query := database.DB.Model(&models.Project{}).Preload("Creator")
+// List получает список проектов с фильтрацией, сортировкой и пагинацией;
+// projectIDs ограничивает список доступными пользователю проектами (nil - все проекты)
+func (s *ProjectService) List(status string, projectIDs []uint, options *listquery.Options) ([]models.Project, *listquery.PageInfo, error) {
+	return s.projects.List(status, projectIDs, options)
+}
 
-	// Фильтр по статусу
-	if status != "" {
-		query = query.Where("status = ?", status)
+// GetByID получает проект по ID
+func (s *ProjectService) GetByID(id uint) (*models.Project, error) {
+	project, err := s.projects.FindWithCreator(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
 	}
+	return project, nil
+}
 
-	// Подсчет общего количества
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+// find загружает проект без связей, отсутствие проекта - ErrProjectNotFound
+func (s *ProjectService) find(id uint) (*models.Project, error) {
+	project, err := s.projects.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
 	}
+	return project, nil
+}
 
-	// Получение записей с пагинацией
-	if err := query.Offset(offset).Limit(limit).Find(&projects).Error; err != nil {
-		return nil, 0, err
+// reloadCreator подгружает создателя проекта для ответа
+func (s *ProjectService) reloadCreator(project *models.Project) {
+	if loaded, err := s.projects.FindWithCreator(project.ID); err == nil {
+		*project = *loaded
 	}
-
-	return projects, total, nil
 }
 
-// GetProjectByID получает проект по ID
-func GetProjectByID(id uint) (*models.Project, error) {
-	var project models.Project
-	if err := database.DB.Preload("Creator").First(&project, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("проект не найден")
-		}
+// Update заменяет все редактируемые поля проекта.
+// version - версия из If-Match (0 - не проверять); одновременное изменение другим пользователем
+// возвращает ошибку конфликта вместо перезаписи.
+func (s *ProjectService) Update(ctx context.Context, id uint, updateData models.ProjectUpdate, updatedBy uint, version uint) (*models.Project, error) {
+	project, err := s.find(id)
+	if err != nil {
 		return nil, err
 	}
-	return &project, nil
-}
-
-// UpdateProject обновляет проект
-func UpdateProject(id uint, updateData models.ProjectUpdate) (*models.Project, error) {
-	var project models.Project
-	if err := database.DB.First(&project, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("проект не найден")
-		}
+	if err := checkVersion(project.Version, version); err != nil {
 		return nil, err
 	}
 
-	// Обновляем только переданные поля
-	if updateData.Name != "" {
-		project.Name = updateData.Name
+	// PUT заменяет все редактируемые поля; частичное изменение - через Patch
+	project.Name = updateData.Name
+	project.Description = updateData.Description
+	project.Address = updateData.Address
+	project.Status = updateData.Status
+	project.StartDate = updateData.StartDate
+	project.EndDate = updateData.EndDate
+
+	if err := s.projects.Update(project); err != nil {
+		return nil, err
 	}
-	if updateData.Description != "" {
-		project.Description = updateData.Description
+
+	// Загружаем информацию о создателе
+	s.reloadCreator(project)
+
+	s.events.Emit(DomainEvent{
+		Type:       models.EventProjectUpdated,
+		ProjectID:  project.ID,
+		EntityType: models.EntityTypeProject,
+		EntityID:   project.ID,
+		ActorID:    updatedBy,
+		Payload:    project,
+		Context:    ctx,
+	})
+
+	return project, nil
+}
+
+// Patch изменяет проект по JSON Merge Patch (RFC 7386): null очищает поле.
+// Результат проверяется по тем же правилам, что и при создании проекта.
+func (s *ProjectService) Patch(ctx context.Context, id uint, patch []byte, updatedBy uint, version uint) (*models.Project, error) {
+	project, err := s.find(id)
+	if err != nil {
+		return nil, err
 	}
-	if updateData.Address != "" {
-		project.Address = updateData.Address
+	if err := checkVersion(project.Version, version); err != nil {
+		return nil, err
 	}
-	if updateData.Status != "" {
-		project.Status = updateData.Status
+
+	fields := models.ProjectPatch{
+		ProjectCreate: models.ProjectCreate{
+			Name:        project.Name,
+			Description: project.Description,
+			Address:     project.Address,
+			StartDate:   project.StartDate,
+			EndDate:     project.EndDate,
+		},
+		Status: project.Status,
 	}
-	if updateData.StartDate != nil {
-		project.StartDate = updateData.StartDate
+	if err := mergepatch.Apply(&fields, patch); err != nil {
+		return nil, invalidInput(err.Error())
 	}
-	if updateData.EndDate != nil {
-		project.EndDate = updateData.EndDate
+	if errs := validateStruct(fields); len(errs) > 0 {
+		return nil, invalidInput(strings.Join(errs, "; "))
 	}
 
-	if err := database.DB.Save(&project).Error; err != nil {
+	project.Name = fields.Name
+	project.Description = fields.Description
+	project.Address = fields.Address
+	project.StartDate = fields.StartDate
+	project.EndDate = fields.EndDate
+	project.Status = fields.Status
+
+	if err := s.projects.Update(project); err != nil {
 		return nil, err
 	}
 
-	// Загружаем информацию о создателе
-	database.DB.Preload("Creator").First(&project, project.ID)
+	s.reloadCreator(project)
 
-	return &project, nil
+	s.events.Emit(DomainEvent{
+		Type:       models.EventProjectUpdated,
+		ProjectID:  project.ID,
+		EntityType: models.EntityTypeProject,
+		EntityID:   project.ID,
+		ActorID:    updatedBy,
+		Payload:    project,
+		Context:    ctx,
+	})
+
+	return project, nil
 }
 
-// DeleteProject удаляет проект (мягкое удаление)
-func DeleteProject(id uint) error {
-	var project models.Project
-	if err := database.DB.First(&project, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New("проект не найден")
-		}
+// Delete удаляет проект (мягкое удаление)
+func (s *ProjectService) Delete(ctx context.Context, id uint, deletedBy uint) error {
+	project, err := s.find(id)
+	if err != nil {
 		return err
 	}
 
-	if err := DeleteAttachmentsByEntity(models.EntityTypeProject, id); err != nil {
-        fmt.Printf("Предупреждение при удалении файлов проекта: %v\n", err)
-    }
-
-    if err := database.DB.Delete(&project).Error; err != nil {
-        return errors.New("не удалось удалить проект")
-    }
-
-    return nil
-}
\ No newline at end of file
+	// Дефекты проекта и все файлы удаляются вместе с проектом, с диска - после фиксации
+	attachments, err := s.projects.Delete(project)
+	if err != nil {
+		return errors.New("не удалось удалить проект")
+	}
+	s.files.RemoveFiles(ctx, attachments)
+
+	s.events.Emit(DomainEvent{
+		Type:       models.EventProjectDeleted,
+		ProjectID:  project.ID,
+		EntityType: models.EntityTypeProject,
+		EntityID:   project.ID,
+		ActorID:    deletedBy,
+		Payload:    project,
+		Context:    ctx,
+	})
+
+	return nil
+}
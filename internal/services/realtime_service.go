@@ -0,0 +1,44 @@
+package services
+
+import (
+	"encoding/json"
+
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/realtime"
+)
+
+// realtimePayload - данные события, передаваемые клиентам в потоке
+type realtimePayload struct {
+	EntityType string      `json:"entity_type"`
+	EntityID   uint        `json:"entity_id"`
+	ActorID    uint        `json:"actor_id"`
+	Payload    interface{} `json:"payload"`
+}
+
+// RealtimePublisher возвращает подписчика EventBus, пересылающего доменные события
+// в шину реального времени broker
+func RealtimePublisher(broker realtime.Broker) EventHandler {
+	return func(event DomainEvent) {
+		data, err := json.Marshal(realtimePayload{
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			ActorID:    event.ActorID,
+			Payload:    event.Payload,
+		})
+		if err != nil {
+			logging.FromContext(event.Context).Error("Не удалось сериализовать событие", "event", event.Type, "error", err)
+			return
+		}
+
+		err = broker.Publish(realtime.Message{
+			Type:      event.Type,
+			ProjectID: event.ProjectID,
+			UserID:    event.UserID,
+			Data:      data,
+			CreatedAt: event.OccurredAt,
+		})
+		if err != nil {
+			logging.FromContext(event.Context).Error("Не удалось опубликовать событие", "event", event.Type, "error", err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/models"
+	"SystemContorlBackend/internal/reports"
+	"gorm.io/gorm"
+)
+
+const (
+	// Максимум дефектов в одном акте
+	reportDefectLimit = 1000
+
+	// Максимум фотографий одного дефекта в акте
+	reportPhotoLimit = 4
+)
+
+// ReportService - печатные акты осмотра
+type ReportService struct {
+	db *gorm.DB
+}
+
+// NewReportService создает сервис актов на подключении db
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// WriteDefectReport формирует акт осмотра проекта по дефектам, отобранным фильтром, и пишет PDF в w
+func (s *ReportService) WriteDefectReport(ctx context.Context, w io.Writer, projectID uint, filter models.DefectFilter, generatedBy uint) error {
+	var project models.Project
+	if err := s.db.First(&project, projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	filter.ProjectID = projectID
+	var defects []models.Defect
+	query := s.db.Model(&models.Defect{}).Preload("Category").Preload("Severity")
+	if err := applyDefectFilter(query, filter).Order("defects.created_at").Limit(reportDefectLimit).Find(&defects).Error; err != nil {
+		return err
+	}
+
+	report := reports.DefectReport{
+		ProjectName: project.Name,
+		Address:     project.Address,
+		Period:      formatPeriod(project.StartDate, project.EndDate),
+		Filters:     describeDefectFilter(filter),
+		GeneratedAt: time.Now(),
+	}
+
+	var author models.User
+	if err := s.db.First(&author, generatedBy).Error; err == nil {
+		report.GeneratedBy = strings.TrimSpace(author.LastName + " " + author.FirstName)
+	}
+
+	for _, defect := range defects {
+		row := reports.DefectRow{
+			Title:       defect.Title,
+			Description: defect.Description,
+			Location:    defect.Location,
+			Status:      defectStatusTitles[defect.Status],
+			Photos:      s.defectPhotos(ctx, defect.ID),
+		}
+		if defect.Category != nil {
+			row.Category = defect.Category.Name
+		}
+		if defect.Severity != nil {
+			row.Severity = defect.Severity.Name
+		}
+		if defect.DueDate != nil {
+			row.DueDate = defect.DueDate.Format("02.01.2006")
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	return reports.WriteDefectsPDF(w, report)
+}
+
+// defectPhotos загружает миниатюры первых фотографий дефекта; недоступные файлы пропускаются
+func (s *ReportService) defectPhotos(ctx context.Context, defectID uint) [][]byte {
+	var attachments []models.Attachment
+	s.db.Where("entity_type = ? AND entity_id = ? AND file_type = ?",
+		models.EntityTypeDefect, defectID, models.FileTypeImage).
+		Order("created_at").Limit(reportPhotoLimit).Find(&attachments)
+
+	var photos [][]byte
+	for _, attachment := range attachments {
+		thumbnail, err := reports.Thumbnail(attachment.FilePath)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Не удалось подготовить фото для акта", "attachment_id", attachment.ID, "error", err)
+			continue
+		}
+		photos = append(photos, thumbnail)
+	}
+	return photos
+}
+
+// formatPeriod форматирует сроки работ проекта
+func formatPeriod(start, end *time.Time) string {
+	switch {
+	case start != nil && end != nil:
+		return start.Format("02.01.2006") + " – " + end.Format("02.01.2006")
+	case start != nil:
+		return "с " + start.Format("02.01.2006")
+	case end != nil:
+		return "по " + end.Format("02.01.2006")
+	}
+	return ""
+}
+
+// describeDefectFilter описывает условия отбора дефектов для шапки акта
+func describeDefectFilter(filter models.DefectFilter) string {
+	var parts []string
+
+	if filter.Status != "" {
+		var titles []string
+		for _, status := range strings.Split(filter.Status, ",") {
+			if title, ok := defectStatusTitles[status]; ok {
+				titles = append(titles, title)
+			}
+		}
+		parts = append(parts, "статус: "+strings.Join(titles, ", "))
+	}
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		var to *time.Time
+		if filter.CreatedTo != nil {
+			// Верхняя граница фильтра исключающая, в акте показываем последний день периода
+			last := filter.CreatedTo.AddDate(0, 0, -1)
+			to = &last
+		}
+		parts = append(parts, "выявлены "+formatPeriod(filter.CreatedFrom, to))
+	}
+	if filter.Location != "" {
+		parts = append(parts, "место: "+filter.Location)
+	}
+	if filter.Overdue {
+		parts = append(parts, "только просроченные")
+	}
+
+	if len(parts) == 0 {
+		return "все дефекты"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Сколько представлений считается за один запрос GET /views/counts
+const savedViewCountLimit = 50
+
+// Параметры навигации, которые не сохраняются в представлении
+var savedViewSkippedParams = []string{"page", "limit", "cursor", "with_total", "view"}
+
+// SavedViewService - сохраненные представления списков (фильтры и сортировка)
+type SavedViewService struct {
+	db      *gorm.DB
+	members *ProjectMemberService
+}
+
+// NewSavedViewService создает сервис представлений на подключении db;
+// members ограничивает представления и счетчики доступными проектами
+func NewSavedViewService(db *gorm.DB, members *ProjectMemberService) *SavedViewService {
+	return &SavedViewService{db: db, members: members}
+}
+
+// List возвращает представления пользователя и общие представления доступных ему проектов.
+// Закрепленные идут первыми.
+func (s *SavedViewService) List(userID uint, roleCode, entityType string) ([]models.SavedView, error) {
+	query, err := s.visibleSavedViews(userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	var views []models.SavedView
+	if err := query.Order("name, id").Find(&views).Error; err != nil {
+		return nil, err
+	}
+	if err := s.markPinnedViews(views, userID); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(views, func(i, j int) bool {
+		return views[i].Pinned && !views[j].Pinned
+	})
+	return views, nil
+}
+
+// GetByID возвращает представление, если оно доступно пользователю
+func (s *SavedViewService) GetByID(id, userID uint, roleCode string) (*models.SavedView, error) {
+	query, err := s.visibleSavedViews(userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var view models.SavedView
+	if err := query.First(&view, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("представление не найдено")
+		}
+		return nil, err
+	}
+
+	views := []models.SavedView{view}
+	if err := s.markPinnedViews(views, userID); err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// Create сохраняет новое представление
+func (s *SavedViewService) Create(input models.SavedViewInput, userID uint, roleCode string) (*models.SavedView, error) {
+	query, err := s.validateInput(input, userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	view := models.SavedView{
+		UserID:     userID,
+		EntityType: input.EntityType,
+		Name:       strings.TrimSpace(input.Name),
+		Query:      query,
+		ProjectID:  input.ProjectID,
+		Shared:     input.Shared,
+	}
+	if err := s.db.Create(&view).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// Update изменяет представление (только владелец)
+func (s *SavedViewService) Update(id uint, input models.SavedViewInput, userID uint, roleCode string) (*models.SavedView, error) {
+	view, err := s.GetByID(id, userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+	if view.UserID != userID {
+		return nil, errors.New("недостаточно прав для изменения представления")
+	}
+
+	query, err := s.validateInput(input, userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	view.EntityType = input.EntityType
+	view.Name = strings.TrimSpace(input.Name)
+	view.Query = query
+	view.ProjectID = input.ProjectID
+	view.Shared = input.Shared
+	if err := s.db.Save(view).Error; err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// Delete удаляет представление вместе с закреплениями (только владелец)
+func (s *SavedViewService) Delete(id, userID uint, roleCode string) error {
+	view, err := s.GetByID(id, userID, roleCode)
+	if err != nil {
+		return err
+	}
+	if view.UserID != userID {
+		return errors.New("недостаточно прав для удаления представления")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("saved_view_id = ?", view.ID).Delete(&models.SavedViewPin{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(view).Error
+	})
+}
+
+// Pin закрепляет представление у пользователя
+func (s *SavedViewService) Pin(id, userID uint, roleCode string) error {
+	if _, err := s.GetByID(id, userID, roleCode); err != nil {
+		return err
+	}
+	pin := models.SavedViewPin{UserID: userID, SavedViewID: id}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&pin).Error
+}
+
+// Unpin снимает закрепление представления
+func (s *SavedViewService) Unpin(id, userID uint) error {
+	return s.db.Where("user_id = ? AND saved_view_id = ?", userID, id).
+		Delete(&models.SavedViewPin{}).Error
+}
+
+// Count считает, сколько записей попадает в каждое доступное представление
+func (s *SavedViewService) Count(userID uint, roleCode, entityType string) ([]models.SavedViewCount, error) {
+	views, err := s.List(userID, roleCode, entityType)
+	if err != nil {
+		return nil, err
+	}
+	if len(views) > savedViewCountLimit {
+		views = views[:savedViewCountLimit]
+	}
+
+	// Считаются только записи доступных пользователю проектов, как в самих списках
+	scope, err := s.members.AccessibleProjectScope(userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.SavedViewCount, 0, len(views))
+	for _, view := range views {
+		values, err := url.ParseQuery(view.Query)
+		if err != nil {
+			return nil, err
+		}
+		query, err := s.listQuery(view.EntityType, values, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		count := models.SavedViewCount{ViewID: view.ID, Name: view.Name, Pinned: view.Pinned}
+		if err := query.Count(&count.Count).Error; err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// Apply подставляет параметры представления в параметры запроса списка.
+// Явно переданные в запросе параметры имеют приоритет над сохраненными.
+func (s *SavedViewService) Apply(id, userID uint, roleCode, entityType string, request url.Values) (url.Values, error) {
+	view, err := s.GetByID(id, userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+	if view.EntityType != entityType {
+		return nil, errors.New("представление относится к другому списку")
+	}
+
+	values, err := url.ParseQuery(view.Query)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range request {
+		if key != "view" {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// visibleSavedViews - запрос представлений, доступных пользователю:
+// свои и общие представления проектов, к которым у него есть доступ
+func (s *SavedViewService) visibleSavedViews(userID uint, roleCode string) (*gorm.DB, error) {
+	projectIDs, all, err := s.members.AccessibleProjectIDs(userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := s.db.Where("shared = ?", true)
+	if !all {
+		shared = shared.Where("project_id IN ?", projectIDs)
+	}
+	// Условие оборачивается в группу, чтобы OR не смешивался с последующими условиями
+	visible := s.db.Where("user_id = ?", userID).Or(shared)
+	return s.db.Model(&models.SavedView{}).Where(visible), nil
+}
+
+// markPinnedViews отмечает представления, закрепленные пользователем
+func (s *SavedViewService) markPinnedViews(views []models.SavedView, userID uint) error {
+	if len(views) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(views))
+	for i, view := range views {
+		ids[i] = view.ID
+	}
+
+	var pinned []uint
+	if err := s.db.Model(&models.SavedViewPin{}).
+		Where("user_id = ? AND saved_view_id IN ?", userID, ids).
+		Pluck("saved_view_id", &pinned).Error; err != nil {
+		return err
+	}
+
+	set := make(map[uint]bool, len(pinned))
+	for _, id := range pinned {
+		set[id] = true
+	}
+	for i := range views {
+		views[i].Pinned = set[views[i].ID]
+	}
+	return nil
+}
+
+// validateInput проверяет доступ к проекту и параметры представления
+// и возвращает нормализованную query string
+func (s *SavedViewService) validateInput(input models.SavedViewInput, userID uint, roleCode string) (string, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return "", errors.New("не указано название представления")
+	}
+	if input.Shared && input.ProjectID == nil {
+		return "", errors.New("для общего представления нужно указать проект")
+	}
+	if input.ProjectID != nil && !s.members.CanAccessProject(userID, roleCode, *input.ProjectID) {
+		return "", errors.New("проект не найден")
+	}
+
+	values, err := url.ParseQuery(strings.TrimPrefix(input.Query, "?"))
+	if err != nil {
+		return "", errors.New("неверный формат параметров представления")
+	}
+	for _, key := range savedViewSkippedParams {
+		values.Del(key)
+	}
+	if input.EntityType == models.SavedViewEntityDefect && input.ProjectID != nil {
+		values.Set("project_id", strconv.FormatUint(uint64(*input.ProjectID), 10))
+	}
+
+	if _, err := s.listQuery(input.EntityType, values, nil); err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// listQuery строит запрос к списку с фильтрами представления так же, как списочные эндпоинты;
+// projectIDs ограничивает записи доступными проектами (nil - все)
+func (s *SavedViewService) listQuery(entityType string, values url.Values, projectIDs []uint) (*gorm.DB, error) {
+	switch entityType {
+	case models.SavedViewEntityProject:
+		options, err := listquery.Parse(ProjectListSchema, values)
+		if err != nil {
+			return nil, err
+		}
+		query := s.db.Model(&models.Project{})
+		if status := values.Get("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if projectIDs != nil {
+			query = query.Where("projects.id IN ?", projectIDs)
+		}
+		return options.Where(query), nil
+
+	case models.SavedViewEntityDefect:
+		options, err := listquery.Parse(DefectListSchema, values)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := ParseDefectFilter(values)
+		if err != nil {
+			return nil, err
+		}
+		filter.ProjectIDs = projectIDs
+		return options.Where(applyDefectFilter(s.db.Model(&models.Defect{}), filter)), nil
+	}
+	return nil, errors.New("неизвестный тип списка: " + entityType)
+}
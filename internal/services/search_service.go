@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Запрос ищется сразу в русской и английской конфигурациях; websearch_to_tsquery
+// понимает привычный синтаксис: "точная фраза", -исключение, or
+const searchQueryExpr = "(websearch_to_tsquery('russian', ?) || websearch_to_tsquery('english', ?))"
+
+// Маркеры совпадений в ts_headline; заменяются на <mark> после экранирования HTML
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+var headlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop +
+	`", MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "`
+
+// SearchTypes - типы записей, по которым выполняется поиск
+var SearchTypes = []string{models.SearchTypeProject, models.SearchTypeDefect, models.SearchTypeComment, models.SearchTypeFile}
+
+// SearchService - полнотекстовый поиск по записям доступных пользователю проектов
+type SearchService struct {
+	db      *gorm.DB
+	members *ProjectMemberService
+}
+
+// NewSearchService создает сервис поиска на подключении db; members определяет доступные проекты
+func NewSearchService(db *gorm.DB, members *ProjectMemberService) *SearchService {
+	return &SearchService{db: db, members: members}
+}
+
+// Search ищет по проектам, дефектам, комментариям и именам файлов, доступным пользователю.
+// Результаты всех типов объединяются и сортируются по релевантности.
+func (s *SearchService) Search(q string, types []string, userID uint, roleCode string, limit int) ([]models.SearchResult, error) {
+	q = strings.TrimSpace(q)
+	if utf8.RuneCountInString(q) < 2 {
+		return nil, errors.New("поисковый запрос должен содержать не менее 2 символов")
+	}
+	if len(types) == 0 {
+		types = SearchTypes
+	}
+
+	projectIDs, all, err := s.members.AccessibleProjectIDs(userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ограничение по доступным проектам; для менеджеров и наблюдателей не нужно
+	restrict := func(query *gorm.DB, column string) *gorm.DB {
+		if all {
+			return query
+		}
+		return query.Where(column+" IN ?", projectIDs)
+	}
+
+	var results []models.SearchResult
+	for _, searchType := range types {
+		var rows []models.SearchResult
+		var query *gorm.DB
+
+		switch searchType {
+		case models.SearchTypeProject:
+			query = s.db.Model(&models.Project{}).
+				Select("projects.id, projects.id AS project_id, projects.name AS title, "+
+					"ts_rank(projects.search_vector, "+searchQueryExpr+") AS rank, "+
+					"ts_headline('russian', concat_ws(' · ', projects.name, projects.address, projects.description), "+searchQueryExpr+", ?) AS snippet",
+					q, q, q, q, headlineOptions).
+				Where("projects.search_vector @@ "+searchQueryExpr, q, q)
+			query = restrict(query, "projects.id")
+
+		case models.SearchTypeDefect:
+			query = s.db.Model(&models.Defect{}).
+				Select("defects.id, defects.project_id, defects.title, "+
+					"ts_rank(defects.search_vector, "+searchQueryExpr+") AS rank, "+
+					"ts_headline('russian', concat_ws(' · ', defects.title, defects.location, defects.description), "+searchQueryExpr+", ?) AS snippet",
+					q, q, q, q, headlineOptions).
+				Where("defects.search_vector @@ "+searchQueryExpr, q, q)
+			query = restrict(query, "defects.project_id")
+
+		case models.SearchTypeComment:
+			query = s.db.Model(&models.Comment{}).
+				Joins("JOIN defects ON defects.id = comments.defect_id AND defects.deleted_at IS NULL").
+				Select("comments.id, defects.project_id, comments.defect_id, defects.title, "+
+					"ts_rank(comments.search_vector, "+searchQueryExpr+") AS rank, "+
+					"ts_headline('russian', comments.text, "+searchQueryExpr+", ?) AS snippet",
+					q, q, q, q, headlineOptions).
+				Where("comments.search_vector @@ "+searchQueryExpr, q, q)
+			query = restrict(query, "defects.project_id")
+
+		case models.SearchTypeFile:
+			// Файл принадлежит проекту напрямую или через дефект
+			projectColumn := "CASE WHEN attachments.entity_type = '" + models.EntityTypeProject +
+				"' THEN attachments.entity_id ELSE defects.project_id END"
+			query = s.db.Model(&models.Attachment{}).
+				Joins("LEFT JOIN defects ON attachments.entity_type = ? AND defects.id = attachments.entity_id AND defects.deleted_at IS NULL",
+					models.EntityTypeDefect).
+				Select(projectColumn+" AS project_id, attachments.id, defects.id AS defect_id, attachments.original_name AS title, "+
+					"ts_rank(attachments.search_vector, "+searchQueryExpr+") AS rank, "+
+					"ts_headline('russian', attachments.original_name, "+searchQueryExpr+", ?) AS snippet",
+					q, q, q, q, headlineOptions).
+				Where("attachments.search_vector @@ "+searchQueryExpr, q, q).
+				Where("attachments.entity_type = ? OR defects.id IS NOT NULL", models.EntityTypeProject)
+			query = restrict(query, projectColumn)
+
+		default:
+			return nil, errors.New("неизвестный тип поиска: " + searchType)
+		}
+
+		if err := query.Order("rank DESC").Limit(limit).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			rows[i].Type = searchType
+			rows[i].Snippet = highlightSnippet(rows[i].Snippet)
+		}
+		results = append(results, rows...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// highlightSnippet экранирует HTML в фрагменте и оборачивает совпадения в <mark>
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightStop, "</mark>")
+}
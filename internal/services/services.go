@@ -0,0 +1,85 @@
+package services
+
+import (
+	"time"
+
+	"SystemContorlBackend/internal/config"
+	"SystemContorlBackend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Config - настройки сервисов приложения
+type Config struct {
+	JWT             config.JWTConfig
+	UploadDir       string        // Каталог загруженных файлов
+	AppURL          string        // Адрес веб-приложения для ссылок в письмах (может быть пустым)
+	CheckMigrations bool          // Проверять готовность по примененным SQL-миграциям
+	IdempotencyTTL  time.Duration // Сколько хранятся ответы на запросы с Idempotency-Key
+}
+
+// Services - сервисы приложения на одном подключении к БД и шина их доменных событий.
+// Подписчики событий (уведомления, webhook, письма, поток реального времени)
+// регистрируются в Events при запуске приложения.
+type Services struct {
+	Events *EventBus
+
+	Tokens        *TokenService
+	Users         *UserService
+	Files         *FileService
+	Projects      *ProjectService
+	Members       *ProjectMemberService
+	SLA           *SLAService
+	Defects       *DefectService
+	Comments      *CommentService
+	Sync          *SyncService
+	Catalog       *CatalogService
+	Organizations *OrganizationService
+	Notifications *NotificationService
+	Emails        *EmailService
+	Webhooks      *WebhookService
+	SavedViews    *SavedViewService
+	Search        *SearchService
+	Stats         *StatsService
+	Exports       *ExportService
+	Reports       *ReportService
+	Imports       *ImportService
+	Health        *HealthService
+	Idempotency   *IdempotencyService
+}
+
+// New собирает сервисы на подключении db с новой шиной событий без подписчиков
+func New(db *gorm.DB, cfg Config) *Services {
+	events := NewEventBus()
+	tokens := NewTokenService(cfg.JWT)
+	files := NewFileService(repository.NewAttachmentRepository(db), cfg.UploadDir, events)
+	members := NewProjectMemberService(db)
+	sla := NewSLAService(db, events)
+	defects := NewDefectService(db, files, repository.NewUserRepository(db), sla, events)
+	comments := NewCommentService(db, events)
+
+	return &Services{
+		Events:        events,
+		Tokens:        tokens,
+		Users:         NewUserService(repository.NewUserRepository(db), tokens),
+		Files:         files,
+		Projects:      NewProjectService(repository.NewProjectRepository(db), files, events),
+		Members:       members,
+		SLA:           sla,
+		Defects:       defects,
+		Comments:      comments,
+		Sync:          NewSyncService(db, defects, comments, members),
+		Catalog:       NewCatalogService(db),
+		Organizations: NewOrganizationService(db),
+		Notifications: NewNotificationService(db, events),
+		Emails:        NewEmailService(db, cfg.AppURL),
+		Webhooks:      NewWebhookService(db),
+		SavedViews:    NewSavedViewService(db, members),
+		Search:        NewSearchService(db, members),
+		Stats:         NewStatsService(db),
+		Exports:       NewExportService(db),
+		Reports:       NewReportService(db),
+		Imports:       NewImportService(db, sla, events),
+		Health:        NewHealthService(db, cfg.UploadDir, cfg.CheckMigrations),
+		Idempotency:   NewIdempotencyService(db, cfg.IdempotencyTTL),
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+// SLAAtRiskShare - доля оставшегося срока, ниже которой дефект считается "под угрозой"
+const SLAAtRiskShare = 0.25
+
+// SLAService - сроки устранения дефектов, производственный календарь и контроль просрочек
+type SLAService struct {
+	db     *gorm.DB
+	events *EventBus
+}
+
+// NewSLAService создает сервис сроков на подключении db; о предупреждениях
+// и эскалациях сообщается в events
+func NewSLAService(db *gorm.DB, events *EventBus) *SLAService {
+	return &SLAService{db: db, events: events}
+}
+
+// AddWorkingDays прибавляет к дате рабочие дни, пропуская выходные и праздники
+func AddWorkingDays(start time.Time, days int, holidays map[string]bool) time.Time {
+	result := start
+	for added := 0; added < days; {
+		result = result.AddDate(0, 0, 1)
+		if isWorkingDay(result, holidays) {
+			added++
+		}
+	}
+	return result
+}
+
+// isWorkingDay проверяет, является ли день рабочим
+func isWorkingDay(day time.Time, holidays map[string]bool) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return !holidays[day.Format("2006-01-02")]
+}
+
+// loadHolidaySet загружает праздничные дни начиная с указанной даты
+func (s *SLAService) loadHolidaySet(from time.Time) map[string]bool {
+	var holidays []models.Holiday
+	s.db.Where("date >= ?", from.Format("2006-01-02")).Find(&holidays)
+
+	set := make(map[string]bool, len(holidays))
+	for _, holiday := range holidays {
+		set[holiday.Date.Format("2006-01-02")] = true
+	}
+	return set
+}
+
+// CalculateDueDate рассчитывает срок устранения по уровню критичности
+func (s *SLAService) CalculateDueDate(start time.Time, severityID *uint) *time.Time {
+	if severityID == nil {
+		return nil
+	}
+
+	var severity models.SeverityLevel
+	if err := s.db.First(&severity, *severityID).Error; err != nil {
+		return nil
+	}
+
+	dueDate := AddWorkingDays(start, severity.FixDays, s.loadHolidaySet(start))
+	return &dueDate
+}
+
+// ComputeSLAState определяет состояние SLA дефекта на момент now
+func ComputeSLAState(defect *models.Defect, now time.Time) string {
+	if defect.DueDate == nil {
+		return ""
+	}
+
+	// Для закрытых дефектов оцениваем, уложились ли в срок
+	if defect.Status == models.DefectStatusClosed || defect.Status == models.DefectStatusCancelled {
+		if defect.ClosedAt != nil && defect.ClosedAt.After(*defect.DueDate) {
+			return models.SLAStateBreached
+		}
+		return models.SLAStateOnTrack
+	}
+
+	if now.After(*defect.DueDate) {
+		return models.SLAStateBreached
+	}
+
+	total := defect.DueDate.Sub(defect.CreatedAt)
+	remaining := defect.DueDate.Sub(now)
+	if total <= 0 || float64(remaining) < float64(total)*SLAAtRiskShare {
+		return models.SLAStateAtRisk
+	}
+	return models.SLAStateOnTrack
+}
+
+// fillSLAStates заполняет вычисляемое состояние SLA для списка дефектов
+func fillSLAStates(defects []models.Defect) {
+	now := time.Now()
+	for i := range defects {
+		defects[i].SLAState = ComputeSLAState(&defects[i], now)
+	}
+}
+
+// EscalateBreachedDefects передает менеджерам проектов дефекты с нарушенным сроком
+func (s *SLAService) EscalateBreachedDefects(ctx context.Context) (int, error) {
+	var defects []models.Defect
+	err := s.db.Preload("Project").
+		Where("due_date < ? AND escalated_at IS NULL", time.Now()).
+		Where("status NOT IN ?", []string{models.DefectStatusClosed, models.DefectStatusCancelled}).
+		Find(&defects).Error
+	if err != nil {
+		return 0, err
+	}
+
+	escalated := 0
+	for _, defect := range defects {
+		if defect.Project == nil {
+			continue
+		}
+
+		now := time.Now()
+		managerID := defect.Project.CreatedBy
+		// Условие escalated_at IS NULL в самом UPDATE: если дефект уже эскалировал
+		// другой экземпляр API, строка не обновится и событие не отправится повторно
+		result := s.db.Model(&models.Defect{}).Where("id = ? AND escalated_at IS NULL", defect.ID).
+			Updates(map[string]interface{}{
+				"escalated_at": now,
+				"escalated_to": managerID,
+				"version":      gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			logging.FromContext(ctx).Error("Не удалось эскалировать дефект", "defect_id", defect.ID, "error", result.Error)
+			continue
+		}
+		if result.RowsAffected != 1 {
+			continue
+		}
+
+		logging.FromContext(ctx).Info("Дефект просрочен и передан менеджеру проекта", "defect_id", defect.ID, "manager_id", managerID)
+		escalated++
+
+		defect.EscalatedAt = &now
+		defect.EscalatedTo = &managerID
+		defect.Version++
+		defect.SLAState = models.SLAStateBreached
+		s.events.Emit(DomainEvent{
+			Type:       models.EventDefectEscalated,
+			ProjectID:  defect.ProjectID,
+			EntityType: models.EntityTypeDefect,
+			EntityID:   defect.ID,
+			Payload:    &defect,
+			Context:    ctx,
+		})
+	}
+
+	return escalated, nil
+}
+
+// WarnApproachingDeadlines предупреждает исполнителей о дефектах, срок которых подходит к концу
+func (s *SLAService) WarnApproachingDeadlines(ctx context.Context) (int, error) {
+	var defects []models.Defect
+	now := time.Now()
+	err := s.db.
+		Where("due_date > ? AND deadline_warned_at IS NULL", now).
+		Where("assignee_user_id IS NOT NULL OR assignee_org_id IS NOT NULL").
+		Where("status NOT IN ?", []string{models.DefectStatusClosed, models.DefectStatusCancelled}).
+		Find(&defects).Error
+	if err != nil {
+		return 0, err
+	}
+
+	warned := 0
+	for _, defect := range defects {
+		if ComputeSLAState(&defect, now) != models.SLAStateAtRisk {
+			continue
+		}
+
+		// Предупреждение отправляет только тот экземпляр API, который первым сделал отметку
+		result := s.db.Model(&models.Defect{}).Where("id = ? AND deadline_warned_at IS NULL", defect.ID).
+			Update("deadline_warned_at", now)
+		if result.Error != nil {
+			logging.FromContext(ctx).Error("Не удалось отметить предупреждение по дефекту", "defect_id", defect.ID, "error", result.Error)
+			continue
+		}
+		if result.RowsAffected != 1 {
+			continue
+		}
+		warned++
+
+		defect.SLAState = models.SLAStateAtRisk
+		s.events.Emit(DomainEvent{
+			Type:       models.EventDefectDeadlineApproaching,
+			ProjectID:  defect.ProjectID,
+			EntityType: models.EntityTypeDefect,
+			EntityID:   defect.ID,
+			Payload:    &defect,
+			Context:    ctx,
+		})
+	}
+
+	return warned, nil
+}
+
+// StartScheduler периодически проверяет сроки дефектов до отмены контекста
+func (s *SLAService) StartScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.WarnApproachingDeadlines(ctx); err != nil {
+				slog.Error("Ошибка проверки сроков дефектов", "error", err)
+			}
+			if _, err := s.EscalateBreachedDefects(ctx); err != nil {
+				slog.Error("Ошибка проверки сроков дефектов", "error", err)
+			}
+		}
+	}
+}
+
+// GetHolidays возвращает праздничные дни за год (0 - все)
+func (s *SLAService) GetHolidays(year int) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	query := s.db.Order("date")
+	if year != 0 {
+		query = query.Where("date >= ? AND date < ?",
+			time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	if err := query.Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+// CreateHoliday добавляет праздничный день в календарь
+func (s *SLAService) CreateHoliday(input models.HolidayInput) (*models.Holiday, error) {
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, errors.New("неверный формат даты")
+	}
+
+	var existing models.Holiday
+	if err := s.db.Where("date = ?", input.Date).First(&existing).Error; err == nil {
+		return nil, errors.New("этот день уже отмечен как праздничный")
+	}
+
+	holiday := models.Holiday{Date: date, Name: input.Name}
+	if err := s.db.Create(&holiday).Error; err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// DeleteHoliday удаляет праздничный день из календаря
+func (s *SLAService) DeleteHoliday(id uint) error {
+	result := s.db.Delete(&models.Holiday{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("праздничный день не найден")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+)
+
+func TestAddWorkingDays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 10, 0, 0, 0, time.UTC) }
+	// 13 марта 2026 - пятница, 16 марта - понедельник
+	holidays := map[string]bool{"2026-03-17": true}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		days     int
+		holidays map[string]bool
+		want     time.Time
+	}{
+		{"ноль дней", day(13), 0, nil, day(13)},
+		{"внутри недели", day(10), 2, nil, day(12)},
+		{"через выходные", day(13), 1, nil, day(16)},
+		{"старт в выходной", day(14), 1, nil, day(16)},
+		{"через праздник", day(13), 2, holidays, day(18)},
+		{"праздник в выходной не сдвигает срок", day(13), 1, map[string]bool{"2026-03-15": true}, day(16)},
+		{"две недели", day(13), 10, nil, day(27)},
+	}
+	for _, tt := range tests {
+		if got := AddWorkingDays(tt.start, tt.days, tt.holidays); !got.Equal(tt.want) {
+			t.Errorf("%s: срок %v, ожидался %v", tt.name, got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestComputeSLAState(t *testing.T) {
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	due := created.Add(100 * time.Hour)
+	at := func(hours int) time.Time { return created.Add(time.Duration(hours) * time.Hour) }
+	defect := func(status string, dueDate, closedAt *time.Time) *models.Defect {
+		return &models.Defect{Status: status, DueDate: dueDate, ClosedAt: closedAt, CreatedAt: created}
+	}
+	closedLate, closedInTime := at(101), at(99)
+
+	tests := []struct {
+		name   string
+		defect *models.Defect
+		now    time.Time
+		want   string
+	}{
+		{"без срока", defect(models.DefectStatusNew, nil, nil), at(200), ""},
+		{"в начале срока", defect(models.DefectStatusNew, &due, nil), at(10), models.SLAStateOnTrack},
+		{"осталась ровно четверть", defect(models.DefectStatusInProgress, &due, nil), at(75), models.SLAStateOnTrack},
+		{"осталось меньше четверти", defect(models.DefectStatusInProgress, &due, nil), at(76), models.SLAStateAtRisk},
+		{"срок прошел", defect(models.DefectStatusReview, &due, nil), at(101), models.SLAStateBreached},
+		{"срок раньше создания", defect(models.DefectStatusNew, &created, nil), created.Add(-time.Hour), models.SLAStateAtRisk},
+		{"закрыт в срок", defect(models.DefectStatusClosed, &due, &closedInTime), at(200), models.SLAStateOnTrack},
+		{"закрыт с опозданием", defect(models.DefectStatusClosed, &due, &closedLate), at(200), models.SLAStateBreached},
+		{"отменен без даты закрытия", defect(models.DefectStatusCancelled, &due, nil), at(200), models.SLAStateOnTrack},
+	}
+	for _, tt := range tests {
+		if got := ComputeSLAState(tt.defect, tt.now); got != tt.want {
+			t.Errorf("%s: состояние %q, ожидалось %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// Сколько мест расположения показывать в разбивке
+	statsLocationLimit = 20
+
+	// Глубина недельного тренда по умолчанию и максимальная
+	statsDefaultWeeks = 12
+	statsMaxWeeks     = 52
+)
+
+// Условие "дефект не закрыт и не отменен" для агрегатов
+var statsOpenCondition = fmt.Sprintf("defects.status NOT IN ('%s', '%s')",
+	models.DefectStatusClosed, models.DefectStatusCancelled)
+
+// Столбцы количества для разбивок
+var statsCountColumns = "COUNT(*) AS count, COUNT(*) FILTER (WHERE " + statsOpenCondition + ") AS open"
+
+// StatsService - аналитика по дефектам
+type StatsService struct {
+	db *gorm.DB
+}
+
+// NewStatsService создает сервис аналитики на подключении db
+func NewStatsService(db *gorm.DB) *StatsService {
+	return &StatsService{db: db}
+}
+
+// GetDefectStats считает аналитику по дефектам агрегатными запросами.
+// Если filter.ProjectID = 0, считается по всем проектам с разбивкой по проектам.
+func (s *StatsService) GetDefectStats(filter models.StatsFilter) (*models.DefectStats, error) {
+	now := time.Now()
+	stats := &models.DefectStats{GeneratedAt: now}
+
+	if filter.ProjectID != 0 {
+		var project models.Project
+		if err := s.db.First(&project, filter.ProjectID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		stats.ProjectID = &filter.ProjectID
+	}
+
+	// Каждый запрос строится заново: условия gorm накапливаются в цепочке
+	defects := func() *gorm.DB {
+		query := s.db.Model(&models.Defect{})
+		if filter.ProjectID != 0 {
+			query = query.Where("defects.project_id = ?", filter.ProjectID)
+		}
+		if filter.CreatedFrom != nil {
+			query = query.Where("defects.created_at >= ?", *filter.CreatedFrom)
+		}
+		if filter.CreatedTo != nil {
+			query = query.Where("defects.created_at < ?", *filter.CreatedTo)
+		}
+		return query
+	}
+
+	var totals struct {
+		Total       int64
+		Open        int64
+		Closed      int64
+		Overdue     int64
+		AvgFixHours *float64
+	}
+	err := defects().Select(
+		"COUNT(*) AS total, "+
+			"COUNT(*) FILTER (WHERE "+statsOpenCondition+") AS open, "+
+			"COUNT(*) FILTER (WHERE defects.status = ?) AS closed, "+
+			"COUNT(*) FILTER (WHERE "+statsOpenCondition+" AND defects.due_date < ?) AS overdue, "+
+			"AVG(EXTRACT(EPOCH FROM defects.closed_at - defects.created_at) / 3600) "+
+			"FILTER (WHERE defects.status = ? AND defects.closed_at IS NOT NULL) AS avg_fix_hours",
+		models.DefectStatusClosed, now, models.DefectStatusClosed,
+	).Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Total = totals.Total
+	stats.Open = totals.Open
+	stats.Closed = totals.Closed
+	stats.Overdue = totals.Overdue
+	stats.AvgFixHours = totals.AvgFixHours
+
+	if err := defects().
+		Select("defects.status AS key, " + statsCountColumns).
+		Group("defects.status").Order("count DESC").
+		Scan(&stats.ByStatus).Error; err != nil {
+		return nil, err
+	}
+	for i := range stats.ByStatus {
+		stats.ByStatus[i].Label = defectStatusTitles[stats.ByStatus[i].Key]
+	}
+
+	if err := defects().
+		Joins("LEFT JOIN severity_levels ON severity_levels.id = defects.severity_id").
+		Select("COALESCE(severity_levels.code, '') AS key, COALESCE(severity_levels.name, 'Не указана') AS label, " + statsCountColumns).
+		Group("severity_levels.code, severity_levels.name, severity_levels.rank").
+		Order("severity_levels.rank DESC NULLS LAST").
+		Scan(&stats.BySeverity).Error; err != nil {
+		return nil, err
+	}
+
+	if err := defects().
+		Joins("LEFT JOIN defect_categories ON defect_categories.id = defects.category_id").
+		Select("COALESCE(defect_categories.code, '') AS key, COALESCE(defect_categories.name, 'Не указана') AS label, " + statsCountColumns).
+		Group("defect_categories.code, defect_categories.name").
+		Order("count DESC").
+		Scan(&stats.ByCategory).Error; err != nil {
+		return nil, err
+	}
+
+	// Исполнитель - инженер, а если назначена только организация - организация
+	if err := defects().
+		Joins("LEFT JOIN users ON users.id = defects.assignee_user_id").
+		Joins("LEFT JOIN organizations ON organizations.id = defects.assignee_org_id").
+		Select("CASE WHEN defects.assignee_user_id IS NOT NULL THEN 'user:' || defects.assignee_user_id " +
+			"WHEN defects.assignee_org_id IS NOT NULL THEN 'org:' || defects.assignee_org_id ELSE '' END AS key, " +
+			"COALESCE(users.last_name || ' ' || users.first_name, organizations.name, 'Не назначен') AS label, " +
+			statsCountColumns).
+		Group("1, 2").
+		Order("open DESC, count DESC").
+		Scan(&stats.ByAssignee).Error; err != nil {
+		return nil, err
+	}
+
+	if err := defects().
+		Select("COALESCE(NULLIF(TRIM(defects.location), ''), 'Не указано') AS key, " +
+			"COALESCE(NULLIF(TRIM(defects.location), ''), 'Не указано') AS label, " + statsCountColumns).
+		Group("1, 2").
+		Order("count DESC").
+		Limit(statsLocationLimit).
+		Scan(&stats.ByLocation).Error; err != nil {
+		return nil, err
+	}
+
+	if filter.ProjectID == 0 {
+		if err := defects().
+			Joins("JOIN projects ON projects.id = defects.project_id").
+			Select("CAST(projects.id AS TEXT) AS key, projects.name AS label, " + statsCountColumns).
+			Group("projects.id, projects.name").
+			Order("open DESC, count DESC").
+			Scan(&stats.ByProject).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	trend, err := s.weeklyTrend(filter, now)
+	if err != nil {
+		return nil, err
+	}
+	stats.Trend = trend
+
+	return stats, nil
+}
+
+// weeklyTrend считает выявленные и закрытые по неделям дефекты за период фильтра;
+// недели без событий заполняются нулями. Недели начинаются в понедельник в часовом поясе
+// filter.Location - и в SQL, и при заполнении пропусков.
+func (s *StatsService) weeklyTrend(filter models.StatsFilter, now time.Time) ([]models.StatsWeek, error) {
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	since, last := trendWeeks(filter, now, loc)
+	if since.After(last) {
+		return []models.StatsWeek{}, nil
+	}
+
+	// События учитываются с начала первой недели тренда, но не раньше начала периода
+	from := since
+	if filter.CreatedFrom != nil && filter.CreatedFrom.After(from) {
+		from = *filter.CreatedFrom
+	}
+
+	type weekCount struct {
+		Week  time.Time
+		Count int64
+	}
+	count := func(column string) (map[int64]int64, error) {
+		var rows []weekCount
+		query := s.db.Model(&models.Defect{}).
+			Select("date_trunc('week', defects."+column+" AT TIME ZONE ?) AS week, COUNT(*) AS count", loc.String()).
+			Where("defects."+column+" >= ?", from).
+			Group("1")
+		if filter.CreatedTo != nil {
+			query = query.Where("defects."+column+" < ?", *filter.CreatedTo)
+		}
+		if filter.ProjectID != 0 {
+			query = query.Where("defects.project_id = ?", filter.ProjectID)
+		}
+		if err := query.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		// date_trunc возвращает время без пояса: это полночь понедельника в loc
+		result := make(map[int64]int64, len(rows))
+		for _, row := range rows {
+			week := time.Date(row.Week.Year(), row.Week.Month(), row.Week.Day(), 0, 0, 0, 0, loc)
+			result[week.Unix()] = row.Count
+		}
+		return result, nil
+	}
+
+	opened, err := count("created_at")
+	if err != nil {
+		return nil, err
+	}
+	closed, err := count("closed_at")
+	if err != nil {
+		return nil, err
+	}
+
+	trend := make([]models.StatsWeek, 0, statsMaxWeeks)
+	for week := since; !week.After(last); week = week.AddDate(0, 0, 7) {
+		trend = append(trend, models.StatsWeek{
+			WeekStart: week,
+			Opened:    opened[week.Unix()],
+			Closed:    closed[week.Unix()],
+		})
+	}
+	return trend, nil
+}
+
+// trendWeeks возвращает начала первой и последней недели тренда в часовом поясе loc.
+// Последняя неделя - неделя конца периода (или текущая); первая - неделя начала периода,
+// а без него - за filter.Weeks недель до последней. Тренд не длиннее statsMaxWeeks недель.
+func trendWeeks(filter models.StatsFilter, now time.Time, loc *time.Location) (since, last time.Time) {
+	last = startOfWeek(now, loc)
+	if filter.CreatedTo != nil {
+		// Конец периода не включается: to - полночь дня после последнего
+		last = startOfWeek(filter.CreatedTo.Add(-time.Nanosecond), loc)
+	}
+
+	weeks := filter.Weeks
+	if weeks < 1 || weeks > statsMaxWeeks {
+		weeks = statsDefaultWeeks
+	}
+	since = last.AddDate(0, 0, -7*(weeks-1))
+	if filter.CreatedFrom != nil {
+		since = startOfWeek(*filter.CreatedFrom, loc)
+		if earliest := last.AddDate(0, 0, -7*(statsMaxWeeks-1)); since.Before(earliest) {
+			since = earliest
+		}
+	}
+	return since, last
+}
+
+// startOfWeek возвращает полночь понедельника недели, в которую попадает t, в часовом поясе loc
+func startOfWeek(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+}
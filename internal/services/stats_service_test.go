@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+)
+
+func TestTrendWeeksFollowsPeriodAndZone(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Воскресенье 22:30 UTC - уже понедельник в Москве
+	now := time.Date(2026, 3, 15, 22, 30, 0, 0, time.UTC)
+
+	since, last := trendWeeks(models.StatsFilter{Weeks: 2}, now, moscow)
+	if want := time.Date(2026, 3, 16, 0, 0, 0, 0, moscow); !last.Equal(want) {
+		t.Errorf("последняя неделя %v, ожидалась %v", last, want)
+	}
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, moscow); !since.Equal(want) {
+		t.Errorf("первая неделя %v, ожидалась %v", since, want)
+	}
+
+	since, last = trendWeeks(models.StatsFilter{Weeks: 2}, now, time.UTC)
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("последняя неделя в UTC %v, ожидалась %v", last, want)
+	}
+
+	// Период from/to задает границы тренда независимо от weeks и текущей даты
+	from, to, err := ParseDateRangeIn(map[string][]string{"from": {"2026-01-07"}, "to": {"2026-02-01"}}, moscow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	since, last = trendWeeks(models.StatsFilter{CreatedFrom: from, CreatedTo: to, Weeks: 2}, now, moscow)
+	if want := time.Date(2026, 1, 5, 0, 0, 0, 0, moscow); !since.Equal(want) {
+		t.Errorf("первая неделя периода %v, ожидалась %v", since, want)
+	}
+	// 1 февраля - воскресенье, последняя неделя начинается 26 января
+	if want := time.Date(2026, 1, 26, 0, 0, 0, 0, moscow); !last.Equal(want) {
+		t.Errorf("последняя неделя периода %v, ожидалась %v", last, want)
+	}
+
+	// Слишком длинный период ограничивается statsMaxWeeks неделями
+	from, _, _ = ParseDateRangeIn(map[string][]string{"from": {"2020-01-01"}}, moscow)
+	since, last = trendWeeks(models.StatsFilter{CreatedFrom: from}, now, moscow)
+	if weeks := int(last.Sub(since).Hours()/24/7) + 1; weeks != statsMaxWeeks {
+		t.Errorf("в тренде %d недель, ожидалось %d", weeks, statsMaxWeeks)
+	}
+}
@@ -0,0 +1,493 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// Изменения последних секунд отдаются в следующей синхронизации: транзакции,
+	// которые еще не зафиксированы, не должны оказаться позади выданного токена
+	syncSettleDelay = 2 * time.Second
+
+	// Количество записей каждого типа за один запрос
+	SyncDefaultLimit = 500
+	SyncMaxLimit     = 2000
+)
+
+// syncCursor - позиция в потоке изменений: время изменения и ID последней отданной записи
+type syncCursor struct {
+	T  time.Time `json:"t"`
+	ID uint      `json:"id"`
+}
+
+// syncToken - состояние синхронизации клиента по каждому типу записей
+type syncToken struct {
+	Changed map[string]syncCursor `json:"c"`
+	Deleted map[string]syncCursor `json:"d"`
+}
+
+// ErrInvalidSyncToken - токен синхронизации поврежден или выдан не этим сервером
+var ErrInvalidSyncToken = errors.New("неверный токен синхронизации")
+
+// syncEntity описывает синхронизируемую таблицу
+type syncEntity struct {
+	table string
+	// scope ограничивает записи доступными проектами; подзапросы строятся на db
+	scope func(db, query *gorm.DB, ids []uint) *gorm.DB
+}
+
+var syncEntities = map[string]syncEntity{
+	"projects": {
+		table: "projects",
+		scope: func(db, query *gorm.DB, ids []uint) *gorm.DB {
+			return query.Where("projects.id IN ?", ids)
+		},
+	},
+	"defects": {
+		table: "defects",
+		scope: func(db, query *gorm.DB, ids []uint) *gorm.DB {
+			return query.Where("defects.project_id IN ?", ids)
+		},
+	},
+	"comments": {
+		table: "comments",
+		scope: func(db, query *gorm.DB, ids []uint) *gorm.DB {
+			return query.Where("comments.defect_id IN (?)",
+				db.Unscoped().Model(&models.Defect{}).Select("id").Where("project_id IN ?", ids))
+		},
+	},
+	"attachments": {
+		table: "attachments",
+		scope: func(db, query *gorm.DB, ids []uint) *gorm.DB {
+			defects := db.Unscoped().Model(&models.Defect{}).Select("id").Where("project_id IN ?", ids)
+			return query.Where("(attachments.entity_type = ? AND attachments.entity_id IN ?) OR "+
+				"(attachments.entity_type = ? AND attachments.entity_id IN (?))",
+				models.EntityTypeProject, ids, models.EntityTypeDefect, defects)
+		},
+	},
+}
+
+// SyncService - синхронизация офлайн-клиентов; изменения дефектов применяются через defects,
+// комментариев - через comments, доступ к проектам проверяет members
+type SyncService struct {
+	db       *gorm.DB
+	defects  *DefectService
+	comments *CommentService
+	members  *ProjectMemberService
+}
+
+// NewSyncService создает сервис синхронизации на подключении db
+func NewSyncService(db *gorm.DB, defects *DefectService, comments *CommentService, members *ProjectMemberService) *SyncService {
+	return &SyncService{db: db, defects: defects, comments: comments, members: members}
+}
+
+// Delta возвращает изменения доступных пользователю проектов, дефектов,
+// комментариев и файлов с момента, зафиксированного в token.
+// Пустой token - первая синхронизация: отдаются все записи без удаленных.
+func (s *SyncService) Delta(userID uint, roleCode, token string, projectID uint, limit int) (*models.SyncDelta, error) {
+	state, err := decodeSyncToken(token)
+	if err != nil {
+		return nil, err
+	}
+	initial := token == ""
+
+	if limit < 1 || limit > SyncMaxLimit {
+		limit = SyncDefaultLimit
+	}
+
+	projectIDs, all, err := s.members.AccessibleProjectIDs(userID, roleCode)
+	if err != nil {
+		return nil, err
+	}
+	if projectID != 0 {
+		if !s.members.CanAccessProject(userID, roleCode, projectID) {
+			return nil, ErrProjectNotFound
+		}
+		projectIDs, all = []uint{projectID}, false
+	}
+	if all {
+		projectIDs = nil
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-syncSettleDelay)
+	delta := &models.SyncDelta{ServerTime: now}
+
+	more, err := syncChanged(s.db, &delta.Projects, "projects", state, projectIDs, cutoff, limit, initial)
+	if err != nil {
+		return nil, err
+	}
+	delta.HasMore = delta.HasMore || more
+
+	if more, err = syncChanged(s.db, &delta.Defects, "defects", state, projectIDs, cutoff, limit, initial); err != nil {
+		return nil, err
+	}
+	delta.HasMore = delta.HasMore || more
+
+	if more, err = syncChanged(s.db, &delta.Comments, "comments", state, projectIDs, cutoff, limit, initial); err != nil {
+		return nil, err
+	}
+	delta.HasMore = delta.HasMore || more
+
+	if more, err = syncChanged(s.db, &delta.Attachments, "attachments", state, projectIDs, cutoff, limit, initial); err != nil {
+		return nil, err
+	}
+	delta.HasMore = delta.HasMore || more
+
+	// При первой синхронизации удаленные записи не нужны, но позиция фиксируется
+	deleted := map[string]*[]uint{
+		"projects":    &delta.Projects.Deleted,
+		"defects":     &delta.Defects.Deleted,
+		"comments":    &delta.Comments.Deleted,
+		"attachments": &delta.Attachments.Deleted,
+	}
+	for name, ids := range deleted {
+		if initial {
+			state.Deleted[name] = syncCursor{T: cutoff}
+			*ids = []uint{}
+			continue
+		}
+		more, err := syncDeleted(s.db, ids, name, state, projectIDs, cutoff, limit)
+		if err != nil {
+			return nil, err
+		}
+		delta.HasMore = delta.HasMore || more
+	}
+
+	delta.SyncToken = encodeSyncToken(state)
+	return delta, nil
+}
+
+// syncChanged выбирает созданные и измененные записи типа name после позиции в state
+// и сдвигает позицию на последнюю отданную запись
+func syncChanged[T any](db *gorm.DB, changes *models.SyncChanges[T], name string, state *syncToken,
+	projectIDs []uint, cutoff time.Time, limit int, initial bool) (bool, error) {
+	entity := syncEntities[name]
+	cursor := state.Changed[name]
+
+	query := db.Model(new(T)).
+		Where(fmt.Sprintf("%[1]s.updated_at < ?", entity.table), cutoff).
+		Where(fmt.Sprintf("(%[1]s.updated_at > ? OR (%[1]s.updated_at = ? AND %[1]s.id > ?))", entity.table),
+			cursor.T, cursor.T, cursor.ID)
+	if projectIDs != nil {
+		query = entity.scope(db, query, projectIDs)
+	}
+
+	var records []T
+	err := query.Order(entity.table + ".updated_at, " + entity.table + ".id").
+		Limit(limit + 1).Find(&records).Error
+	if err != nil {
+		return false, err
+	}
+
+	more := len(records) > limit
+	if more {
+		records = records[:limit]
+	}
+
+	changes.Created, changes.Updated = []T{}, []T{}
+	for _, record := range records {
+		id, createdAt, updatedAt := syncRecordKey(record)
+		if initial || createdAt.After(cursor.T) {
+			changes.Created = append(changes.Created, record)
+		} else {
+			changes.Updated = append(changes.Updated, record)
+		}
+		state.Changed[name] = syncCursor{T: updatedAt, ID: id}
+	}
+	return more, nil
+}
+
+// syncDeleted выбирает ID записей, удаленных после позиции в state
+func syncDeleted(db *gorm.DB, ids *[]uint, name string, state *syncToken, projectIDs []uint, cutoff time.Time, limit int) (bool, error) {
+	entity := syncEntities[name]
+	cursor := state.Deleted[name]
+
+	query := db.Unscoped().Table(entity.table).
+		Select(entity.table+".id, "+entity.table+".deleted_at").
+		Where(fmt.Sprintf("%[1]s.deleted_at IS NOT NULL AND %[1]s.deleted_at < ?", entity.table), cutoff).
+		Where(fmt.Sprintf("(%[1]s.deleted_at > ? OR (%[1]s.deleted_at = ? AND %[1]s.id > ?))", entity.table),
+			cursor.T, cursor.T, cursor.ID)
+	if projectIDs != nil {
+		query = entity.scope(db, query, projectIDs)
+	}
+
+	var rows []struct {
+		ID        uint
+		DeletedAt time.Time
+	}
+	err := query.Order(entity.table + ".deleted_at, " + entity.table + ".id").
+		Limit(limit + 1).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+
+	*ids = make([]uint, 0, len(rows))
+	for _, row := range rows {
+		*ids = append(*ids, row.ID)
+		state.Deleted[name] = syncCursor{T: row.DeletedAt, ID: row.ID}
+	}
+	return more, nil
+}
+
+// syncRecordKey возвращает ID, время создания и изменения записи модели
+func syncRecordKey(record interface{}) (uint, time.Time, time.Time) {
+	value := reflect.Indirect(reflect.ValueOf(record))
+	id := uint(value.FieldByName("ID").Uint())
+	createdAt := value.FieldByName("CreatedAt").Interface().(time.Time)
+	updatedAt := value.FieldByName("UpdatedAt").Interface().(time.Time)
+	return id, createdAt, updatedAt
+}
+
+// decodeSyncToken разбирает токен синхронизации; пустой токен - начальное состояние
+func decodeSyncToken(token string) (*syncToken, error) {
+	state := &syncToken{Changed: map[string]syncCursor{}, Deleted: map[string]syncCursor{}}
+	if token == "" {
+		return state, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidSyncToken
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, ErrInvalidSyncToken
+	}
+	if state.Changed == nil {
+		state.Changed = map[string]syncCursor{}
+	}
+	if state.Deleted == nil {
+		state.Deleted = map[string]syncCursor{}
+	}
+	return state, nil
+}
+
+// encodeSyncToken кодирует состояние синхронизации для клиента
+func encodeSyncToken(state *syncToken) string {
+	data, _ := json.Marshal(state)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Apply применяет пакет изменений, накопленных на устройстве без связи.
+// Изменения применяются по одному: ошибка в одном не отменяет остальные.
+// Повторная отправка созданной записи возвращает статус duplicate с серверным ID.
+func (s *SyncService) Apply(ctx context.Context, changes []models.SyncChange, userID uint, roleCode string) []models.SyncResult {
+	results := make([]models.SyncResult, 0, len(changes))
+	for i, change := range changes {
+		result := models.SyncResult{Index: i, Entity: change.Entity, Op: change.Op, ClientID: change.ClientID}
+
+		var err error
+		switch change.Entity {
+		case "defect":
+			err = s.applyDefect(ctx, change, &result, userID, roleCode)
+		case "comment":
+			err = s.applyComment(ctx, change, &result, userID, roleCode)
+		default:
+			err = errors.New("неизвестный тип записи: " + change.Entity)
+		}
+
+		if err != nil {
+			result.Status = models.SyncStatusError
+			result.Error = err.Error()
+		} else if result.Status == "" {
+			result.Status = models.SyncStatusApplied
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// applyDefect применяет изменение дефекта
+func (s *SyncService) applyDefect(ctx context.Context, change models.SyncChange, result *models.SyncResult, userID uint, roleCode string) error {
+	if change.Op == "create" {
+		if change.ClientID == "" {
+			return errors.New("для создания записи нужен client_id")
+		}
+		if id, ok := syncExistingID(s.db, &models.Defect{}, change.ClientID); ok {
+			result.ID, result.Status = id, models.SyncStatusDuplicate
+			return nil
+		}
+
+		var input models.DefectCreate
+		if err := json.Unmarshal(change.Data, &input); err != nil {
+			return errors.New("неверный формат данных дефекта")
+		}
+		if errs := validateStruct(input); len(errs) > 0 {
+			return errors.New(errs[0])
+		}
+		if !s.members.CanAccessProject(userID, roleCode, input.ProjectID) {
+			return ErrProjectNotFound
+		}
+		input.ClientID = &change.ClientID
+
+		defect, err := s.defects.Create(ctx, input, userID)
+		if err != nil {
+			return err
+		}
+		result.ID = defect.ID
+		return nil
+	}
+
+	var defect models.Defect
+	if err := syncFindRecord(s.db, &defect, change); err != nil {
+		// Повторное удаление уже удаленной записи не считается ошибкой
+		if change.Op == "delete" && err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return ErrDefectNotFound
+	}
+	result.ID = defect.ID
+	if !s.members.CanAccessProject(userID, roleCode, defect.ProjectID) {
+		return ErrDefectNotFound
+	}
+
+	switch change.Op {
+	case "update":
+		if syncConflict(defect.UpdatedAt, change.BaseUpdatedAt) {
+			result.Status, result.Server = models.SyncStatusConflict, &defect
+			return nil
+		}
+
+		// Устройство передает только измененные поля: изменение применяется как JSON Merge Patch
+		_, err := s.defects.Patch(ctx, defect.ID, change.Data, userID, 0)
+		return err
+
+	case "delete":
+		if roleCode != models.RoleManager {
+			return errors.New("недостаточно прав для удаления дефекта")
+		}
+		return s.defects.Delete(ctx, defect.ID, userID)
+	}
+	return errors.New("неизвестная операция: " + change.Op)
+}
+
+// applyComment применяет изменение комментария. Изменять можно только комментарии
+// к дефектам доступных пользователю проектов.
+func (s *SyncService) applyComment(ctx context.Context, change models.SyncChange, result *models.SyncResult, userID uint, roleCode string) error {
+	if change.Op == "create" {
+		if change.ClientID == "" {
+			return errors.New("для создания записи нужен client_id")
+		}
+		if id, ok := syncExistingID(s.db, &models.Comment{}, change.ClientID); ok {
+			result.ID, result.Status = id, models.SyncStatusDuplicate
+			return nil
+		}
+
+		var input models.SyncCommentCreate
+		if err := json.Unmarshal(change.Data, &input); err != nil {
+			return errors.New("неверный формат данных комментария")
+		}
+		if errs := validateStruct(input); len(errs) > 0 {
+			return errors.New(errs[0])
+		}
+
+		// Дефект мог быть создан в том же пакете и известен клиенту только по client_id
+		var defect models.Defect
+		query := s.db
+		if input.DefectClientID != "" {
+			query = query.Where("client_id = ?", input.DefectClientID)
+		} else {
+			query = query.Where("id = ?", input.DefectID)
+		}
+		if err := query.First(&defect).Error; err != nil {
+			return ErrDefectNotFound
+		}
+		if !s.members.CanAccessProject(userID, roleCode, defect.ProjectID) {
+			return ErrDefectNotFound
+		}
+
+		comment, err := s.comments.Create(ctx, defect.ID, models.CommentCreate{
+			Text:       input.Text,
+			MentionIDs: input.MentionIDs,
+			ClientID:   &change.ClientID,
+		}, userID)
+		if err != nil {
+			return err
+		}
+		result.ID = comment.ID
+		return nil
+	}
+
+	var comment models.Comment
+	if err := syncFindRecord(s.db, &comment, change); err != nil {
+		// Повторное удаление уже удаленной записи не считается ошибкой
+		if change.Op == "delete" && errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return ErrCommentNotFound
+	}
+	result.ID = comment.ID
+
+	// Комментарий к дефекту недоступного проекта для пользователя не существует
+	var defect models.Defect
+	if err := s.db.Unscoped().First(&defect, comment.DefectID).Error; err != nil ||
+		!s.members.CanAccessProject(userID, roleCode, defect.ProjectID) {
+		return ErrCommentNotFound
+	}
+
+	switch change.Op {
+	case "update":
+		if syncConflict(comment.UpdatedAt, change.BaseUpdatedAt) {
+			result.Status, result.Server = models.SyncStatusConflict, &comment
+			return nil
+		}
+
+		var input models.CommentUpdate
+		if err := json.Unmarshal(change.Data, &input); err != nil {
+			return errors.New("неверный формат данных комментария")
+		}
+		if errs := validateStruct(input); len(errs) > 0 {
+			return errors.New(errs[0])
+		}
+		_, err := s.comments.Update(comment.ID, input, userID, 0)
+		return err
+
+	case "delete":
+		return s.comments.Delete(comment.ID, userID)
+	}
+	return errors.New("неизвестная операция: " + change.Op)
+}
+
+// syncFindRecord ищет запись по серверному ID или client_id изменения
+func syncFindRecord(db *gorm.DB, dest interface{}, change models.SyncChange) error {
+	switch {
+	case change.ID != 0:
+		return db.First(dest, change.ID).Error
+	case change.ClientID != "":
+		return db.Where("client_id = ?", change.ClientID).First(dest).Error
+	}
+	return errors.New("не указан id или client_id записи")
+}
+
+// syncExistingID возвращает ID записи, уже созданной с этим client_id (в том числе удаленной)
+func syncExistingID(db *gorm.DB, model interface{}, clientID string) (uint, bool) {
+	var ids []uint
+	db.Unscoped().Model(model).Where("client_id = ?", clientID).Limit(1).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return 0, false
+	}
+	return ids[0], true
+}
+
+// syncConflict проверяет, изменена ли запись на сервере после версии, которую редактировал клиент.
+// Без base_updated_at изменение применяется поверх серверной версии.
+func syncConflict(updatedAt time.Time, base *time.Time) bool {
+	if base == nil {
+		return false
+	}
+	return !updatedAt.Truncate(time.Microsecond).Equal(base.Truncate(time.Microsecond))
+}
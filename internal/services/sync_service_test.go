@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/database"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// syncFixture - проект руководителя с одним дефектом и комментарием, измененными час назад
+type syncFixture struct {
+	db       *gorm.DB
+	sync     *SyncService
+	manager  uint
+	engineer uint
+	defect   models.Defect
+	comment  models.Comment
+}
+
+func newSyncFixture(t *testing.T) *syncFixture {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "sync.db") + "?_foreign_keys=on"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Seed(db); err != nil {
+		t.Fatal(err)
+	}
+
+	user := func(email, roleCode string) uint {
+		var role models.Role
+		if err := db.Where("code = ?", roleCode).First(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		user := models.User{Email: email, Password: "-", FirstName: "Тест", LastName: "Тестов", IsActive: true, RoleID: role.ID}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user.ID
+	}
+
+	hourAgo := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	f := &syncFixture{
+		db:       db,
+		sync:     New(db, Config{UploadDir: t.TempDir()}).Sync,
+		manager:  user("manager@example.com", models.RoleManager),
+		engineer: user("engineer@example.com", models.RoleEngineer),
+	}
+	project := models.Project{Name: "ЖК Север", CreatedBy: f.manager, CreatedAt: hourAgo, UpdatedAt: hourAgo}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	f.defect = models.Defect{ProjectID: project.ID, Title: "Трещина", CreatedBy: f.manager, CreatedAt: hourAgo, UpdatedAt: hourAgo}
+	if err := db.Create(&f.defect).Error; err != nil {
+		t.Fatal(err)
+	}
+	f.comment = models.Comment{DefectID: f.defect.ID, AuthorID: f.manager, Text: "Проверить", CreatedAt: hourAgo, UpdatedAt: hourAgo}
+	if err := db.Create(&f.comment).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestSyncConflict(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 10, 0, 0, 123456789, time.UTC)
+	sameInMicroseconds := updatedAt.Truncate(time.Microsecond)
+	earlier := updatedAt.Add(-time.Second)
+
+	tests := []struct {
+		name string
+		base *time.Time
+		want bool
+	}{
+		{"без base_updated_at", nil, false},
+		{"та же версия", &updatedAt, false},
+		{"та же версия с точностью БД", &sameInMicroseconds, false},
+		{"запись изменена после base", &earlier, true},
+	}
+	for _, tt := range tests {
+		if got := syncConflict(updatedAt, tt.base); got != tt.want {
+			t.Errorf("%s: конфликт %v, ожидалось %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSyncApplyReportsConflicts(t *testing.T) {
+	stale := time.Now().Add(-2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		entity    string
+		data      string
+		base      func(f *syncFixture) *time.Time
+		engineer  bool
+		want      string
+		wantError error
+	}{
+		{
+			name:   "дефект по актуальной версии",
+			entity: "defect",
+			data:   `{"title": "Скол"}`,
+			base:   func(f *syncFixture) *time.Time { return &f.defect.UpdatedAt },
+			want:   models.SyncStatusApplied,
+		},
+		{
+			name:   "дефект без версии перезаписывается",
+			entity: "defect",
+			data:   `{"title": "Скол"}`,
+			base:   func(f *syncFixture) *time.Time { return nil },
+			want:   models.SyncStatusApplied,
+		},
+		{
+			name:   "дефект изменен на сервере",
+			entity: "defect",
+			data:   `{"title": "Скол"}`,
+			base:   func(f *syncFixture) *time.Time { return &stale },
+			want:   models.SyncStatusConflict,
+		},
+		{
+			name:   "комментарий изменен на сервере",
+			entity: "comment",
+			data:   `{"text": "Исправлено"}`,
+			base:   func(f *syncFixture) *time.Time { return &stale },
+			want:   models.SyncStatusConflict,
+		},
+		{
+			name:   "комментарий по актуальной версии",
+			entity: "comment",
+			data:   `{"text": "Исправлено"}`,
+			base:   func(f *syncFixture) *time.Time { return &f.comment.UpdatedAt },
+			want:   models.SyncStatusApplied,
+		},
+		{
+			// Конфликт не раскрывает запись проекта, к которому у пользователя нет доступа
+			name:      "конфликт в недоступном проекте",
+			entity:    "defect",
+			data:      `{"title": "Скол"}`,
+			base:      func(f *syncFixture) *time.Time { return &stale },
+			engineer:  true,
+			want:      models.SyncStatusError,
+			wantError: ErrDefectNotFound,
+		},
+	}
+	for _, tt := range tests {
+		f := newSyncFixture(t)
+		change := models.SyncChange{Entity: tt.entity, Op: "update", BaseUpdatedAt: tt.base(f), Data: json.RawMessage(tt.data)}
+		change.ID = f.defect.ID
+		if tt.entity == "comment" {
+			change.ID = f.comment.ID
+		}
+		userID, roleCode := f.manager, models.RoleManager
+		if tt.engineer {
+			userID, roleCode = f.engineer, models.RoleEngineer
+		}
+
+		results := f.sync.Apply(context.Background(), []models.SyncChange{change}, userID, roleCode)
+		if len(results) != 1 {
+			t.Fatalf("%s: получено %d результатов", tt.name, len(results))
+		}
+		result := results[0]
+		if result.Status != tt.want {
+			t.Errorf("%s: статус %q (%s), ожидался %q", tt.name, result.Status, result.Error, tt.want)
+			continue
+		}
+		if tt.wantError != nil && result.Error != tt.wantError.Error() {
+			t.Errorf("%s: ошибка %q, ожидалась %q", tt.name, result.Error, tt.wantError)
+		}
+
+		switch server := result.Server.(type) {
+		case nil:
+			if tt.want == models.SyncStatusConflict {
+				t.Errorf("%s: при конфликте не передана серверная версия", tt.name)
+			}
+		case *models.Defect:
+			if tt.want != models.SyncStatusConflict || server.Title != "Трещина" {
+				t.Errorf("%s: неожиданная серверная версия %+v", tt.name, server)
+			}
+		case *models.Comment:
+			if tt.want != models.SyncStatusConflict || server.Text != "Проверить" {
+				t.Errorf("%s: неожиданная серверная версия %+v", tt.name, server)
+			}
+		default:
+			t.Errorf("%s: серверная версия неизвестного типа %T", tt.name, server)
+		}
+	}
+}
+
+func TestSyncDeltaAfterServerChange(t *testing.T) {
+	f := newSyncFixture(t)
+
+	if _, err := f.sync.Delta(f.manager, models.RoleManager, "не токен", 0, 0); !errors.Is(err, ErrInvalidSyncToken) {
+		t.Fatalf("поврежденный токен: ошибка %v", err)
+	}
+
+	initial, err := f.sync.Delta(f.manager, models.RoleManager, "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(initial.Defects.Created) != 1 || len(initial.Comments.Created) != 1 {
+		t.Fatalf("первая синхронизация: дефектов %d, комментариев %d", len(initial.Defects.Created), len(initial.Comments.Created))
+	}
+
+	// Дефект изменен на сервере: клиент получает его среди измененных, а не созданных
+	changedAt := time.Now().Add(-30 * time.Minute)
+	if err := f.db.Model(&f.defect).UpdateColumns(map[string]interface{}{"title": "Скол", "updated_at": changedAt}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	delta, err := f.sync.Delta(f.manager, models.RoleManager, initial.SyncToken, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delta.Defects.Created) != 0 || len(delta.Defects.Updated) != 1 || delta.Defects.Updated[0].Title != "Скол" {
+		t.Errorf("измененный дефект не передан: %+v", delta.Defects)
+	}
+	if len(delta.Comments.Created)+len(delta.Comments.Updated) != 0 {
+		t.Errorf("передан неизмененный комментарий: %+v", delta.Comments)
+	}
+
+	// Изменение по серверной версии из синхронизации применяется без конфликта
+	server := delta.Defects.Updated[0].UpdatedAt
+	results := f.sync.Apply(context.Background(), []models.SyncChange{{
+		Entity: "defect", Op: "update", ID: f.defect.ID, BaseUpdatedAt: &server, Data: json.RawMessage(`{"location": "Корпус 2"}`),
+	}}, f.manager, models.RoleManager)
+	if results[0].Status != models.SyncStatusApplied {
+		t.Errorf("изменение по актуальной версии: статус %q (%s)", results[0].Status, results[0].Error)
+	}
+}
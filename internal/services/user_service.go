@@ -0,0 +1,30 @@
+package services
+
+import (
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/models"
+)
+
+// UserListSchema - поля пользователей, доступные для сортировки, фильтров и выборки
+var UserListSchema = listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":              {Column: "users.id", Type: listquery.Number},
+		"email":           {Column: "users.email", Type: listquery.String},
+		"first_name":      {Column: "users.first_name", Type: listquery.String},
+		"last_name":       {Column: "users.last_name", Type: listquery.String},
+		"is_active":       {Column: "users.is_active", Type: listquery.Bool},
+		"role_id":         {Column: "users.role_id", Type: listquery.Number},
+		"organization_id": {Column: "users.organization_id", Type: listquery.Number},
+		"created_at":      {Column: "users.created_at", Type: listquery.Time},
+	},
+	Selectable: []string{"email", "first_name", "last_name", "phone", "is_active", "role_id", "role",
+		"organization_id", "organization", "created_at", "updated_at"},
+	DefaultSort:  "last_name,first_name",
+	IDColumn:     "users.id",
+	DefaultLimit: 10,
+}
+
+// List получает список пользователей с фильтрацией, сортировкой и пагинацией
+func (s *UserService) List(options *listquery.Options) ([]models.User, *listquery.PageInfo, error) {
+	return s.users.List(options)
+}
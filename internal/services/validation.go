@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrInvalidInput - данные запроса не прошли проверку. Ошибки проверки сохраняют свой текст
+// для клиента и совпадают с ErrInvalidInput через errors.Is.
+var ErrInvalidInput = errors.New("неверные данные запроса")
+
+// inputError - ошибка проверки данных запроса
+type inputError struct {
+	message string
+}
+
+func (e inputError) Error() string {
+	return e.message
+}
+
+func (e inputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// invalidInput возвращает ошибку проверки данных запроса с текстом message
+func invalidInput(message string) error {
+	return inputError{message: message}
+}
+
+// structValidator проверяет структуры по тем же тегам binding, что и Gin в обработчиках
+var structValidator = newStructValidator()
+
+func newStructValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// validateStruct возвращает ошибки валидации в виде понятных сообщений (пусто - ошибок нет)
+func validateStruct(obj interface{}) []string {
+	err := structValidator.Struct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		messages = append(messages, validationMessage(fe))
+	}
+	return messages
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s: обязательное поле", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s: минимальная длина %s", fe.Field(), fe.Param())
+	case "max":
+		return fmt.Sprintf("%s: максимальная длина %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s: допустимые значения: %s", fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "email":
+		return fmt.Sprintf("%s: неверный email", fe.Field())
+	}
+	return fmt.Sprintf("%s: не выполнено правило %s", fe.Field(), fe.Tag())
+}
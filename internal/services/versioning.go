@@ -0,0 +1,20 @@
+package services
+
+import (
+	"SystemContorlBackend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// checkVersion сравнивает текущую версию записи с версией, которую клиент передал в If-Match.
+// expected == 0 - клиент версию не передал, проверка не выполняется.
+func checkVersion(current, expected uint) error {
+	if expected != 0 && current != expected {
+		return repository.ErrVersionMismatch
+	}
+	return nil
+}
+
+// saveVersioned сохраняет запись с проверкой версии, см. repository.SaveVersioned
+func saveVersioned(tx *gorm.DB, model interface{}, version *uint) error {
+	return repository.SaveVersioned(tx, model, version)
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"SystemContorlBackend/internal/listquery"
+	"SystemContorlBackend/internal/logging"
+	"SystemContorlBackend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Максимальное число попыток доставки
+	WebhookMaxAttempts = 8
+
+	// Первая пауза перед повтором; далее удваивается
+	WebhookRetryBase = 30 * time.Second
+
+	// Сколько ответа сохраняется в журнале доставок
+	webhookResponseLimit = 2048
+
+	// На сколько доставка резервируется за обработчиком, чтобы ее не взял другой экземпляр
+	webhookLease = 5 * time.Minute
+)
+
+// WebhookService - исходящие webhook: подписки, очередь доставок и их отправка
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+// NewWebhookService создает сервис webhook на подключении db
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{db: db, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// webhookEnvelope - тело запроса, отправляемого на webhook
+type webhookEnvelope struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	ProjectID  uint        `json:"project_id"`
+	EntityType string      `json:"entity_type"`
+	EntityID   uint        `json:"entity_id"`
+	ActorID    uint        `json:"actor_id"`
+	Data       interface{} `json:"data"`
+}
+
+// HandleEvent ставит событие в очередь доставки подходящим webhook (подписчик EventBus)
+func (s *WebhookService) HandleEvent(event DomainEvent) {
+	if !isWebhookEvent(event.Type) {
+		return
+	}
+
+	var webhooks []models.Webhook
+	err := s.db.Where("is_active = ?", true).
+		Where("project_id IS NULL OR project_id = ?", event.ProjectID).
+		Find(&webhooks).Error
+	if err != nil {
+		logging.FromContext(event.Context).Error("Не удалось найти webhook для события", "event", event.Type, "error", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(webhookEnvelope{
+		Event:      event.Type,
+		OccurredAt: event.OccurredAt,
+		ProjectID:  event.ProjectID,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		ActorID:    event.ActorID,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		logging.FromContext(event.Context).Error("Не удалось сериализовать событие для webhook", "event", event.Type, "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhookAccepts(webhook, event.Type) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			Payload:       string(body),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.db.Create(&delivery).Error; err != nil {
+			logging.FromContext(event.Context).Error("Не удалось поставить в очередь доставку webhook", "webhook_id", webhook.ID, "error", err)
+		}
+	}
+}
+
+// StartWorker периодически отправляет доставки из очереди до отмены контекста
+func (s *WebhookService) StartWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				processed, err := s.ProcessDeliveries(20)
+				if err != nil {
+					slog.Error("Ошибка обработки очереди webhook", "error", err)
+					break
+				}
+				if processed == 0 || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// ProcessDeliveries отправляет очередную пачку доставок, срок которых наступил
+func (s *WebhookService) ProcessDeliveries(batch int) (int, error) {
+	var deliveries []models.WebhookDelivery
+
+	// Резервируем доставки; SKIP LOCKED позволяет нескольким экземплярам разбирать очередь параллельно
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(batch).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(webhookLease)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		var webhook models.Webhook
+		if err := s.db.First(&webhook, deliveries[i].WebhookID).Error; err != nil || !webhook.IsActive {
+			s.db.Model(&deliveries[i]).Updates(map[string]interface{}{
+				"status": models.WebhookDeliveryFailed,
+				"error":  "webhook удален или отключен",
+			})
+			continue
+		}
+		s.attemptDelivery(&webhook, &deliveries[i])
+	}
+
+	return len(deliveries), nil
+}
+
+// attemptDelivery выполняет одну попытку доставки и планирует повтор при неудаче
+func (s *WebhookService) attemptDelivery(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	code, body, err := s.send(webhook, delivery.ID, delivery.EventType, []byte(delivery.Payload))
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseCode = code
+	delivery.ResponseBody = body
+	delivery.Error = ""
+
+	switch {
+	case err == nil && code >= 200 && code < 300:
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	default:
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.Error = fmt.Sprintf("неуспешный ответ: %d", code)
+		}
+		if delivery.Attempts >= WebhookMaxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(webhookBackoff(delivery.Attempts))
+			delivery.Status = models.WebhookDeliveryPending
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := s.db.Save(delivery).Error; err != nil {
+		slog.Error("Не удалось сохранить результат доставки webhook", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// webhookBackoff - экспоненциальная пауза перед повтором: 30с, 1м, 2м, 4м...
+func webhookBackoff(attempts int) time.Duration {
+	return WebhookRetryBase * time.Duration(1<<uint(attempts-1))
+}
+
+// send отправляет подписанный запрос и возвращает код и начало ответа
+func (s *WebhookService) send(webhook *models.Webhook, deliveryID uint, eventType string, payload []byte) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SystemControl-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", eventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(deliveryID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(webhook.Secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	return resp.StatusCode, string(body), nil
+}
+
+// SignWebhookPayload вычисляет подпись HMAC-SHA256 от "<timestamp>.<тело>".
+// Получатель проверяет ее тем же секретом и отклоняет запросы с устаревшей меткой времени.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// List получает webhook, при необходимости только для проекта
+func (s *WebhookService) List(projectID uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	query := s.db.Order("created_at")
+	if projectID != 0 {
+		query = query.Where("project_id = ?", projectID)
+	}
+	if err := query.Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Events = splitWebhookEvents(webhooks[i].EventTypes)
+	}
+	return webhooks, nil
+}
+
+// GetByID получает webhook по ID
+func (s *WebhookService) GetByID(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.First(&webhook, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("webhook не найден")
+		}
+		return nil, err
+	}
+	webhook.Events = splitWebhookEvents(webhook.EventTypes)
+	return &webhook, nil
+}
+
+// Create регистрирует webhook и возвращает секрет подписи (показывается один раз)
+func (s *WebhookService) Create(input models.WebhookInput, createdBy uint) (*models.Webhook, string, error) {
+	if err := s.validateInput(input); err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	webhook := models.Webhook{
+		ProjectID:   input.ProjectID,
+		URL:         input.URL,
+		Secret:      secret,
+		EventTypes:  strings.Join(input.Events, ","),
+		Description: input.Description,
+		IsActive:    input.IsActive == nil || *input.IsActive,
+		CreatedBy:   createdBy,
+	}
+	if err := s.db.Create(&webhook).Error; err != nil {
+		return nil, "", err
+	}
+
+	webhook.Events = splitWebhookEvents(webhook.EventTypes)
+	return &webhook, secret, nil
+}
+
+// Update обновляет адрес, фильтр событий и активность webhook
+func (s *WebhookService) Update(id uint, input models.WebhookInput) (*models.Webhook, error) {
+	if err := s.validateInput(input); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.ProjectID = input.ProjectID
+	webhook.URL = input.URL
+	webhook.EventTypes = strings.Join(input.Events, ",")
+	webhook.Description = input.Description
+	if input.IsActive != nil {
+		webhook.IsActive = *input.IsActive
+	}
+
+	if err := s.db.Save(webhook).Error; err != nil {
+		return nil, err
+	}
+
+	webhook.Events = splitWebhookEvents(webhook.EventTypes)
+	return webhook, nil
+}
+
+// Delete удаляет webhook и отменяет его неотправленные доставки
+func (s *WebhookService) Delete(id uint) error {
+	webhook, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("webhook_id = ? AND status = ?", id, models.WebhookDeliveryPending).
+			Updates(map[string]interface{}{"status": models.WebhookDeliveryFailed, "error": "webhook удален"}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+}
+
+// WebhookDeliveryListSchema - поля журнала доставок, доступные для сортировки, фильтров и выборки
+var WebhookDeliveryListSchema = listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":            {Column: "webhook_deliveries.id", Type: listquery.Number},
+		"event_type":    {Column: "webhook_deliveries.event_type", Type: listquery.String},
+		"status":        {Column: "webhook_deliveries.status", Type: listquery.String},
+		"attempts":      {Column: "webhook_deliveries.attempts", Type: listquery.Number},
+		"response_code": {Column: "webhook_deliveries.response_code", Type: listquery.Number},
+		"delivered_at":  {Column: "webhook_deliveries.delivered_at", Type: listquery.Time},
+		"created_at":    {Column: "webhook_deliveries.created_at", Type: listquery.Time},
+	},
+	Selectable: []string{"webhook_id", "event_type", "payload", "status", "attempts", "next_attempt_at",
+		"last_attempt_at", "response_code", "response_body", "error", "delivered_at", "created_at"},
+	DefaultSort:  "-created_at",
+	IDColumn:     "webhook_deliveries.id",
+	DefaultLimit: 20,
+}
+
+// Deliveries получает журнал доставок webhook
+func (s *WebhookService) Deliveries(webhookID uint, status string, options *listquery.Options) ([]models.WebhookDelivery, *listquery.PageInfo, error) {
+	var deliveries []models.WebhookDelivery
+
+	query := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	page, err := options.Find(query, &deliveries)
+	if err != nil {
+		return nil, nil, err
+	}
+	return deliveries, page, nil
+}
+
+// Test сразу отправляет тестовое событие и возвращает результат доставки
+func (s *WebhookService) Test(id uint, actorID uint) (*models.WebhookDelivery, error) {
+	webhook, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var projectID uint
+	if webhook.ProjectID != nil {
+		projectID = *webhook.ProjectID
+	}
+
+	body, err := json.Marshal(webhookEnvelope{
+		Event:      models.EventWebhookTest,
+		OccurredAt: time.Now(),
+		ProjectID:  projectID,
+		EntityType: "webhook",
+		EntityID:   webhook.ID,
+		ActorID:    actorID,
+		Data:       map[string]interface{}{"message": "Тестовое событие"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventType: models.EventWebhookTest,
+		Payload:   string(body),
+		Status:    models.WebhookDeliveryPending,
+	}
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	// Тестовая доставка не повторяется: результат нужен сразу
+	now := time.Now()
+	code, respBody, sendErr := s.send(webhook, delivery.ID, delivery.EventType, body)
+	delivery.Attempts = 1
+	delivery.LastAttemptAt = &now
+	delivery.ResponseCode = code
+	delivery.ResponseBody = respBody
+	if sendErr == nil && code >= 200 && code < 300 {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+	} else {
+		delivery.Status = models.WebhookDeliveryFailed
+		if sendErr != nil {
+			delivery.Error = sendErr.Error()
+		} else {
+			delivery.Error = fmt.Sprintf("неуспешный ответ: %d", code)
+		}
+	}
+
+	if err := s.db.Save(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// validateInput проверяет проект и типы событий
+func (s *WebhookService) validateInput(input models.WebhookInput) error {
+	if input.ProjectID != nil {
+		var project models.Project
+		if err := s.db.First(&project, *input.ProjectID).Error; err != nil {
+			return errors.New("проект не найден")
+		}
+	}
+	if !strings.HasPrefix(input.URL, "http://") && !strings.HasPrefix(input.URL, "https://") {
+		return errors.New("адрес webhook должен начинаться с http:// или https://")
+	}
+	for _, eventType := range input.Events {
+		if !isWebhookEvent(eventType) && !strings.HasSuffix(eventType, ".*") {
+			return fmt.Errorf("неизвестный тип события: %s", eventType)
+		}
+	}
+	return nil
+}
+
+// isWebhookEvent проверяет, отправляется ли событие на webhook
+func isWebhookEvent(eventType string) bool {
+	for _, t := range models.WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookAccepts проверяет фильтр событий webhook (поддерживается маска "defect.*")
+func webhookAccepts(webhook models.Webhook, eventType string) bool {
+	filters := splitWebhookEvents(webhook.EventTypes)
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if filter == eventType {
+			return true
+		}
+		if strings.HasSuffix(filter, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(filter, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitWebhookEvents(value string) []string {
+	events := []string{}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			events = append(events, part)
+		}
+	}
+	return events
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/models"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	payload := []byte(`{"event":"defect.created"}`)
+
+	// Подпись - HMAC-SHA256 от "timestamp.payload" в hex
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(payload)))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := SignWebhookPayload("secret", "1700000000", payload); got != want {
+		t.Errorf("подпись %s, ожидалась %s", got, want)
+	}
+	if SignWebhookPayload("other", "1700000000", payload) == want {
+		t.Error("подпись не зависит от секрета")
+	}
+	if SignWebhookPayload("secret", "1700000001", payload) == want {
+		t.Error("подпись не зависит от времени отправки")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, WebhookRetryBase},
+		{2, 2 * WebhookRetryBase},
+		{3, 4 * WebhookRetryBase},
+		{WebhookMaxAttempts, 128 * WebhookRetryBase},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, ожидалось %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookAccepts(t *testing.T) {
+	tests := []struct {
+		filter string
+		event  string
+		want   bool
+	}{
+		{"", models.EventDefectCreated, true},
+		{" , ", models.EventCommentCreated, true},
+		{models.EventDefectCreated, models.EventDefectCreated, true},
+		{models.EventDefectCreated, models.EventDefectUpdated, false},
+		{"project.created, defect.updated", models.EventDefectUpdated, true},
+		{"defect.*", models.EventDefectStatusChanged, true},
+		{"defect.*", models.EventCommentCreated, false},
+		// Маска сравнивается с префиксом вместе с точкой
+		{"defect.*", "defects.created", false},
+		{"defect", models.EventDefectCreated, false},
+	}
+	for _, tt := range tests {
+		webhook := models.Webhook{EventTypes: tt.filter}
+		if got := webhookAccepts(webhook, tt.event); got != tt.want {
+			t.Errorf("webhookAccepts(%q, %q) = %v, ожидалось %v", tt.filter, tt.event, got, tt.want)
+		}
+	}
+}
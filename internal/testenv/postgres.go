@@ -0,0 +1,106 @@
+package testenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"SystemContorlBackend/internal/database"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Встроенная PostgreSQL запускается один раз на пакет тестов, каждый тест получает свою базу
+var (
+	pgOnce     sync.Once
+	pgServer   *embeddedpostgres.EmbeddedPostgres
+	pgPort     uint32
+	pgStartErr error
+	pgSeq      atomic.Uint64
+)
+
+// Main запускает тесты пакета и останавливает встроенную PostgreSQL, если она запускалась.
+// Вызывается из TestMain: func TestMain(m *testing.M) { testenv.Main(m) }
+func Main(m *testing.M) {
+	code := m.Run()
+	if pgServer != nil {
+		pgServer.Stop()
+	}
+	os.Exit(code)
+}
+
+// startPostgres запускает встроенную PostgreSQL. Бинарные файлы скачиваются при первом
+// запуске и кешируются; порт задается TEST_POSTGRES_PORT (по умолчанию 54329).
+func startPostgres() {
+	port, err := strconv.ParseUint(os.Getenv("TEST_POSTGRES_PORT"), 10, 32)
+	if err != nil {
+		port = 54329
+	}
+	pgPort = uint32(port)
+
+	runtimeDir, err := os.MkdirTemp("", "systemcontrol-pg-")
+	if err != nil {
+		pgStartErr = err
+		return
+	}
+
+	server := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(pgPort).
+		RuntimePath(runtimeDir).
+		DataPath(filepath.Join(runtimeDir, "data")).
+		Logger(io.Discard))
+	if err := server.Start(); err != nil {
+		pgStartErr = err
+		return
+	}
+	pgServer = server
+}
+
+// postgresDSN - строка подключения к базе name на встроенном сервере
+func postgresDSN(name string) string {
+	return fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=%s sslmode=disable", pgPort, name)
+}
+
+// openPostgres создает отдельную базу на встроенной PostgreSQL и применяет SQL-миграции
+func openPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	pgOnce.Do(startPostgres)
+	if pgStartErr != nil {
+		t.Fatalf("не удалось запустить встроенную PostgreSQL: %v", pgStartErr)
+	}
+
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	admin, err := gorm.Open(postgres.Open(postgresDSN("postgres")), config)
+	if err != nil {
+		t.Fatalf("не удалось подключиться к PostgreSQL: %v", err)
+	}
+	adminDB, _ := admin.DB()
+
+	name := fmt.Sprintf("test_%d_%d", os.Getpid(), pgSeq.Add(1))
+	if err := admin.Exec("CREATE DATABASE " + name).Error; err != nil {
+		adminDB.Close()
+		t.Fatalf("не удалось создать тестовую базу: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)")
+		adminDB.Close()
+	})
+
+	db, err := gorm.Open(postgres.Open(postgresDSN(name)), config)
+	if err != nil {
+		t.Fatalf("не удалось подключиться к тестовой базе: %v", err)
+	}
+
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("не удалось применить миграции: %v", err)
+	}
+	return db
+}
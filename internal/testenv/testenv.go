@@ -0,0 +1,247 @@
+// Package testenv запускает API целиком для интеграционных тестов: роутер со всеми
+// middleware, изолированную БД и временный каталог загрузок.
+//
+// По умолчанию каждый тест получает свою SQLite-базу, схема создается AutoMigrate.
+// С TEST_DB=postgres тесты идут на встроенной PostgreSQL со схемой из SQL-миграций;
+// такой пакет должен вызывать testenv.Main из TestMain, чтобы сервер остановился после тестов.
+package testenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"SystemContorlBackend/internal/config"
+	"SystemContorlBackend/internal/database"
+	"SystemContorlBackend/internal/handlers"
+	"SystemContorlBackend/internal/realtime"
+	"SystemContorlBackend/internal/router"
+	"SystemContorlBackend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// JWTSecret - секрет подписи токенов в тестах
+const JWTSecret = "test-secret-for-integration-tests"
+
+// DefaultPassword - пароль пользователей, созданных через Env.Register
+const DefaultPassword = "password123"
+
+// userSeq делает email пользователей уникальными в пределах процесса
+var userSeq atomic.Uint64
+
+// Env - запущенное API с изолированной БД
+type Env struct {
+	t         *testing.T
+	DB        *gorm.DB
+	UploadDir string
+	Services  *services.Services
+	Health    *services.HealthService
+	Router    *gin.Engine
+}
+
+// User - зарегистрированный в тестовом API пользователь
+type User struct {
+	ID       uint
+	Email    string
+	Password string
+	RoleCode string
+	Token    string
+}
+
+// New поднимает API для теста. База, каталог загрузок и глобальные настройки
+// восстанавливаются при завершении теста.
+func New(t *testing.T) *Env {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var db *gorm.DB
+	postgres := os.Getenv("TEST_DB") == "postgres"
+	if postgres {
+		db = openPostgres(t)
+	} else {
+		db = openSQLite(t)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := database.Seed(db); err != nil {
+		t.Fatalf("не удалось заполнить справочники: %v", err)
+	}
+
+	uploadDir := t.TempDir()
+	svc := services.New(db, services.Config{
+		JWT:       config.JWTConfig{Secret: JWTSecret, ExpireHours: 1},
+		UploadDir: uploadDir,
+		// SQL-миграции применяются только на PostgreSQL, у SQLite схема из AutoMigrate
+		CheckMigrations: postgres,
+		IdempotencyTTL:  24 * time.Hour,
+	})
+
+	// Подписчики как в cmd/api, кроме писем: отправителя писем в тестах нет
+	broker := realtime.NewMemoryBroker(1000)
+	t.Cleanup(func() { broker.Close() })
+	svc.Events.Subscribe(svc.Notifications.HandleEvent)
+	svc.Events.Subscribe(svc.Webhooks.HandleEvent)
+	svc.Events.Subscribe(services.RealtimePublisher(broker))
+
+	return &Env{
+		t:         t,
+		DB:        db,
+		UploadDir: uploadDir,
+		Services:  svc,
+		Health:    svc.Health,
+		Router:    router.New(handlers.New(svc, broker), svc.Tokens, svc.Idempotency),
+	}
+}
+
+// openSQLite создает пустую SQLite-базу во временном каталоге теста
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on&_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("не удалось открыть SQLite: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("не удалось создать схему: %v", err)
+	}
+	return db
+}
+
+// Response - ответ API на тестовый запрос
+type Response struct {
+	*httptest.ResponseRecorder
+	t *testing.T
+}
+
+// Decode разбирает JSON-тело ответа в v
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), v); err != nil {
+		r.t.Fatalf("ответ не JSON: %v; тело: %s", err, r.Body.String())
+	}
+}
+
+// Map возвращает JSON-тело ответа как объект
+func (r *Response) Map() map[string]interface{} {
+	r.t.Helper()
+	var body map[string]interface{}
+	r.Decode(&body)
+	return body
+}
+
+// ExpectStatus завершает тест, если код ответа не равен ожидаемому
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Fatalf("ожидался код %d, получен %d; тело: %s", code, r.Code, r.Body.String())
+	}
+	return r
+}
+
+// Do выполняет запрос к роутеру; token == "" - без авторизации
+func (e *Env) Do(method, path, token, contentType string, body io.Reader) *Response {
+	e.t.Helper()
+	req := httptest.NewRequest(method, path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	e.Router.ServeHTTP(recorder, req)
+	return &Response{ResponseRecorder: recorder, t: e.t}
+}
+
+// JSON выполняет запрос с телом body, закодированным в JSON (nil - без тела)
+func (e *Env) JSON(method, path, token string, body interface{}) *Response {
+	e.t.Helper()
+	if body == nil {
+		return e.Do(method, path, token, "", nil)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		e.t.Fatalf("не удалось закодировать тело запроса: %v", err)
+	}
+	return e.Do(method, path, token, "application/json", bytes.NewReader(data))
+}
+
+// Register регистрирует пользователя с ролью roleCode и уникальным email
+func (e *Env) Register(roleCode string) *User {
+	e.t.Helper()
+	user := &User{
+		Email:    fmt.Sprintf("%s%d@example.com", roleCode, userSeq.Add(1)),
+		Password: DefaultPassword,
+		RoleCode: roleCode,
+	}
+
+	var body struct {
+		User  struct{ ID uint } `json:"user"`
+		Token string            `json:"token"`
+	}
+	e.JSON(http.MethodPost, "/api/v1/auth/register", "", map[string]interface{}{
+		"email":      user.Email,
+		"password":   user.Password,
+		"first_name": "Тест",
+		"last_name":  roleCode,
+		"role_code":  roleCode,
+	}).ExpectStatus(http.StatusCreated).Decode(&body)
+
+	user.ID = body.User.ID
+	user.Token = body.Token
+	return user
+}
+
+// Login входит под пользователем и возвращает новый токен
+func (e *Env) Login(email, password string) string {
+	e.t.Helper()
+	var body struct {
+		Token string `json:"token"`
+	}
+	e.JSON(http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    email,
+		"password": password,
+	}).ExpectStatus(http.StatusOK).Decode(&body)
+	return body.Token
+}
+
+// Upload загружает один файл к сущности через POST /files/upload
+func (e *Env) Upload(token, entityType string, entityID uint, fileName, contentType string, content []byte) *Response {
+	e.t.Helper()
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	form.WriteField("entity_type", entityType)
+	form.WriteField("entity_id", fmt.Sprint(entityID))
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files"; filename="%s"`, fileName))
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		e.t.Fatalf("не удалось собрать форму: %v", err)
+	}
+	part.Write(content)
+	form.Close()
+
+	return e.Do(http.MethodPost, "/api/v1/files/upload", token, form.FormDataContentType(), &buf)
+}